@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// Limits on announcements and how they are delivered
+const (
+	maxAnnouncementSubjectLength = 255 // matches the size of the subject column
+	maxAnnouncementBodyLength    = 5000
+	announcementSenders          = 4 // emails sent at the same time for an announcement
+)
+
+// announcementStatuses are the RSVP statuses announcements can be sent to
+var announcementStatuses = []string{"going", "maybe", "waitlisted"}
+
+// AnnouncementHandler handles HTTP requests for the announcements organizers
+// send to the people who RSVP'd to an event
+type AnnouncementHandler struct {
+	AnnouncementRepo *repositories.AnnouncementRepository
+	RSVPRepo         *repositories.RSVPRepository
+	EventRepo        *repositories.EventRepository
+	UserRepo         *repositories.UserRepository
+	EmailService     *services.EmailService
+	AccessService    *services.AccessService
+}
+
+func NewAnnouncementHandler(
+	announcementRepo *repositories.AnnouncementRepository,
+	rsvpRepo *repositories.RSVPRepository,
+	eventRepo *repositories.EventRepository,
+	userRepo *repositories.UserRepository,
+	emailService *services.EmailService,
+	accessService *services.AccessService,
+) *AnnouncementHandler {
+	return &AnnouncementHandler{
+		AnnouncementRepo: announcementRepo,
+		RSVPRepo:         rsvpRepo,
+		EventRepo:        eventRepo,
+		UserRepo:         userRepo,
+		EmailService:     emailService,
+		AccessService:    accessService,
+	}
+}
+
+// GetAnnouncements handles listing a page of an event's announcements, newest first
+func (h *AnnouncementHandler) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	announcements, next, err := h.AnnouncementRepo.GetAnnouncements(eventID, limit, cursor)
+	if err != nil {
+		writeListError(w, err, "Failed to get announcements")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Page[models.Announcement]{
+		Items:      announcements,
+		NextCursor: repositories.EncodeCursor(next),
+	})
+}
+
+// CreateAnnouncement handles an organizer sending an announcement to attendees
+// with the chosen RSVP statuses. The announcement is saved right away and the
+// emails are sent in the background.
+func (h *AnnouncementHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionAnnounce) {
+		return
+	}
+
+	var req models.AnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	if err := validateAnnouncementRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid announcement: %v\n", err)
+		return
+	}
+
+	rsvps, err := h.RSVPRepo.GetUpcomingRSVPsByStatus(event.ID, req.Statuses)
+	if err != nil {
+		http.Error(w, "Failed to send announcement", http.StatusInternalServerError)
+		log.Printf("Failed to get announcement recipients: %v\n", err)
+		return
+	}
+
+	organizerEmail := ""
+	if organizer, err := h.UserRepo.GetUserByID(event.UserID); err == nil {
+		organizerEmail = organizer.Email
+	}
+
+	// Users with RSVPs to several occurrences of a recurring event get one email,
+	// showing the next occurrence they are going to. Cancelled occurrences are
+	// skipped.
+	recipients := []announcementRecipient{}
+	seen := map[int]bool{}
+	for _, rsvp := range rsvps {
+		if seen[rsvp.UserID] || rsvp.Email == "" {
+			continue
+		}
+		start, err := h.EventRepo.ResolveOccurrence(event, rsvp.OccurrenceDate)
+		if err != nil {
+			continue
+		}
+		seen[rsvp.UserID] = true
+		recipients = append(recipients, announcementRecipient{
+			User: &models.User{
+				ID:        rsvp.UserID,
+				FirstName: rsvp.FirstName,
+				LastName:  rsvp.LastName,
+				Email:     rsvp.Email,
+			},
+			Event: emailEvent(event, start, organizerEmail),
+		})
+	}
+
+	id, err := h.AnnouncementRepo.CreateAnnouncement(event.ID, userID, req, len(recipients))
+	if err == repositories.ErrAnnouncementLimit {
+		if next, err := h.AnnouncementRepo.NextAnnouncementAt(event.ID); err == nil {
+			retryAfter := int(math.Ceil(time.Until(next).Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		}
+		http.Error(w, fmt.Sprintf("Events can send at most %d announcements per hour. Try again later", repositories.AnnouncementLimit), http.StatusTooManyRequests)
+		log.Printf("Event %d reached its announcement limit\n", event.ID)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to send announcement", http.StatusInternalServerError)
+		log.Printf("Failed to create announcement: %v\n", err)
+		return
+	}
+
+	go h.deliver(id, recipients, req)
+
+	announcement, err := h.AnnouncementRepo.GetAnnouncementByID(event.ID, id)
+	if err != nil {
+		http.Error(w, "Failed to get announcement", http.StatusInternalServerError)
+		log.Printf("Failed to get announcement: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(announcement)
+	log.Printf("Announcement %d for event %d sent by user %d to %d attendees\n", id, event.ID, userID, len(recipients))
+}
+
+// announcementRecipient is an attendee an announcement is emailed to, along with
+// the occurrence of the event the email describes
+type announcementRecipient struct {
+	User  *models.User
+	Event *models.Event
+}
+
+// deliver emails an announcement to its recipients, a few at a time, and records
+// how many emails went out
+func (h *AnnouncementHandler) deliver(announcementID int, recipients []announcementRecipient, req models.AnnouncementRequest) {
+	queue := make(chan announcementRecipient)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	delivered, failed := 0, 0
+
+	for i := 0; i < announcementSenders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for recipient := range queue {
+				err := h.EmailService.SendAnnouncement(recipient.Event, recipient.User, req.Subject, req.Body)
+				mu.Lock()
+				if err != nil {
+					failed++
+					log.Printf("Error sending announcement %d to user %d: %v\n", announcementID, recipient.User.ID, err)
+				} else {
+					delivered++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, recipient := range recipients {
+		queue <- recipient
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := h.AnnouncementRepo.RecordDelivery(announcementID, delivered, failed); err != nil {
+		log.Printf("Error recording delivery of announcement %d: %v\n", announcementID, err)
+	}
+}
+
+// validateAnnouncementRequest checks and normalizes an announcement request
+func validateAnnouncementRequest(req *models.AnnouncementRequest) error {
+	req.Subject = strings.TrimSpace(req.Subject)
+	if req.Subject == "" || len(req.Subject) > maxAnnouncementSubjectLength {
+		return fmt.Errorf("Subject is required and can be at most %d characters", maxAnnouncementSubjectLength)
+	}
+	// The subject becomes an email header, so it must stay on a single line
+	if strings.ContainsAny(req.Subject, "\r\n") {
+		return errors.New("Subject must be a single line")
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" || len(req.Body) > maxAnnouncementBodyLength {
+		return fmt.Errorf("Message is required and can be at most %d characters", maxAnnouncementBodyLength)
+	}
+
+	if len(req.Statuses) == 0 {
+		req.Statuses = []string{"going"}
+	}
+	statuses := []string{}
+	for _, status := range req.Statuses {
+		if !containsString(announcementStatuses, status) {
+			return errors.New("Invalid status. Announcements can be sent to 'going', 'maybe' and 'waitlisted' attendees")
+		}
+		if !containsString(statuses, status) {
+			statuses = append(statuses, status)
+		}
+	}
+	req.Statuses = statuses
+
+	return nil
+}
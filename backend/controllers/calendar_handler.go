@@ -14,18 +14,21 @@ import (
 
 	"github.com/johneliud/evently/backend/models"
 	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
 )
 
 // CalendarHandler handles Google Calendar-related HTTP requests
 type CalendarHandler struct {
-	CalendarRepo *repositories.CalendarRepository
-	EventRepo    *repositories.EventRepository
+	CalendarRepo  *repositories.CalendarRepository
+	EventRepo     *repositories.EventRepository
+	AccessService *services.AccessService
 }
 
-func NewCalendarHandler(calendarRepo *repositories.CalendarRepository, eventRepo *repositories.EventRepository) *CalendarHandler {
+func NewCalendarHandler(calendarRepo *repositories.CalendarRepository, eventRepo *repositories.EventRepository, accessService *services.AccessService) *CalendarHandler {
 	return &CalendarHandler{
-		CalendarRepo: calendarRepo,
-		EventRepo:    eventRepo,
+		CalendarRepo:  calendarRepo,
+		EventRepo:     eventRepo,
+		AccessService: accessService,
 	}
 }
 
@@ -169,6 +172,10 @@ func (h *CalendarHandler) AddEventToCalendar(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
 	// Get the user's token
 	token, err := h.CalendarRepo.GetUserToken(userID)
 	if err != nil {
@@ -196,14 +203,17 @@ func (h *CalendarHandler) AddEventToCalendar(w http.ResponseWriter, r *http.Requ
 
 	// Convert EventWithOrganizer to Event
 	eventModel := &models.Event{
-		ID:          event.ID,
-		Title:       event.Title,
-		Description: event.Description,
-		Date:        event.Date,
-		Location:    event.Location,
-		UserID:      event.UserID,
-		CreatedAt:   event.CreatedAt,
-		UpdatedAt:   event.UpdatedAt,
+		ID:             event.ID,
+		Title:          event.Title,
+		Description:    event.Description,
+		Date:           event.Date,
+		EndDate:        event.EndDate,
+		TimeZone:       event.TimeZone,
+		Location:       event.Location,
+		RecurrenceRule: event.RecurrenceRule,
+		UserID:         event.UserID,
+		CreatedAt:      event.CreatedAt,
+		UpdatedAt:      event.UpdatedAt,
 	}
 
 	// Add the event to Google Calendar
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/qrcode"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// CheckInHandler handles HTTP requests for attendee tickets and check-in
+type CheckInHandler struct {
+	RSVPRepo      *repositories.RSVPRepository
+	EventRepo     *repositories.EventRepository
+	TokenService  *services.TokenService
+	AccessService *services.AccessService
+}
+
+func NewCheckInHandler(
+	rsvpRepo *repositories.RSVPRepository,
+	eventRepo *repositories.EventRepository,
+	tokenService *services.TokenService,
+	accessService *services.AccessService,
+) *CheckInHandler {
+	return &CheckInHandler{
+		RSVPRepo:      rsvpRepo,
+		EventRepo:     eventRepo,
+		TokenService:  tokenService,
+		AccessService: accessService,
+	}
+}
+
+// GetTicket handles retrieving the QR code of the requesting user's ticket as a PNG image
+func (h *CheckInHandler) GetTicket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	// Tickets to recurring events are per occurrence
+	occurrence, err := getOccurrenceFromQuery(r)
+	if err != nil {
+		http.Error(w, "Invalid occurrence. Use RFC 3339 format", http.StatusBadRequest)
+		log.Printf("Invalid occurrence: %v\n", err)
+		return
+	}
+	if event.RecurrenceRule == "" {
+		occurrence = nil
+	}
+
+	rsvp, err := h.RSVPRepo.GetRSVPByEventAndUser(event.ID, userID, occurrence)
+	if err != nil {
+		http.Error(w, "Failed to get ticket", http.StatusInternalServerError)
+		log.Printf("Failed to get RSVP: %v\n", err)
+		return
+	}
+	if rsvp == nil || rsvp.Status != "going" {
+		http.Error(w, "You don't have a ticket for this event", http.StatusNotFound)
+		log.Printf("User %d has no ticket for event %d\n", userID, event.ID)
+		return
+	}
+
+	image, err := qrcode.PNG(h.TokenService.SignTicket(event.ID, rsvp.ID), services.TicketQRScale)
+	if err != nil {
+		http.Error(w, "Failed to get ticket", http.StatusInternalServerError)
+		log.Printf("Failed to render ticket: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Write(image)
+}
+
+// CheckIn handles checking in an attendee by the code on their ticket. Tickets to
+// recurring events are checked in to the occurrence given by the occurrence query
+// parameter, or to the one taking place today when it is left out.
+func (h *CheckInHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	// Organizers and check-in staff scan tickets
+	if !authorize(w, h.AccessService, event, userID, services.PermissionCheckIn) {
+		return
+	}
+
+	if event.Status == models.EventStatusCancelled {
+		http.Error(w, "This event has been cancelled", http.StatusConflict)
+		log.Printf("Check-in attempted for cancelled event %d\n", event.ID)
+		return
+	}
+
+	var req models.CheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	ticketEventID, rsvpID, err := h.TokenService.VerifyTicket(req.Code)
+	if err != nil {
+		http.Error(w, "Invalid ticket", http.StatusBadRequest)
+		log.Printf("Invalid ticket: %v\n", err)
+		return
+	}
+	if ticketEventID != event.ID {
+		http.Error(w, "This ticket is for a different event", http.StatusBadRequest)
+		log.Printf("Ticket for event %d scanned at event %d\n", ticketEventID, event.ID)
+		return
+	}
+
+	occurrence, err := getOccurrenceFromQuery(r)
+	if err != nil {
+		http.Error(w, "Invalid occurrence. Use RFC 3339 format", http.StatusBadRequest)
+		log.Printf("Invalid occurrence: %v\n", err)
+		return
+	}
+	if occurrence == nil && event.RecurrenceRule != "" {
+		today, err := h.EventRepo.TodaysOccurrence(event, time.Now())
+		if err != nil {
+			if err == repositories.ErrNoOccurrenceToday {
+				http.Error(w, "No occurrence of this event takes place today. Send the occurrence to check in to", http.StatusConflict)
+			} else {
+				http.Error(w, "Failed to resolve occurrence", http.StatusInternalServerError)
+			}
+			log.Printf("Failed to get today's occurrence of event %d: %v\n", event.ID, err)
+			return
+		}
+		occurrence = &today
+	} else if _, err := h.EventRepo.ResolveOccurrence(event, occurrence); err != nil {
+		writeOccurrenceError(w, err)
+		return
+	}
+	if event.RecurrenceRule == "" {
+		occurrence = nil
+	}
+
+	attendee, err := h.RSVPRepo.CheckIn(event.ID, rsvpID, occurrence)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			http.Error(w, "Ticket not found. The RSVP may have been withdrawn", http.StatusNotFound)
+		case repositories.ErrWrongOccurrence:
+			message := "This ticket is for a different occurrence"
+			if attendee.OccurrenceDate != nil {
+				message = fmt.Sprintf("This ticket is for the occurrence on %s", attendee.OccurrenceDate.In(event.Date.Location()).Format("Monday, January 2, 2006 at 3:04 PM"))
+			}
+			http.Error(w, message, http.StatusConflict)
+		case repositories.ErrNotGoing:
+			http.Error(w, fmt.Sprintf("This ticket is no longer valid. %s %s's RSVP is %s", attendee.FirstName, attendee.LastName, attendee.Status), http.StatusConflict)
+		case repositories.ErrAlreadyCheckedIn:
+			http.Error(w, fmt.Sprintf("%s %s already checked in at %s", attendee.FirstName, attendee.LastName, attendee.CheckedInAt.Format(time.RFC3339)), http.StatusConflict)
+		default:
+			http.Error(w, "Failed to check in", http.StatusInternalServerError)
+		}
+		log.Printf("Failed to check in RSVP %d: %v\n", rsvpID, err)
+		return
+	}
+
+	count, err := h.RSVPRepo.GetRSVPCount(event.ID, attendee.OccurrenceDate)
+	if err != nil {
+		http.Error(w, "Checked in, but failed to get RSVP count", http.StatusInternalServerError)
+		log.Printf("Failed to get RSVP count: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.CheckInResult{
+		Attendee: *attendee,
+		Count:    count,
+	})
+	log.Printf("User %d checked in to event %d by user %d\n", attendee.UserID, event.ID, userID)
+}
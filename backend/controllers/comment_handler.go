@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// maxCommentLength limits the length of a comment's body
+const maxCommentLength = 2000
+
+// CommentHandler handles HTTP requests for the discussion on an event
+type CommentHandler struct {
+	CommentRepo   *repositories.CommentRepository
+	EventRepo     *repositories.EventRepository
+	UserRepo      *repositories.UserRepository
+	EmailService  *services.EmailService
+	AccessService *services.AccessService
+}
+
+func NewCommentHandler(
+	commentRepo *repositories.CommentRepository,
+	eventRepo *repositories.EventRepository,
+	userRepo *repositories.UserRepository,
+	emailService *services.EmailService,
+	accessService *services.AccessService,
+) *CommentHandler {
+	return &CommentHandler{
+		CommentRepo:   commentRepo,
+		EventRepo:     eventRepo,
+		UserRepo:      userRepo,
+		EmailService:  emailService,
+		AccessService: accessService,
+	}
+}
+
+// GetComments handles listing a page of an event's discussion threads
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	comments, next, err := h.CommentRepo.GetComments(eventID, limit, cursor)
+	if err != nil {
+		writeListError(w, err, "Failed to get comments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Page[models.Comment]{
+		Items:      comments,
+		NextCursor: repositories.EncodeCursor(next),
+	})
+}
+
+// CreateComment handles posting a comment or a reply to one. The organizer is
+// emailed about new threads.
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	req, ok := decodeCommentRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Replies belong to the thread they were made in, so replying to a reply
+	// answers its top-level comment
+	if req.ParentID != nil {
+		parent, err := h.CommentRepo.GetCommentByID(event.ID, *req.ParentID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Comment to reply to not found", http.StatusNotFound)
+				log.Printf("Parent comment %d not found in event %d\n", *req.ParentID, event.ID)
+				return
+			}
+			http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+			log.Printf("Failed to get parent comment: %v\n", err)
+			return
+		}
+		if parent.ParentID != nil {
+			req.ParentID = parent.ParentID
+		} else if parent.Deleted {
+			http.Error(w, "Cannot reply to a deleted comment", http.StatusConflict)
+			log.Printf("User %d tried to reply to deleted comment %d\n", userID, parent.ID)
+			return
+		}
+	}
+
+	id, err := h.CommentRepo.CreateComment(event.ID, userID, req)
+	if err != nil {
+		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+		log.Printf("Failed to create comment: %v\n", err)
+		return
+	}
+
+	comment, err := h.CommentRepo.GetCommentByID(event.ID, id)
+	if err != nil {
+		http.Error(w, "Failed to get comment", http.StatusInternalServerError)
+		log.Printf("Failed to get comment: %v\n", err)
+		return
+	}
+
+	if req.ParentID == nil && userID != event.UserID {
+		h.notifyOrganizer(event, userID, comment.Body)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+	log.Printf("Comment %d posted to event %d by user %d\n", id, event.ID, userID)
+}
+
+// UpdateComment handles the author of a comment editing it
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, comment, ok := h.getComment(w, r)
+	if !ok {
+		return
+	}
+
+	if comment.UserID != userID {
+		http.Error(w, "Forbidden: You can only edit your own comments", http.StatusForbidden)
+		log.Printf("Forbidden: User %d tried to edit comment %d\n", userID, comment.ID)
+		return
+	}
+
+	req, ok := decodeCommentRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.CommentRepo.UpdateComment(comment.ID, req.Body); err != nil {
+		http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+		log.Printf("Failed to update comment: %v\n", err)
+		return
+	}
+
+	updated, err := h.CommentRepo.GetCommentByID(event.ID, comment.ID)
+	if err != nil {
+		http.Error(w, "Failed to get comment", http.StatusInternalServerError)
+		log.Printf("Failed to get comment: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	log.Printf("Comment %d of event %d edited by user %d\n", comment.ID, event.ID, userID)
+}
+
+// DeleteComment handles the author or an organizer deleting a comment
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, comment, ok := h.getComment(w, r)
+	if !ok {
+		return
+	}
+
+	// Organizers moderate the discussion and may delete anyone's comments
+	if comment.UserID != userID && !authorize(w, h.AccessService, event, userID, services.PermissionModerate) {
+		return
+	}
+
+	if err := h.CommentRepo.DeleteComment(comment.ID); err != nil {
+		http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+		log.Printf("Failed to delete comment: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Comment deleted successfully",
+	})
+	log.Printf("Comment %d of event %d deleted by user %d\n", comment.ID, event.ID, userID)
+}
+
+// PinComment handles an organizer pinning a thread to the top of the discussion
+func (h *CommentHandler) PinComment(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true)
+}
+
+// UnpinComment handles an organizer unpinning a thread
+func (h *CommentHandler) UnpinComment(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false)
+}
+
+// setPinned pins or unpins the top-level comment in the URL
+func (h *CommentHandler) setPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	userID, event, comment, ok := h.getComment(w, r)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionModerate) {
+		return
+	}
+
+	if comment.ParentID != nil || comment.Deleted {
+		http.Error(w, "Only top-level comments can be pinned", http.StatusBadRequest)
+		log.Printf("Comment %d cannot be pinned\n", comment.ID)
+		return
+	}
+
+	if err := h.CommentRepo.SetPinned(comment.ID, pinned); err != nil {
+		http.Error(w, "Failed to pin comment", http.StatusInternalServerError)
+		log.Printf("Failed to pin comment: %v\n", err)
+		return
+	}
+
+	message := "Comment unpinned successfully"
+	if pinned {
+		message = "Comment pinned successfully"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": message,
+	})
+	log.Printf("Comment %d of event %d pinned=%t by user %d\n", comment.ID, event.ID, pinned, userID)
+}
+
+// getComment authenticates the request and loads the event and comment from the URL.
+// Deleted comments are treated as missing.
+func (h *CommentHandler) getComment(w http.ResponseWriter, r *http.Request) (int, *models.EventWithOrganizer, *models.Comment, bool) {
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return 0, nil, nil, false
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return 0, nil, nil, false
+	}
+
+	commentID, err := getPathID(r, "comments")
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		log.Printf("Invalid comment ID: %v\n", err)
+		return 0, nil, nil, false
+	}
+
+	comment, err := h.CommentRepo.GetCommentByID(event.ID, commentID)
+	if err == nil && comment.Deleted {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Comment not found", http.StatusNotFound)
+			log.Printf("Comment %d not found in event %d\n", commentID, event.ID)
+			return 0, nil, nil, false
+		}
+		http.Error(w, "Failed to get comment", http.StatusInternalServerError)
+		log.Printf("Failed to get comment: %v\n", err)
+		return 0, nil, nil, false
+	}
+
+	return userID, event, comment, true
+}
+
+// notifyOrganizer emails the event's organizer about a new thread in the background
+func (h *CommentHandler) notifyOrganizer(event *models.EventWithOrganizer, commenterID int, body string) {
+	commenter, err := h.UserRepo.GetUserByID(commenterID)
+	if err != nil {
+		log.Printf("Warning: Could not get commenter details: %v\n", err)
+		return
+	}
+	organizer, err := h.UserRepo.GetUserByID(event.UserID)
+	if err != nil {
+		log.Printf("Warning: Could not get organizer details: %v\n", err)
+		return
+	}
+
+	eventModel := emailEvent(event, event.Date, organizer.Email)
+	go func() {
+		if err := h.EmailService.SendNewCommentToOrganizer(eventModel, commenter, body); err != nil {
+			log.Printf("Error sending comment notification: %v\n", err)
+		}
+	}()
+}
+
+// decodeCommentRequest reads and validates a comment post or edit request.
+// It writes a 400 response and returns false if the request is invalid.
+func decodeCommentRequest(w http.ResponseWriter, r *http.Request) (models.CommentRequest, bool) {
+	var req models.CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return req, false
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" || len(req.Body) > maxCommentLength {
+		http.Error(w, fmt.Sprintf("Comment is required and can be at most %d characters", maxCommentLength), http.StatusBadRequest)
+		log.Printf("Invalid comment length: %d\n", len(req.Body))
+		return req, false
+	}
+
+	return req, true
+}
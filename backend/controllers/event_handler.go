@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -13,16 +14,37 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/recurrence"
 	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
 )
 
 // EventHandler handles event-related HTTP requests
 type EventHandler struct {
-	EventRepo *repositories.EventRepository
+	EventRepo       *repositories.EventRepository
+	AttachmentRepo  *repositories.AttachmentRepository
+	VenueRepo       *repositories.VenueRepository
+	AccessService   *services.AccessService
+	MediaService    *services.MediaService
+	WaitlistService *services.WaitlistService
 }
 
-func NewEventHandler(eventRepo *repositories.EventRepository) *EventHandler {
-	return &EventHandler{EventRepo: eventRepo}
+func NewEventHandler(
+	eventRepo *repositories.EventRepository,
+	attachmentRepo *repositories.AttachmentRepository,
+	venueRepo *repositories.VenueRepository,
+	accessService *services.AccessService,
+	mediaService *services.MediaService,
+	waitlistService *services.WaitlistService,
+) *EventHandler {
+	return &EventHandler{
+		EventRepo:       eventRepo,
+		AttachmentRepo:  attachmentRepo,
+		VenueRepo:       venueRepo,
+		AccessService:   accessService,
+		MediaService:    mediaService,
+		WaitlistService: waitlistService,
+	}
 }
 
 // CreateEvent handles event creation
@@ -48,28 +70,60 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Location) == "" {
-		http.Error(w, "Title and location are required", http.StatusBadRequest)
-		log.Println("Title and location are required")
+	createEvent(w, h.EventRepo, h.VenueRepo, req, userID)
+}
+
+// DuplicateEvent handles copying an event the user owns to a new date. Fields
+// in the request body override the copied ones.
+func (h *EventHandler) DuplicateEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
 		return
 	}
 
-	// Create event
-	id, err := h.EventRepo.CreateEvent(req, userID)
-	if err != nil {
-		http.Error(w, "Failed to create event", http.StatusInternalServerError)
-		log.Printf("Failed to create event: %v\n", err)
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
 		return
 	}
 
-	// Return success response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":      id,
-		"message": "Event created successfully",
-	})
-	log.Println("Event created successfully")
+	if !authorize(w, h.AccessService, event, userID, services.PermissionDuplicateEvent) {
+		return
+	}
+
+	// The copy keeps the event's length but needs a new date
+	req := eventRequestFrom(event)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	if id, ok := createEvent(w, h.EventRepo, h.VenueRepo, req, userID); ok {
+		log.Printf("Event %d duplicated as event %d by user %d\n", event.ID, id, userID)
+	}
+}
+
+// eventRequestFrom returns a request that recreates an event without its date,
+// keeping its length as a duration so the copy can start at any time
+func eventRequestFrom(event *models.EventWithOrganizer) models.EventRequest {
+	return models.EventRequest{
+		Title:            event.Title,
+		Description:      event.Description,
+		DurationMinutes:  int(event.Duration().Minutes()),
+		TimeZone:         event.TimeZone,
+		Location:         event.Location,
+		Latitude:         event.Latitude,
+		Longitude:        event.Longitude,
+		RecurrenceRule:   event.RecurrenceRule,
+		Capacity:         event.Capacity,
+		MaxGuests:        event.MaxGuests,
+		RequiresApproval: event.RequiresApproval,
+		Visibility:       event.Visibility,
+		Tags:             append([]string{}, event.Tags...),
+		VenueID:          event.VenueID,
+		RoomID:           event.RoomID,
+	}
 }
 
 // GetUserEvents handles retrieving events for a user
@@ -88,21 +142,31 @@ func (h *EventHandler) GetUserEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
+		return
+	}
+
 	// Get events
-	events, err := h.EventRepo.GetEventsByUserID(userID)
+	events, next, err := h.EventRepo.GetEventsByUserID(userID, limit, cursor)
 	if err != nil {
-		http.Error(w, "Failed to get events", http.StatusInternalServerError)
-		log.Printf("Failed to get events: %v\n", err)
+		writeListError(w, err, "Failed to get events")
 		return
 	}
 
 	if events == nil {
 		events = []models.Event{}
 	}
+	for i := range events {
+		events[i].CoverImage = h.MediaService.CoverImage(events[i].CoverImageKey)
+	}
 
 	// Return events
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	json.NewEncoder(w).Encode(models.Page[models.Event]{
+		Items:      events,
+		NextCursor: repositories.EncodeCursor(next),
+	})
 }
 
 // GetUpcomingEvents handles retrieving upcoming public events
@@ -113,11 +177,22 @@ func (h *EventHandler) GetUpcomingEvents(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	var filter models.EventFilter
+	if err := getTagFilter(r, &filter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid tag filter: %v\n", err)
+		return
+	}
+
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
+		return
+	}
+
 	// Get upcoming events
-	events, err := h.EventRepo.GetUpcomingEvents()
+	events, next, err := h.EventRepo.GetUpcomingEvents(filter, limit, cursor)
 	if err != nil {
-		http.Error(w, "Failed to get upcoming events", http.StatusInternalServerError)
-		log.Printf("Failed to get upcoming events: %v\n", err)
+		writeListError(w, err, "Failed to get upcoming events")
 		return
 	}
 
@@ -125,10 +200,16 @@ func (h *EventHandler) GetUpcomingEvents(w http.ResponseWriter, r *http.Request)
 	if events == nil {
 		events = []models.Event{}
 	}
+	for i := range events {
+		events[i].CoverImage = h.MediaService.CoverImage(events[i].CoverImageKey)
+	}
 
 	// Return events
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	json.NewEncoder(w).Encode(models.Page[models.Event]{
+		Items:      events,
+		NextCursor: repositories.EncodeCursor(next),
+	})
 }
 
 // GetEventByID handles retrieving a single event by ID
@@ -169,12 +250,76 @@ func (h *EventHandler) GetEventByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Private events are hidden from anyone without access
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	// Let the client know what the requesting user may do with the event
+	if userID := getOptionalUserID(r); userID != 0 {
+		role, err := h.AccessService.Role(event, userID)
+		if err != nil {
+			http.Error(w, "Failed to get event", http.StatusInternalServerError)
+			log.Printf("Failed to get event role: %v\n", err)
+			return
+		}
+		event.Role = role
+	}
+
+	// The version covers everything returned below, so clients that already have
+	// it are told so without the rest of the event being loaded
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, eventETag(event.Version), true) {
+		setEventCacheHeaders(w, event.Version)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// Return a single occurrence of a recurring event if requested
+	occurrence, err := getOccurrenceFromQuery(r)
+	if err != nil {
+		http.Error(w, "Invalid occurrence. Use RFC 3339 format", http.StatusBadRequest)
+		log.Printf("Invalid occurrence: %v\n", err)
+		return
+	}
+	if occurrence != nil && event.RecurrenceRule != "" {
+		date, err := h.EventRepo.ResolveOccurrence(event, occurrence)
+		if err != nil {
+			writeOccurrenceError(w, err)
+			return
+		}
+		event.OccurrenceDate = occurrence
+		event.Rescheduled = !date.Equal(*occurrence)
+		event.Date = date
+	}
+
+	// Include the cover image and attachments
+	event.CoverImage = h.MediaService.CoverImage(event.CoverImageKey)
+	event.Attachments, err = h.AttachmentRepo.GetAttachments(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event attachments: %v\n", err)
+		return
+	}
+	withAttachmentURLs(h.MediaService, event.Attachments)
+
+	// Include the venue the event is booked at
+	if event.VenueID != nil {
+		event.Venue, err = h.VenueRepo.GetVenueByID(*event.VenueID)
+		if err != nil {
+			http.Error(w, "Failed to get event", http.StatusInternalServerError)
+			log.Printf("Failed to get event venue: %v\n", err)
+			return
+		}
+	}
+
 	// Return event
+	setEventCacheHeaders(w, event.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(event)
 }
 
-// DeleteEvent handles event deletion
+// DeleteEvent handles moving an event to the trash, where its owner can restore
+// it until it is purged
 func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -207,7 +352,7 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the event belongs to the user
+	// Check if the user may manage the event
 	event, err := h.EventRepo.GetEventByID(eventID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -220,13 +365,11 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if event.UserID != userID {
-		http.Error(w, "Unauthorized: You can only delete your own events", http.StatusForbidden)
-		log.Printf("Unauthorized: User %d attempted to delete event %d owned by user %d\n", userID, eventID, event.UserID)
+	if !authorize(w, h.AccessService, event, userID, services.PermissionDeleteEvent) {
 		return
 	}
 
-	// Delete the event
+	// Move the event to the trash. Its RSVPs and stored files are kept until it is purged.
 	err = h.EventRepo.DeleteEvent(eventID)
 	if err != nil {
 		http.Error(w, "Failed to delete event", http.StatusInternalServerError)
@@ -237,9 +380,9 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Event deleted successfully",
+		"message": "Event moved to trash",
 	})
-	log.Printf("Event %d deleted successfully by user %d\n", eventID, userID)
+	log.Printf("Event %d moved to trash by user %d\n", eventID, userID)
 }
 
 // UpdateEvent handles event updates
@@ -275,7 +418,7 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the event belongs to the user
+	// Check if the user may manage the event
 	event, err := h.EventRepo.GetEventByID(eventID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -288,9 +431,19 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if event.UserID != userID {
-		http.Error(w, "Unauthorized: You can only update your own events", http.StatusForbidden)
-		log.Printf("Unauthorized: User %d attempted to update event %d owned by user %d\n", userID, eventID, event.UserID)
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	if event.Status == models.EventStatusCancelled {
+		http.Error(w, "Cancelled events cannot be edited", http.StatusConflict)
+		log.Printf("Event %d is cancelled\n", eventID)
+		return
+	}
+
+	// Updates must be based on the current version so concurrent edits aren't lost
+	version, ok := checkIfMatch(w, r, event, true)
+	if !ok {
 		return
 	}
 
@@ -302,25 +455,57 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Location) == "" {
-		http.Error(w, "Title and location are required", http.StatusBadRequest)
-		log.Println("Title and location are required")
+	if !applyVenue(w, h.VenueRepo, &req) {
+		return
+	}
+
+	if err := validateEventRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid event: %v\n", err)
+		return
+	}
+
+	conflicts, ok := checkVenueConflicts(w, h.EventRepo, req, eventID, userID)
+	if !ok {
 		return
 	}
 
 	// Update the event
-	err = h.EventRepo.UpdateEvent(eventID, req)
+	version, revision, promotions, err := h.EventRepo.UpdateEvent(eventID, userID, version, req)
 	if err != nil {
+		if errors.Is(err, repositories.ErrVersionMismatch) {
+			writeVersionMismatch(w, eventID)
+			return
+		}
+		if writeVenueConflictError(w, err, userID) {
+			return
+		}
+		if errors.Is(err, repositories.ErrScheduleLocked) {
+			http.Error(w, "The new schedule would drop or move occurrences that have RSVPs or were cancelled or rescheduled", http.StatusConflict)
+			log.Printf("Event %d schedule change would strand RSVPs or exceptions\n", eventID)
+			return
+		}
 		http.Error(w, "Failed to update event", http.StatusInternalServerError)
 		log.Printf("Failed to update event: %v\n", err)
 		return
 	}
 
-	// Return success response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	// Let anyone moved off a waitlist by a larger capacity know they have a seat
+	notifyPromotions(h.EventRepo, h.WaitlistService, eventID, promotions)
+
+	// Return success response, including any overlapping bookings that were allowed
+	response := map[string]interface{}{
 		"message": "Event updated successfully",
-	})
+	}
+	if revision > 0 {
+		response["revision"] = revision
+	}
+	if len(conflicts) > 0 {
+		response["conflicts"] = conflicts
+	}
+	w.Header().Set("ETag", eventETag(version))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
 	log.Printf("Event %d updated successfully by user %d\n", eventID, userID)
 }
 
@@ -338,11 +523,23 @@ func (h *EventHandler) SearchEvents(w http.ResponseWriter, r *http.Request) {
 	startDateStr := r.URL.Query().Get("start_date")
 	endDateStr := r.URL.Query().Get("end_date")
 
+	// Dates are interpreted in the caller's time zone, UTC by default
+	loc := time.UTC
+	if zone := r.URL.Query().Get("timezone"); zone != "" {
+		var err error
+		loc, err = time.LoadLocation(zone)
+		if err != nil {
+			http.Error(w, "Invalid time zone. Use an IANA zone name", http.StatusBadRequest)
+			log.Printf("Invalid time zone: %v\n", err)
+			return
+		}
+	}
+
 	var startDate, endDate *time.Time
 
 	// Parse start date if provided
 	if startDateStr != "" {
-		parsedDate, err := time.Parse("2006-01-02", startDateStr)
+		parsedDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
 		if err != nil {
 			http.Error(w, "Invalid start date format. Use YYYY-MM-DD", http.StatusBadRequest)
 			log.Printf("Invalid start date format: %v\n", err)
@@ -353,22 +550,63 @@ func (h *EventHandler) SearchEvents(w http.ResponseWriter, r *http.Request) {
 
 	// Parse end date if provided
 	if endDateStr != "" {
-		parsedDate, err := time.Parse("2006-01-02", endDateStr)
+		parsedDate, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
 		if err != nil {
 			http.Error(w, "Invalid end date format. Use YYYY-MM-DD", http.StatusBadRequest)
 			log.Printf("Invalid end date format: %v\n", err)
 			return
 		}
-		// Set the end date to the end of the day
-		parsedDate = parsedDate.Add(23 * time.Hour).Add(59 * time.Minute).Add(59 * time.Second)
+		// Set the end date to the end of the day, which isn't always 24 hours long
+		// in the caller's time zone
+		parsedDate = parsedDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
 		endDate = &parsedDate
 	}
 
+	// Sort by relevance when searching for text, by distance when searching near a point, by date otherwise
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy != "" && sortBy != models.SortByRelevance && sortBy != models.SortByDate && sortBy != models.SortByDistance {
+		http.Error(w, "Invalid sort. Must be 'relevance', 'date', or 'distance'", http.StatusBadRequest)
+		log.Printf("Invalid sort: %s\n", sortBy)
+		return
+	}
+
+	// Parse the radius filter if provided
+	near, radiusKm, err := getNearFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid radius filter: %v\n", err)
+		return
+	}
+	if sortBy == models.SortByDistance && near == nil {
+		http.Error(w, "Sorting by distance requires near=lat,lng", http.StatusBadRequest)
+		log.Println("Sort by distance without near")
+		return
+	}
+
+	filter := models.EventFilter{
+		Query:     query,
+		Location:  location,
+		StartDate: startDate,
+		EndDate:   endDate,
+		SortBy:    sortBy,
+		Near:      near,
+		RadiusKm:  radiusKm,
+	}
+	if err := getTagFilter(r, &filter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid tag filter: %v\n", err)
+		return
+	}
+
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
+		return
+	}
+
 	// Search events
-	events, err := h.EventRepo.SearchEvents(query, location, startDate, endDate)
+	events, next, err := h.EventRepo.SearchEvents(filter, limit, cursor)
 	if err != nil {
-		http.Error(w, "Failed to search events", http.StatusInternalServerError)
-		log.Printf("Failed to search events: %v\n", err)
+		writeListError(w, err, "Failed to search events")
 		return
 	}
 
@@ -376,10 +614,677 @@ func (h *EventHandler) SearchEvents(w http.ResponseWriter, r *http.Request) {
 	if events == nil {
 		events = []models.EventWithOrganizer{}
 	}
+	for i := range events {
+		events[i].CoverImage = h.MediaService.CoverImage(events[i].CoverImageKey)
+	}
 
 	// Return events
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	json.NewEncoder(w).Encode(models.Page[models.EventWithOrganizer]{
+		Items:      events,
+		NextCursor: repositories.EncodeCursor(next),
+	})
+}
+
+// GetOccurrences handles listing the occurrences of a recurring event
+func (h *EventHandler) GetOccurrences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return
+	}
+
+	// Private and unpublished events are hidden from anyone without access
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	if event.RecurrenceRule == "" {
+		http.Error(w, "Event is not recurring", http.StatusBadRequest)
+		log.Printf("Event %d is not recurring\n", eventID)
+		return
+	}
+
+	// Default to the next 90 days
+	from := time.Now()
+	to := from.AddDate(0, 0, 90)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			http.Error(w, "Invalid from date. Use RFC 3339 format", http.StatusBadRequest)
+			log.Printf("Invalid from date: %v\n", err)
+			return
+		}
+	}
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			http.Error(w, "Invalid to date. Use RFC 3339 format", http.StatusBadRequest)
+			log.Printf("Invalid to date: %v\n", err)
+			return
+		}
+	}
+
+	occurrences, err := h.EventRepo.GetOccurrences(event, from, to)
+	if err != nil {
+		http.Error(w, "Failed to get occurrences", http.StatusInternalServerError)
+		log.Printf("Failed to get occurrences: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(occurrences)
+}
+
+// UpdateOccurrence handles cancelling, rescheduling or restoring a single occurrence of a recurring event
+func (h *EventHandler) UpdateOccurrence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	action := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if action != "cancel" && action != "reschedule" && action != "restore" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		log.Printf("Unknown occurrence action: %s\n", action)
+		return
+	}
+
+	// Check if the user may manage the event
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	if event.RecurrenceRule == "" {
+		http.Error(w, "Event is not recurring", http.StatusBadRequest)
+		log.Printf("Event %d is not recurring\n", eventID)
+		return
+	}
+
+	var req models.OccurrenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	rule, err := recurrence.Parse(event.RecurrenceRule)
+	if err != nil || !rule.Includes(event.Date, req.OccurrenceDate) {
+		writeOccurrenceError(w, repositories.ErrInvalidOccurrence)
+		return
+	}
+
+	switch action {
+	case "cancel":
+		err = h.EventRepo.SetOccurrenceException(eventID, req.OccurrenceDate, true, nil, false)
+	case "reschedule":
+		if req.NewDate == nil || req.NewDate.IsZero() {
+			http.Error(w, "New date is required", http.StatusBadRequest)
+			log.Println("New date is required")
+			return
+		}
+		err = h.EventRepo.SetOccurrenceException(eventID, req.OccurrenceDate, false, req.NewDate, req.AllowConflicts)
+	case "restore":
+		err = h.EventRepo.DeleteOccurrenceException(eventID, req.OccurrenceDate)
+	}
+	if err != nil {
+		if writeVenueConflictError(w, err, userID) {
+			return
+		}
+		http.Error(w, "Failed to update occurrence", http.StatusInternalServerError)
+		log.Printf("Failed to %s occurrence: %v\n", action, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Occurrence updated successfully",
+	})
+	log.Printf("Occurrence %s of event %d updated (%s) by user %d\n", req.OccurrenceDate.Format(time.RFC3339), eventID, action, userID)
+}
+
+// validateEventRequest checks and normalizes an event create or update request
+func validateEventRequest(req *models.EventRequest) error {
+	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Location) == "" {
+		return errors.New("Title and location are required")
+	}
+
+	if req.Date.IsZero() {
+		return errors.New("Date is required")
+	}
+
+	// Default to UTC and public
+	if strings.TrimSpace(req.TimeZone) == "" {
+		req.TimeZone = "UTC"
+	}
+	if req.Visibility == "" {
+		req.Visibility = "public"
+	}
+
+	if err := validateEventFields(req); err != nil {
+		return err
+	}
+
+	if req.EndDate == nil && req.DurationMinutes > 0 {
+		endDate := req.Date.Add(time.Duration(req.DurationMinutes) * time.Minute)
+		req.EndDate = &endDate
+	}
+	if req.EndDate != nil && !req.EndDate.After(req.Date) {
+		return errors.New("End date must be after the start date")
+	}
+
+	return nil
+}
+
+// validateEventFields checks and normalizes the optional fields of an event request
+// that are set, so it also works for the partial fields of a template
+func validateEventFields(req *models.EventRequest) error {
+	// Reject unknown zones
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			return fmt.Errorf("Invalid time zone %q. Use an IANA zone name such as Africa/Nairobi", req.TimeZone)
+		}
+	}
+
+	if req.DurationMinutes < 0 {
+		return errors.New("Duration must be positive")
+	}
+
+	if req.Visibility != "" && req.Visibility != "public" && req.Visibility != "unlisted" && req.Visibility != "private" {
+		return errors.New("Invalid visibility. Must be 'public', 'unlisted', or 'private'")
+	}
+
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return errors.New("Latitude and longitude must be provided together")
+	}
+	if req.Latitude != nil && !validCoordinates(*req.Latitude, *req.Longitude) {
+		return errors.New("Latitude must be between -90 and 90 and longitude between -180 and 180")
+	}
+
+	if req.Capacity != nil && *req.Capacity < 1 {
+		return errors.New("Capacity must be at least 1")
+	}
+
+	if req.MaxGuests < 0 || req.MaxGuests > maxGuestsPerRSVP {
+		return fmt.Errorf("Guests per RSVP must be between 0 and %d", maxGuestsPerRSVP)
+	}
+
+	if req.RSVPOpensAt != nil && req.RSVPClosesAt != nil && !req.RSVPClosesAt.After(*req.RSVPOpensAt) {
+		return errors.New("RSVPs must close after they open")
+	}
+
+	if req.RoomID != nil && req.VenueID == nil {
+		return errors.New("A room can only be booked together with its venue")
+	}
+
+	if req.RecurrenceRule != "" {
+		rule, err := recurrence.Parse(req.RecurrenceRule)
+		if err != nil {
+			return fmt.Errorf("Invalid recurrence rule: %v", err)
+		}
+		req.RecurrenceRule = rule.String()
+	}
+
+	if req.Tags != nil {
+		req.Tags = normalizeTags(req.Tags)
+		if len(req.Tags) > maxEventTags {
+			return fmt.Errorf("An event can have at most %d tags", maxEventTags)
+		}
+		for _, tag := range req.Tags {
+			if len(tag) > maxTagLength {
+				return fmt.Errorf("Tag %q is too long. Tags can be at most %d characters", tag, maxTagLength)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateNewEventStatus checks the lifecycle state requested for a new event,
+// defaulting to published. Setting publish_at schedules the event instead.
+func validateNewEventStatus(req *models.EventRequest) error {
+	if req.Status == "" {
+		req.Status = models.EventStatusPublished
+	}
+	if req.Status != models.EventStatusDraft && req.Status != models.EventStatusPublished {
+		return errors.New("Invalid status. Must be 'draft' or 'published'")
+	}
+
+	if req.PublishAt != nil {
+		if !req.PublishAt.After(time.Now()) {
+			return errors.New("Publish time must be in the future")
+		}
+		req.Status = models.EventStatusScheduled
+	}
+
+	return nil
+}
+
+// Limits on the tags of an event
+const (
+	maxEventTags = 10
+	maxTagLength = 50
+)
+
+// normalizeTags lowercases and trims tags, dropping empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// Radius limits for searches near a point
+const (
+	defaultRadiusKm = 25
+	maxRadiusKm     = 20000
+)
+
+// validCoordinates reports whether a latitude and longitude are in range
+func validCoordinates(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+// getNearFilter reads the optional near=lat,lng and radius_km query parameters.
+// It returns a nil point when near is not set.
+func getNearFilter(r *http.Request) (*models.Coordinates, float64, error) {
+	nearStr := r.URL.Query().Get("near")
+	if nearStr == "" {
+		return nil, 0, nil
+	}
+
+	latStr, lngStr, ok := strings.Cut(nearStr, ",")
+	if !ok {
+		return nil, 0, errors.New("Invalid near. Use near=lat,lng")
+	}
+	latitude, latErr := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	longitude, lngErr := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+	if latErr != nil || lngErr != nil || !validCoordinates(latitude, longitude) {
+		return nil, 0, errors.New("Invalid near. Use near=lat,lng in decimal degrees")
+	}
+
+	radiusKm := float64(defaultRadiusKm)
+	if radiusStr := r.URL.Query().Get("radius_km"); radiusStr != "" {
+		var err error
+		radiusKm, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radiusKm <= 0 || radiusKm > maxRadiusKm {
+			return nil, 0, fmt.Errorf("Invalid radius_km. Must be greater than 0 and at most %d", maxRadiusKm)
+		}
+	}
+
+	return &models.Coordinates{Latitude: latitude, Longitude: longitude}, radiusKm, nil
+}
+
+// getTagFilter reads the tag filter from the query string. Tags can be repeated
+// (tag=music&tag=outdoor) or comma separated, and tag_mode chooses whether events
+// must match any (the default) or all of them.
+func getTagFilter(r *http.Request, filter *models.EventFilter) error {
+	var tags []string
+	for _, value := range r.URL.Query()["tag"] {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+	filter.Tags = normalizeTags(tags)
+
+	switch r.URL.Query().Get("tag_mode") {
+	case "", "any":
+		filter.MatchAllTags = false
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		return errors.New("Invalid tag_mode. Must be 'any' or 'all'")
+	}
+
+	return nil
+}
+
+// getOccurrenceFromQuery parses the optional occurrence query parameter
+func getOccurrenceFromQuery(r *http.Request) (*time.Time, error) {
+	occurrenceStr := r.URL.Query().Get("occurrence")
+	if occurrenceStr == "" {
+		return nil, nil
+	}
+
+	occurrence, err := time.Parse(time.RFC3339, occurrenceStr)
+	if err != nil {
+		return nil, err
+	}
+	return &occurrence, nil
+}
+
+// writeOccurrenceError writes the response for an error returned by ResolveOccurrence
+func writeOccurrenceError(w http.ResponseWriter, err error) {
+	switch err {
+	case repositories.ErrOccurrenceRequired:
+		http.Error(w, "The occurrence query parameter is required for recurring events", http.StatusBadRequest)
+	case repositories.ErrInvalidOccurrence:
+		http.Error(w, "Occurrence not found", http.StatusNotFound)
+	case repositories.ErrOccurrenceCancelled:
+		http.Error(w, "This occurrence has been cancelled", http.StatusGone)
+	default:
+		http.Error(w, "Failed to resolve occurrence", http.StatusInternalServerError)
+	}
+	log.Printf("Failed to resolve occurrence: %v\n", err)
+}
+
+// canViewEvent checks that the requesting user, or the invite token they present,
+// may see the event. It writes a 404 response and returns false otherwise so
+// private events and drafts are indistinguishable from missing ones.
+func canViewEvent(w http.ResponseWriter, r *http.Request, accessService *services.AccessService, event *models.EventWithOrganizer) bool {
+	userID := getOptionalUserID(r)
+	allowed, err := accessService.CanViewEvent(event, userID, getInviteToken(r))
+	if err != nil {
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to check event access: %v\n", err)
+		return false
+	}
+
+	if !allowed {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		log.Printf("User %d denied access to event %d\n", userID, event.ID)
+		return false
+	}
+
+	return true
+}
+
+// Helper function to check that the user holds a permission on the event. It
+// writes a 403 response and returns false otherwise.
+func authorize(w http.ResponseWriter, accessService *services.AccessService, event *models.EventWithOrganizer, userID int, permission services.Permission) bool {
+	allowed, err := accessService.Can(event, userID, permission)
+	if err != nil {
+		http.Error(w, "Failed to check permissions", http.StatusInternalServerError)
+		log.Printf("Failed to check permissions: %v\n", err)
+		return false
+	}
+
+	if !allowed {
+		http.Error(w, "Forbidden: You do not have permission to do this for this event", http.StatusForbidden)
+		log.Printf("Forbidden: User %d lacks %s permission on event %d\n", userID, permission, event.ID)
+		return false
+	}
+
+	return true
+}
+
+// Helper function to read the limit and cursor query parameters of list endpoints.
+// It writes a 400 response and returns false if either is invalid.
+func getPageParams(w http.ResponseWriter, r *http.Request) (int, *repositories.Cursor, bool) {
+	limit := repositories.DefaultPageSize
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > repositories.MaxPageSize {
+			http.Error(w, fmt.Sprintf("Invalid limit. Must be between 1 and %d", repositories.MaxPageSize), http.StatusBadRequest)
+			log.Printf("Invalid limit: %s\n", limitStr)
+			return 0, nil, false
+		}
+	}
+
+	var cursor *repositories.Cursor
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		var err error
+		cursor, err = repositories.DecodeCursor(cursorStr)
+		if err != nil {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			log.Printf("Invalid cursor: %v\n", err)
+			return 0, nil, false
+		}
+	}
+
+	return limit, cursor, true
+}
+
+// Helper function to format an event's version as its ETag
+func eventETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// Helper function to set the ETag of an event on a response. Clients must
+// revalidate it, and it depends on who is asking since it includes their role.
+func setEventCacheHeaders(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", eventETag(version))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Vary", "Authorization, X-Invite-Token")
+}
+
+// Helper function to report whether an If-Match or If-None-Match header lists an
+// ETag. Weak tags only match when weak is set, as If-None-Match compares weakly.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to check the If-Match header of a request to change an event
+// against the event's current ETag. It returns the version the change must be
+// applied to, or 0 when any version will do. It writes a 428 response when the
+// header is required but missing, or a 412 response when it doesn't match.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, event *models.EventWithOrganizer, required bool) (int, bool) {
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		if required {
+			http.Error(w, "If-Match header with the event's ETag is required", http.StatusPreconditionRequired)
+			log.Printf("Update of event %d without If-Match\n", event.ID)
+			return 0, false
+		}
+		return 0, true
+	}
+
+	if strings.TrimSpace(match) == "*" {
+		return 0, true
+	}
+	if !etagMatches(match, eventETag(event.Version), false) {
+		w.Header().Set("ETag", eventETag(event.Version))
+		writeVersionMismatch(w, event.ID)
+		return 0, false
+	}
+	return event.Version, true
+}
+
+// Helper function to write the response for a change based on an outdated version of an event
+func writeVersionMismatch(w http.ResponseWriter, eventID int) {
+	http.Error(w, "The event was changed by someone else. Reload it and try again", http.StatusPreconditionFailed)
+	log.Printf("Event %d changed since the version the request was based on\n", eventID)
+}
+
+// Helper function to write the response for an error returned by a paginated repository method
+func writeListError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor for this listing", http.StatusBadRequest)
+		log.Printf("Cursor does not match the listing: %v\n", err)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+	log.Printf("%s: %v\n", message, err)
+}
+
+// Helper function to validate a request for a new event, create it and write the
+// 201 response. It writes an error response and returns false if any step fails.
+func createEvent(w http.ResponseWriter, eventRepo *repositories.EventRepository, venueRepo *repositories.VenueRepository, req models.EventRequest, userID int) (int, bool) {
+	if !applyVenue(w, venueRepo, &req) {
+		return 0, false
+	}
+
+	if err := validateEventRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid event: %v\n", err)
+		return 0, false
+	}
+
+	// New events are published right away unless saved as a draft or scheduled
+	if err := validateNewEventStatus(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid event status: %v\n", err)
+		return 0, false
+	}
+
+	conflicts, ok := checkVenueConflicts(w, eventRepo, req, 0, userID)
+	if !ok {
+		return 0, false
+	}
+
+	// Create event
+	id, err := eventRepo.CreateEvent(req, userID)
+	if err != nil {
+		if writeVenueConflictError(w, err, userID) {
+			return 0, false
+		}
+		http.Error(w, "Failed to create event", http.StatusInternalServerError)
+		log.Printf("Failed to create event: %v\n", err)
+		return 0, false
+	}
+
+	// Return success response, including any overlapping bookings that were allowed
+	response := map[string]interface{}{
+		"id":      id,
+		"status":  req.Status,
+		"message": "Event created successfully",
+	}
+	if len(conflicts) > 0 {
+		response["conflicts"] = conflicts
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	log.Printf("Event %d created successfully by user %d\n", id, userID)
+	return id, true
+}
+
+// Helper function to authenticate the request and load the event from the URL.
+// It writes an error response and returns false if either fails.
+func getUserAndEvent(w http.ResponseWriter, r *http.Request, eventRepo *repositories.EventRepository) (int, *models.EventWithOrganizer, bool) {
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return 0, nil, false
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return 0, nil, false
+	}
+
+	event, err := eventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return 0, nil, false
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return 0, nil, false
+	}
+
+	return userID, event, true
+}
+
+// Helper function to read an invite token from the X-Invite-Token header or the invite query parameter
+func getInviteToken(r *http.Request) string {
+	if token := r.Header.Get("X-Invite-Token"); token != "" {
+		return token
+	}
+	return r.URL.Query().Get("invite")
+}
+
+// Helper function to get the user ID for endpoints that also allow anonymous access.
+// It returns 0 if the request has no valid token.
+func getOptionalUserID(r *http.Request) int {
+	if r.Header.Get("Authorization") == "" {
+		return 0
+	}
+
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		return 0
+	}
+	return userID
+}
+
+// Helper function to extract the ID that follows the given segment in the URL path,
+// e.g. getPathID(r, "events") returns 5 for /api/events/5/rsvp
+func getPathID(r *http.Request, after string) (int, error) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == after {
+			return strconv.Atoi(segments[i+1])
+		}
+	}
+	return 0, fmt.Errorf("no %s ID in path %s", after, r.URL.Path)
+}
+
+// Helper function to extract the event ID from the URL path
+func getEventIDFromPath(r *http.Request) (int, error) {
+	return getPathID(r, "events")
 }
 
 // Helper function to extract user ID from JWT token
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// InviteHandler handles invite link HTTP requests for private events
+type InviteHandler struct {
+	InviteRepo    *repositories.InviteRepository
+	EventRepo     *repositories.EventRepository
+	TokenService  *services.TokenService
+	AccessService *services.AccessService
+}
+
+func NewInviteHandler(
+	inviteRepo *repositories.InviteRepository,
+	eventRepo *repositories.EventRepository,
+	tokenService *services.TokenService,
+	accessService *services.AccessService,
+) *InviteHandler {
+	return &InviteHandler{
+		InviteRepo:    inviteRepo,
+		EventRepo:     eventRepo,
+		TokenService:  tokenService,
+		AccessService: accessService,
+	}
+}
+
+// CreateInvite handles generating a new shareable invite link for an event
+func (h *InviteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := h.authorizeOrganizer(w, r)
+	if !ok {
+		return
+	}
+
+	nonce, err := h.TokenService.NewNonce()
+	if err != nil {
+		http.Error(w, "Failed to create invite", http.StatusInternalServerError)
+		log.Printf("Failed to generate invite nonce: %v\n", err)
+		return
+	}
+
+	invite, err := h.InviteRepo.CreateInvite(event.ID, userID, nonce)
+	if err != nil {
+		http.Error(w, "Failed to create invite", http.StatusInternalServerError)
+		log.Printf("Failed to create invite: %v\n", err)
+		return
+	}
+	h.withToken(invite)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invite)
+	log.Printf("Invite %d created for event %d by user %d\n", invite.ID, event.ID, userID)
+}
+
+// GetInvites handles listing the invite links of an event
+func (h *InviteHandler) GetInvites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	_, event, ok := h.authorizeOrganizer(w, r)
+	if !ok {
+		return
+	}
+
+	invites, err := h.InviteRepo.GetInvites(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to get invites", http.StatusInternalServerError)
+		log.Printf("Failed to get invites: %v\n", err)
+		return
+	}
+
+	// Only active invites expose a usable token
+	for i := range invites {
+		if invites[i].RevokedAt == nil {
+			h.withToken(&invites[i])
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invites)
+}
+
+// RevokeInvite handles revoking an invite link so it no longer grants access
+func (h *InviteHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	inviteID, err := getPathID(r, "invites")
+	if err != nil {
+		http.Error(w, "Invalid invite ID", http.StatusBadRequest)
+		log.Printf("Invalid invite ID: %v\n", err)
+		return
+	}
+
+	userID, event, ok := h.authorizeOrganizer(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.InviteRepo.RevokeInvite(event.ID, inviteID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Invite not found", http.StatusNotFound)
+			log.Printf("Invite %d not found for event %d\n", inviteID, event.ID)
+			return
+		}
+		http.Error(w, "Failed to revoke invite", http.StatusInternalServerError)
+		log.Printf("Failed to revoke invite: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Invite revoked successfully",
+	})
+	log.Printf("Invite %d for event %d revoked by user %d\n", inviteID, event.ID, userID)
+}
+
+// authorizeOrganizer loads the event from the URL and checks the requesting user may manage its invites
+func (h *InviteHandler) authorizeOrganizer(w http.ResponseWriter, r *http.Request) (int, *models.EventWithOrganizer, bool) {
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return 0, nil, false
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionManageInvites) {
+		return 0, nil, false
+	}
+
+	return userID, event, true
+}
+
+// withToken fills in the signed token and shareable link of an invite
+func (h *InviteHandler) withToken(invite *models.EventInvite) {
+	invite.Token = h.TokenService.SignInviteToken(invite.EventID, invite.Nonce)
+	invite.URL = fmt.Sprintf("%s/event/%d?invite=%s", frontendBaseURL(), invite.EventID, invite.Token)
+}
+
+// frontendBaseURL returns the URL of the frontend for the current environment
+func frontendBaseURL() string {
+	if os.Getenv("ENVIRONMENT") == "production" {
+		if url := os.Getenv("FRONTEND_URL"); url != "" {
+			return url
+		}
+		return "https://evently-dgq9.onrender.com"
+	}
+	return "http://localhost:5173"
+}
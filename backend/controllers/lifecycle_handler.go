@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// LifecycleHandler handles HTTP requests that move an event between lifecycle states
+type LifecycleHandler struct {
+	EventRepo     *repositories.EventRepository
+	RSVPRepo      *repositories.RSVPRepository
+	UserRepo      *repositories.UserRepository
+	EmailService  *services.EmailService
+	AccessService *services.AccessService
+}
+
+func NewLifecycleHandler(
+	eventRepo *repositories.EventRepository,
+	rsvpRepo *repositories.RSVPRepository,
+	userRepo *repositories.UserRepository,
+	emailService *services.EmailService,
+	accessService *services.AccessService,
+) *LifecycleHandler {
+	return &LifecycleHandler{
+		EventRepo:     eventRepo,
+		RSVPRepo:      rsvpRepo,
+		UserRepo:      userRepo,
+		EmailService:  emailService,
+		AccessService: accessService,
+	}
+}
+
+// UpdateEventStatus handles publishing, scheduling, unpublishing and cancelling an event
+func (h *LifecycleHandler) UpdateEventStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	action := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if action != "publish" && action != "unpublish" && action != "cancel" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		log.Printf("Unknown event status action: %s\n", action)
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	// The body is optional
+	var req models.EventStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	var status string
+	var err error
+	switch action {
+	case "publish":
+		if req.PublishAt != nil && !req.PublishAt.After(time.Now()) {
+			http.Error(w, "Publish time must be in the future", http.StatusBadRequest)
+			log.Printf("Publish time in the past: %v\n", req.PublishAt)
+			return
+		}
+		status = models.EventStatusPublished
+		if req.PublishAt != nil {
+			status = models.EventStatusScheduled
+		}
+		err = h.EventRepo.PublishEvent(event.ID, req.PublishAt)
+	case "unpublish":
+		status = models.EventStatusDraft
+		err = h.EventRepo.UnpublishEvent(event.ID)
+	case "cancel":
+		status = models.EventStatusCancelled
+		err = h.EventRepo.CancelEvent(event.ID, strings.TrimSpace(req.Reason))
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidTransition) {
+			http.Error(w, fmt.Sprintf("Cannot %s an event that is %s", action, event.Status), http.StatusConflict)
+			log.Printf("Cannot %s event %d in status %s\n", action, event.ID, event.Status)
+			return
+		}
+		http.Error(w, "Failed to update event status", http.StatusInternalServerError)
+		log.Printf("Failed to %s event: %v\n", action, err)
+		return
+	}
+
+	if action == "cancel" {
+		h.notifyCancelled(event, strings.TrimSpace(req.Reason))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Event status updated successfully",
+		"status":  status,
+	})
+	log.Printf("Event %d moved from %s to %s by user %d\n", event.ID, event.Status, status, userID)
+}
+
+// notifyCancelled emails everyone who said they were going, might go, is
+// waitlisted or awaits approval that the event has been cancelled. Users with
+// RSVPs to several occurrences of a recurring event are only emailed once.
+func (h *LifecycleHandler) notifyCancelled(event *models.EventWithOrganizer, reason string) {
+	rsvps, err := h.RSVPRepo.GetRSVPsByStatus(event.ID, []string{"going", "maybe", "waitlisted", "pending"})
+	if err != nil {
+		log.Printf("Warning: Could not get attendees of cancelled event %d: %v\n", event.ID, err)
+		return
+	}
+
+	organizerEmail := ""
+	if organizer, err := h.UserRepo.GetUserByID(event.UserID); err == nil {
+		organizerEmail = organizer.Email
+	}
+	eventModel := emailEvent(event, event.Date, organizerEmail)
+
+	notified := map[int]bool{}
+	for _, rsvp := range rsvps {
+		if notified[rsvp.UserID] || rsvp.Email == "" {
+			continue
+		}
+		notified[rsvp.UserID] = true
+
+		attendee := &models.User{
+			ID:        rsvp.UserID,
+			FirstName: rsvp.FirstName,
+			LastName:  rsvp.LastName,
+			Email:     rsvp.Email,
+		}
+		go func() {
+			err := h.EmailService.SendEventCancellation(eventModel, attendee, reason)
+			if err != nil {
+				log.Printf("Error sending cancellation notice: %v\n", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// maxEventAttachments limits how many files can be attached to an event
+const maxEventAttachments = 20
+
+// MediaHandler handles HTTP requests for event cover images and attachments
+type MediaHandler struct {
+	EventRepo      *repositories.EventRepository
+	AttachmentRepo *repositories.AttachmentRepository
+	MediaService   *services.MediaService
+	AccessService  *services.AccessService
+}
+
+func NewMediaHandler(
+	eventRepo *repositories.EventRepository,
+	attachmentRepo *repositories.AttachmentRepository,
+	mediaService *services.MediaService,
+	accessService *services.AccessService,
+) *MediaHandler {
+	return &MediaHandler{
+		EventRepo:      eventRepo,
+		AttachmentRepo: attachmentRepo,
+		MediaService:   mediaService,
+		AccessService:  accessService,
+	}
+}
+
+// UploadCoverImage handles setting or replacing the cover image of an event
+func (h *MediaHandler) UploadCoverImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	_, data, ok := readUpload(w, r, services.MaxCoverImageSize)
+	if !ok {
+		return
+	}
+
+	key, err := h.MediaService.SaveCoverImage(event.ID, data)
+	if err != nil {
+		writeMediaError(w, err, "Unsupported image type. Upload a JPEG, PNG or GIF image")
+		return
+	}
+
+	previous, err := h.EventRepo.SetCoverImage(event.ID, key)
+	if err != nil {
+		h.MediaService.DeleteCoverImage(key)
+		http.Error(w, "Failed to save cover image", http.StatusInternalServerError)
+		log.Printf("Failed to save cover image: %v\n", err)
+		return
+	}
+	if previous != "" {
+		h.MediaService.DeleteCoverImage(previous)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.MediaService.CoverImage(key))
+	log.Printf("Cover image of event %d updated by user %d\n", event.ID, userID)
+}
+
+// DeleteCoverImage handles removing the cover image of an event
+func (h *MediaHandler) DeleteCoverImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	previous, err := h.EventRepo.SetCoverImage(event.ID, "")
+	if err != nil {
+		http.Error(w, "Failed to remove cover image", http.StatusInternalServerError)
+		log.Printf("Failed to remove cover image: %v\n", err)
+		return
+	}
+	if previous != "" {
+		h.MediaService.DeleteCoverImage(previous)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Cover image removed successfully",
+	})
+	log.Printf("Cover image of event %d removed by user %d\n", event.ID, userID)
+}
+
+// GetAttachments handles listing the attachments of an event
+func (h *MediaHandler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	attachments, err := h.AttachmentRepo.GetAttachments(eventID)
+	if err != nil {
+		http.Error(w, "Failed to get attachments", http.StatusInternalServerError)
+		log.Printf("Failed to get attachments: %v\n", err)
+		return
+	}
+	withAttachmentURLs(h.MediaService, attachments)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// UploadAttachment handles attaching a file such as an agenda or slides to an event
+func (h *MediaHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	count, err := h.AttachmentRepo.CountAttachments(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to save attachment", http.StatusInternalServerError)
+		log.Printf("Failed to count attachments: %v\n", err)
+		return
+	}
+	if count >= maxEventAttachments {
+		http.Error(w, fmt.Sprintf("An event can have at most %d attachments", maxEventAttachments), http.StatusConflict)
+		log.Printf("Event %d already has %d attachments\n", event.ID, count)
+		return
+	}
+
+	fileName, data, ok := readUpload(w, r, services.MaxAttachmentSize)
+	if !ok {
+		return
+	}
+
+	key, contentType, err := h.MediaService.SaveAttachment(event.ID, fileName, data)
+	if err != nil {
+		writeMediaError(w, err, "Unsupported file type. Upload a PDF, PowerPoint, Word, Excel, text or image file")
+		return
+	}
+
+	attachment := models.EventAttachment{
+		EventID:     event.ID,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		StorageKey:  key,
+		UploadedBy:  userID,
+	}
+	if err := h.AttachmentRepo.CreateAttachment(&attachment); err != nil {
+		h.MediaService.DeleteAttachment(key)
+		http.Error(w, "Failed to save attachment", http.StatusInternalServerError)
+		log.Printf("Failed to save attachment: %v\n", err)
+		return
+	}
+	attachment.URL = h.MediaService.URL(key)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+	log.Printf("Attachment %d added to event %d by user %d\n", attachment.ID, event.ID, userID)
+}
+
+// DeleteAttachment handles removing an attachment from an event
+func (h *MediaHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := getPathID(r, "attachments")
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		log.Printf("Invalid attachment ID: %v\n", err)
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	attachment, err := h.AttachmentRepo.GetAttachmentByID(event.ID, attachmentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Attachment not found", http.StatusNotFound)
+			log.Printf("Attachment not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get attachment", http.StatusInternalServerError)
+		log.Printf("Failed to get attachment: %v\n", err)
+		return
+	}
+
+	if err := h.AttachmentRepo.DeleteAttachment(attachment.ID); err != nil {
+		http.Error(w, "Failed to delete attachment", http.StatusInternalServerError)
+		log.Printf("Failed to delete attachment: %v\n", err)
+		return
+	}
+	h.MediaService.DeleteAttachment(attachment.StorageKey)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Attachment deleted successfully",
+	})
+	log.Printf("Attachment %d removed from event %d by user %d\n", attachment.ID, event.ID, userID)
+}
+
+// readUpload reads the file sent in the "file" field of a multipart form. It writes
+// a 413 response if the file is larger than maxSize, or a 400 response if there is
+// no file, and returns false in either case.
+func readUpload(w http.ResponseWriter, r *http.Request, maxSize int64) (string, []byte, bool) {
+	// Leave some room for the rest of the multipart body
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("File is too large. The limit is %d MB", maxSize>>20), http.StatusRequestEntityTooLarge)
+			log.Printf("Upload too large: %v\n", err)
+			return "", nil, false
+		}
+		http.Error(w, "A file is required in the 'file' form field", http.StatusBadRequest)
+		log.Printf("Invalid upload: %v\n", err)
+		return "", nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		http.Error(w, "Failed to read upload", http.StatusBadRequest)
+		log.Printf("Failed to read upload: %v\n", err)
+		return "", nil, false
+	}
+	if int64(len(data)) > maxSize {
+		http.Error(w, fmt.Sprintf("File is too large. The limit is %d MB", maxSize>>20), http.StatusRequestEntityTooLarge)
+		log.Printf("Upload too large: %d bytes\n", len(data))
+		return "", nil, false
+	}
+	if len(data) == 0 {
+		http.Error(w, "The uploaded file is empty", http.StatusBadRequest)
+		log.Println("Empty upload")
+		return "", nil, false
+	}
+
+	return header.Filename, data, true
+}
+
+// writeMediaError writes the response for an error returned when saving media
+func writeMediaError(w http.ResponseWriter, err error, unsupportedMessage string) {
+	switch {
+	case errors.Is(err, services.ErrUnsupportedMediaType):
+		http.Error(w, unsupportedMessage, http.StatusUnsupportedMediaType)
+	case errors.Is(err, services.ErrImageTooLarge):
+		http.Error(w, "Image dimensions are too large", http.StatusBadRequest)
+	default:
+		http.Error(w, "Failed to store file", http.StatusInternalServerError)
+	}
+	log.Printf("Failed to save media: %v\n", err)
+}
+
+// withAttachmentURLs fills in the download URLs of attachments
+func withAttachmentURLs(mediaService *services.MediaService, attachments []models.EventAttachment) {
+	for i := range attachments {
+		attachments[i].URL = mediaService.URL(attachments[i].StorageKey)
+	}
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// MemberHandler handles HTTP requests for the team running an event
+type MemberHandler struct {
+	MemberRepo    *repositories.MemberRepository
+	EventRepo     *repositories.EventRepository
+	UserRepo      *repositories.UserRepository
+	EmailService  *services.EmailService
+	AccessService *services.AccessService
+}
+
+func NewMemberHandler(
+	memberRepo *repositories.MemberRepository,
+	eventRepo *repositories.EventRepository,
+	userRepo *repositories.UserRepository,
+	emailService *services.EmailService,
+	accessService *services.AccessService,
+) *MemberHandler {
+	return &MemberHandler{
+		MemberRepo:    memberRepo,
+		EventRepo:     eventRepo,
+		UserRepo:      userRepo,
+		EmailService:  emailService,
+		AccessService: accessService,
+	}
+}
+
+// GetMembers handles listing the owner and members of an event
+func (h *MemberHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionViewAttendees) {
+		return
+	}
+
+	members, err := h.MemberRepo.GetMembers(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to get members", http.StatusInternalServerError)
+		log.Printf("Failed to get members: %v\n", err)
+		return
+	}
+
+	// The owner is not stored as a member, so list them first
+	owner, err := h.UserRepo.GetUserByID(event.UserID)
+	if err != nil {
+		http.Error(w, "Failed to get members", http.StatusInternalServerError)
+		log.Printf("Failed to get event owner: %v\n", err)
+		return
+	}
+	members = append([]models.EventMember{{
+		EventID:   event.ID,
+		UserID:    owner.ID,
+		Role:      models.RoleOwner,
+		FirstName: owner.FirstName,
+		LastName:  owner.LastName,
+		Email:     owner.Email,
+		CreatedAt: event.CreatedAt,
+		UpdatedAt: event.CreatedAt,
+	}}, members...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// AddMember handles adding a user to an event by email, or changing their role
+func (h *MemberHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionManageMembers) {
+		return
+	}
+
+	var req models.EventMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	if !services.IsValidMemberRole(req.Role) {
+		http.Error(w, "Invalid role. Must be 'co_organizer', 'checkin_staff', or 'viewer'", http.StatusBadRequest)
+		log.Printf("Invalid member role: %s\n", req.Role)
+		return
+	}
+
+	member, err := h.UserRepo.GetUserByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "No user found with that email. They need to sign up first", http.StatusNotFound)
+			log.Printf("Member not found: %s\n", req.Email)
+			return
+		}
+		http.Error(w, "Failed to add member", http.StatusInternalServerError)
+		log.Printf("Failed to get user by email: %v\n", err)
+		return
+	}
+
+	if member.ID == event.UserID {
+		http.Error(w, "The event owner cannot be added as a member", http.StatusBadRequest)
+		log.Printf("User %d attempted to add owner %d as a member of event %d\n", userID, member.ID, event.ID)
+		return
+	}
+
+	// Both the new role and the one it replaces must be below the user's own
+	currentRole, err := h.MemberRepo.GetMemberRole(event.ID, member.ID)
+	if err != nil {
+		http.Error(w, "Failed to add member", http.StatusInternalServerError)
+		log.Printf("Failed to get member role: %v\n", err)
+		return
+	}
+	if !authorizeRole(w, h.AccessService, event, userID, req.Role) ||
+		!authorizeRole(w, h.AccessService, event, userID, currentRole) {
+		return
+	}
+
+	if err := h.MemberRepo.AddMember(event.ID, member.ID, req.Role, userID); err != nil {
+		http.Error(w, "Failed to add member", http.StatusInternalServerError)
+		log.Printf("Failed to add member: %v\n", err)
+		return
+	}
+
+	// Let the new member know
+	if inviter, err := h.UserRepo.GetUserByID(userID); err == nil {
+		go func() {
+			if err := h.EmailService.SendMemberInvitation(emailEvent(event, event.Date, ""), member, inviter, req.Role); err != nil {
+				log.Printf("Failed to send member invitation: %v\n", err)
+			}
+		}()
+	} else {
+		log.Printf("Failed to get inviter for member invitation: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Member added successfully",
+		"user_id": member.ID,
+		"role":    req.Role,
+	})
+	log.Printf("User %d added user %d to event %d as %s\n", userID, member.ID, event.ID, req.Role)
+}
+
+// RemoveMember handles removing a member from an event. Members may also remove themselves.
+func (h *MemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	memberID, err := getPathID(r, "members")
+	if err != nil {
+		http.Error(w, "Invalid member ID", http.StatusBadRequest)
+		log.Printf("Invalid member ID: %v\n", err)
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if memberID != userID {
+		if !authorize(w, h.AccessService, event, userID, services.PermissionManageMembers) {
+			return
+		}
+
+		role, err := h.MemberRepo.GetMemberRole(event.ID, memberID)
+		if err != nil {
+			http.Error(w, "Failed to remove member", http.StatusInternalServerError)
+			log.Printf("Failed to get member role: %v\n", err)
+			return
+		}
+		if !authorizeRole(w, h.AccessService, event, userID, role) {
+			return
+		}
+	}
+
+	if err := h.MemberRepo.RemoveMember(event.ID, memberID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Member not found", http.StatusNotFound)
+			log.Printf("User %d is not a member of event %d\n", memberID, event.ID)
+			return
+		}
+		http.Error(w, "Failed to remove member", http.StatusInternalServerError)
+		log.Printf("Failed to remove member: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Member removed successfully",
+	})
+	log.Printf("User %d removed user %d from event %d\n", userID, memberID, event.ID)
+}
+
+// Helper function to check that a user may manage members holding a role.
+// It writes the error response and returns false if they may not.
+func authorizeRole(w http.ResponseWriter, accessService *services.AccessService, event *models.EventWithOrganizer, userID int, role string) bool {
+	allowed, err := accessService.CanManageRole(event, userID, role)
+	if err != nil {
+		http.Error(w, "Failed to check permissions", http.StatusInternalServerError)
+		log.Printf("Failed to check permissions: %v\n", err)
+		return false
+	}
+
+	if !allowed {
+		http.Error(w, "Forbidden: You can only manage members with a role below your own", http.StatusForbidden)
+		log.Printf("Forbidden: User %d cannot manage the %s role on event %d\n", userID, role, event.ID)
+		return false
+	}
+
+	return true
+}
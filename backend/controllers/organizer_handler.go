@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// OrganizerHandler handles HTTP requests for the public profiles of organizers
+type OrganizerHandler struct {
+	UserRepo   *repositories.UserRepository
+	SurveyRepo *repositories.SurveyRepository
+}
+
+func NewOrganizerHandler(userRepo *repositories.UserRepository, surveyRepo *repositories.SurveyRepository) *OrganizerHandler {
+	return &OrganizerHandler{
+		UserRepo:   userRepo,
+		SurveyRepo: surveyRepo,
+	}
+}
+
+// GetOrganizer handles getting an organizer's profile with the average rating
+// attendees gave their events
+func (h *OrganizerHandler) GetOrganizer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	organizerID, err := getPathID(r, "organizers")
+	if err != nil {
+		http.Error(w, "Invalid organizer ID", http.StatusBadRequest)
+		log.Printf("Invalid organizer ID: %v\n", err)
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(organizerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Organizer not found", http.StatusNotFound)
+			log.Printf("Organizer not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get organizer", http.StatusInternalServerError)
+		log.Printf("Failed to get organizer: %v\n", err)
+		return
+	}
+
+	average, count, err := h.SurveyRepo.GetOrganizerRating(organizerID)
+	if err != nil {
+		http.Error(w, "Failed to get organizer", http.StatusInternalServerError)
+		log.Printf("Failed to get organizer rating: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.OrganizerProfile{
+		ID:            user.ID,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		AverageRating: average,
+		RatingCount:   count,
+	})
+}
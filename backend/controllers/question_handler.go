@@ -0,0 +1,383 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// Limits on an event's registration and survey questions and the answers to them
+const (
+	maxEventQuestions     = 20
+	maxQuestionOptions    = 50
+	maxQuestionTextLength = 255 // matches the size of the label column, also used for options
+	maxAnswerLength       = 1000
+)
+
+// QuestionHandler handles HTTP requests for the questions an event asks
+// attendees when they RSVP, or in its post-event survey when given a survey
+// question repository
+type QuestionHandler struct {
+	QuestionRepo  *repositories.QuestionRepository
+	EventRepo     *repositories.EventRepository
+	AccessService *services.AccessService
+}
+
+func NewQuestionHandler(
+	questionRepo *repositories.QuestionRepository,
+	eventRepo *repositories.EventRepository,
+	accessService *services.AccessService,
+) *QuestionHandler {
+	return &QuestionHandler{
+		QuestionRepo:  questionRepo,
+		EventRepo:     eventRepo,
+		AccessService: accessService,
+	}
+}
+
+// GetQuestions handles listing an event's questions
+func (h *QuestionHandler) GetQuestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	questions, err := h.QuestionRepo.GetQuestions(eventID)
+	if err != nil {
+		http.Error(w, "Failed to get questions", http.StatusInternalServerError)
+		log.Printf("Failed to get questions: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(questions)
+}
+
+// CreateQuestion handles adding a question to an event
+func (h *QuestionHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	questions, err := h.QuestionRepo.GetQuestions(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to create question", http.StatusInternalServerError)
+		log.Printf("Failed to get questions: %v\n", err)
+		return
+	}
+	if len(questions) >= maxEventQuestions {
+		http.Error(w, fmt.Sprintf("An event can have at most %d questions", maxEventQuestions), http.StatusConflict)
+		log.Printf("Event %d has too many questions\n", event.ID)
+		return
+	}
+
+	req, ok := decodeQuestionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := h.QuestionRepo.CreateQuestion(event.ID, req)
+	if err != nil {
+		http.Error(w, "Failed to create question", http.StatusInternalServerError)
+		log.Printf("Failed to create question: %v\n", err)
+		return
+	}
+
+	question, err := h.QuestionRepo.GetQuestionByID(event.ID, id)
+	if err != nil {
+		http.Error(w, "Failed to get question", http.StatusInternalServerError)
+		log.Printf("Failed to get question: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(question)
+	log.Printf("Question %d added to event %d by user %d\n", id, event.ID, userID)
+}
+
+// UpdateQuestion handles changing a question
+func (h *QuestionHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, question, ok := h.getQuestion(w, r)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	req, ok := decodeQuestionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.QuestionRepo.UpdateQuestion(question.ID, req); err != nil {
+		http.Error(w, "Failed to update question", http.StatusInternalServerError)
+		log.Printf("Failed to update question: %v\n", err)
+		return
+	}
+
+	updated, err := h.QuestionRepo.GetQuestionByID(event.ID, question.ID)
+	if err != nil {
+		http.Error(w, "Failed to get question", http.StatusInternalServerError)
+		log.Printf("Failed to get question: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	log.Printf("Question %d of event %d updated by user %d\n", question.ID, event.ID, userID)
+}
+
+// DeleteQuestion handles removing a question along with its answers
+func (h *QuestionHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, question, ok := h.getQuestion(w, r)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	if err := h.QuestionRepo.DeleteQuestion(question.ID); err != nil {
+		http.Error(w, "Failed to delete question", http.StatusInternalServerError)
+		log.Printf("Failed to delete question: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Question deleted successfully",
+	})
+	log.Printf("Question %d of event %d deleted by user %d\n", question.ID, event.ID, userID)
+}
+
+// getQuestion authenticates the request and loads the event and question from the URL
+func (h *QuestionHandler) getQuestion(w http.ResponseWriter, r *http.Request) (int, *models.EventWithOrganizer, *models.EventQuestion, bool) {
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return 0, nil, nil, false
+	}
+
+	questionID, err := getPathID(r, "questions")
+	if err != nil {
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		log.Printf("Invalid question ID: %v\n", err)
+		return 0, nil, nil, false
+	}
+
+	question, err := h.QuestionRepo.GetQuestionByID(event.ID, questionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Question not found", http.StatusNotFound)
+			log.Printf("Question not found: %v\n", err)
+			return 0, nil, nil, false
+		}
+		http.Error(w, "Failed to get question", http.StatusInternalServerError)
+		log.Printf("Failed to get question: %v\n", err)
+		return 0, nil, nil, false
+	}
+
+	return userID, event, question, true
+}
+
+// decodeQuestionRequest reads and validates a question create or update request.
+// It writes a 400 response and returns false if the request is invalid.
+func decodeQuestionRequest(w http.ResponseWriter, r *http.Request) (models.QuestionRequest, bool) {
+	var req models.QuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return req, false
+	}
+
+	if err := validateQuestionRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid question: %v\n", err)
+		return req, false
+	}
+
+	return req, true
+}
+
+// validateQuestionRequest checks and normalizes a question create or update request
+func validateQuestionRequest(req *models.QuestionRequest) error {
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Label == "" || len(req.Label) > maxQuestionTextLength {
+		return fmt.Errorf("Label is required and can be at most %d characters", maxQuestionTextLength)
+	}
+
+	if req.Position != nil && *req.Position < 0 {
+		return errors.New("Position cannot be negative")
+	}
+
+	switch req.Type {
+	case models.QuestionTypeText:
+		if len(req.Options) > 0 {
+			return errors.New("Text questions cannot have options")
+		}
+		req.Options = []string{}
+		return nil
+	case models.QuestionTypeSingleChoice, models.QuestionTypeMultipleChoice:
+	default:
+		return errors.New("Invalid type. Must be 'text', 'single_choice' or 'multiple_choice'")
+	}
+
+	options := []string{}
+	seen := map[string]bool{}
+	for _, option := range req.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		if len(option) > maxQuestionTextLength {
+			return fmt.Errorf("Option %q is too long. Options can be at most %d characters", option, maxQuestionTextLength)
+		}
+		if seen[option] {
+			return fmt.Errorf("Option %q is listed more than once", option)
+		}
+		seen[option] = true
+		options = append(options, option)
+	}
+	if len(options) == 0 || len(options) > maxQuestionOptions {
+		return fmt.Errorf("Choice questions need between 1 and %d options", maxQuestionOptions)
+	}
+	req.Options = options
+
+	return nil
+}
+
+// validateAnswers checks answers to an event's questions and returns
+// them trimmed, leaving out empty ones. Every required question must be answered
+// when requireAnswers is set.
+func validateAnswers(questions []models.EventQuestion, answers []models.RSVPAnswer, requireAnswers bool) ([]models.RSVPAnswer, error) {
+	byID := map[int]*models.EventQuestion{}
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+
+	valid := []models.RSVPAnswer{}
+	answered := map[int]bool{}
+	for _, answer := range answers {
+		question, ok := byID[answer.QuestionID]
+		if !ok {
+			return nil, fmt.Errorf("Question %d is not asked by this event", answer.QuestionID)
+		}
+		if answered[question.ID] {
+			return nil, fmt.Errorf("Question %q is answered more than once", question.Label)
+		}
+
+		if question.Type == models.QuestionTypeText {
+			if len(answer.Choices) > 0 {
+				return nil, fmt.Errorf("Question %q takes a text answer", question.Label)
+			}
+			answer.Text = strings.TrimSpace(answer.Text)
+			if len(answer.Text) > maxAnswerLength {
+				return nil, fmt.Errorf("The answer to %q can be at most %d characters", question.Label, maxAnswerLength)
+			}
+			if answer.Text == "" {
+				continue
+			}
+		} else {
+			if answer.Text != "" {
+				return nil, fmt.Errorf("Question %q must be answered with its options", question.Label)
+			}
+			if len(answer.Choices) == 0 {
+				continue
+			}
+			if question.Type == models.QuestionTypeSingleChoice && len(answer.Choices) > 1 {
+				return nil, fmt.Errorf("Choose a single option for %q", question.Label)
+			}
+			chosen := map[string]bool{}
+			for _, choice := range answer.Choices {
+				if !containsString(question.Options, choice) {
+					return nil, fmt.Errorf("%q is not an option for %q", choice, question.Label)
+				}
+				if chosen[choice] {
+					return nil, fmt.Errorf("%q is chosen more than once for %q", choice, question.Label)
+				}
+				chosen[choice] = true
+			}
+		}
+
+		answered[question.ID] = true
+		valid = append(valid, answer)
+	}
+
+	if requireAnswers {
+		for _, question := range questions {
+			if question.Required && !answered[question.ID] {
+				return nil, fmt.Errorf("Question %q is required", question.Label)
+			}
+		}
+	}
+
+	return valid, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// RevisionHandler handles HTTP requests for the change history of events
+type RevisionHandler struct {
+	RevisionRepo    *repositories.RevisionRepository
+	EventRepo       *repositories.EventRepository
+	VenueRepo       *repositories.VenueRepository
+	AccessService   *services.AccessService
+	WaitlistService *services.WaitlistService
+}
+
+func NewRevisionHandler(
+	revisionRepo *repositories.RevisionRepository,
+	eventRepo *repositories.EventRepository,
+	venueRepo *repositories.VenueRepository,
+	accessService *services.AccessService,
+	waitlistService *services.WaitlistService,
+) *RevisionHandler {
+	return &RevisionHandler{
+		RevisionRepo:    revisionRepo,
+		EventRepo:       eventRepo,
+		VenueRepo:       venueRepo,
+		AccessService:   accessService,
+		WaitlistService: waitlistService,
+	}
+}
+
+// GetRevisions handles listing a page of an event's revisions, newest first
+func (h *RevisionHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, next, err := h.RevisionRepo.GetRevisions(event.ID, limit, cursor)
+	if err != nil {
+		writeListError(w, err, "Failed to get revisions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Page[models.EventRevision]{
+		Items:      revisions,
+		NextCursor: repositories.EncodeCursor(next),
+	})
+}
+
+// RestoreRevision handles the owner bringing an event back to the details it had
+// in one of its revisions. The restore is recorded as a new revision.
+func (h *RevisionHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionRestoreRevision) {
+		return
+	}
+
+	if event.Status == models.EventStatusCancelled {
+		http.Error(w, "Cancelled events cannot be edited", http.StatusConflict)
+		log.Printf("Event %d is cancelled\n", event.ID)
+		return
+	}
+
+	// If-Match is optional, but honored so a restore doesn't undo an edit the
+	// owner hasn't seen
+	version, ok := checkIfMatch(w, r, event, false)
+	if !ok {
+		return
+	}
+
+	number, err := getPathID(r, "revisions")
+	if err != nil {
+		http.Error(w, "Invalid revision number", http.StatusBadRequest)
+		log.Printf("Invalid revision number: %v\n", err)
+		return
+	}
+
+	// The body is optional and only needed to allow venue conflicts
+	var options models.RestoreRevisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	revision, err := h.RevisionRepo.GetRevision(event.ID, number)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			log.Printf("Revision %d of event %d not found\n", number, event.ID)
+			return
+		}
+		http.Error(w, "Failed to get revision", http.StatusInternalServerError)
+		log.Printf("Failed to get revision: %v\n", err)
+		return
+	}
+
+	// The old details are checked like any update, since the venue or its
+	// bookings may have changed since
+	req := revision.Snapshot.Request()
+	req.AllowConflicts = options.AllowConflicts
+	if !applyVenue(w, h.VenueRepo, &req) {
+		return
+	}
+
+	if err := validateEventRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid revision: %v\n", err)
+		return
+	}
+
+	conflicts, ok := checkVenueConflicts(w, h.EventRepo, req, event.ID, userID)
+	if !ok {
+		return
+	}
+
+	version, restored, promotions, err := h.EventRepo.RestoreRevision(event.ID, userID, number, version, req)
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionMismatch) {
+			writeVersionMismatch(w, event.ID)
+			return
+		}
+		if writeVenueConflictError(w, err, userID) {
+			return
+		}
+		if errors.Is(err, repositories.ErrScheduleLocked) {
+			http.Error(w, "The new schedule would drop or move occurrences that have RSVPs or were cancelled or rescheduled", http.StatusConflict)
+			log.Printf("Event %d schedule change would strand RSVPs or exceptions\n", event.ID)
+			return
+		}
+		http.Error(w, "Failed to restore revision", http.StatusInternalServerError)
+		log.Printf("Failed to restore revision: %v\n", err)
+		return
+	}
+
+	notifyPromotions(h.EventRepo, h.WaitlistService, event.ID, promotions)
+
+	response := map[string]interface{}{
+		"message": "Event restored successfully",
+	}
+	if restored > 0 {
+		response["revision"] = restored
+	}
+	if len(conflicts) > 0 {
+		response["conflicts"] = conflicts
+	}
+	w.Header().Set("ETag", eventETag(version))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	log.Printf("Event %d restored to revision %d by user %d\n", event.ID, number, userID)
+}
@@ -2,36 +2,68 @@ package controllers
 
 import (
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/johneliud/evently/backend/models"
 	"github.com/johneliud/evently/backend/repositories"
 	"github.com/johneliud/evently/backend/services"
 )
 
+// Limits on the guests attendees bring along
+const (
+	maxGuestsPerRSVP   = 20
+	maxGuestNameLength = 100
+)
+
+// maxReviewedRSVPs limits how many pending RSVPs can be approved or declined at once
+const maxReviewedRSVPs = 100
+
 // RSVPHandler handles RSVP-related HTTP requests
 type RSVPHandler struct {
-	RSVPRepo     *repositories.RSVPRepository
-	EventRepo    *repositories.EventRepository
-	UserRepo     *repositories.UserRepository
-	EmailService *services.EmailService
+	RSVPRepo        *repositories.RSVPRepository
+	EventRepo       *repositories.EventRepository
+	UserRepo        *repositories.UserRepository
+	QuestionRepo    *repositories.QuestionRepository
+	AnswerRepo      *repositories.AnswerRepository
+	OverrideRepo    *repositories.RSVPOverrideRepository
+	EmailService    *services.EmailService
+	TokenService    *services.TokenService
+	AccessService   *services.AccessService
+	WaitlistService *services.WaitlistService
 }
 
 func NewRSVPHandler(
 	rsvpRepo *repositories.RSVPRepository,
 	eventRepo *repositories.EventRepository,
 	userRepo *repositories.UserRepository,
+	questionRepo *repositories.QuestionRepository,
+	answerRepo *repositories.AnswerRepository,
+	overrideRepo *repositories.RSVPOverrideRepository,
 	emailService *services.EmailService,
+	tokenService *services.TokenService,
+	accessService *services.AccessService,
+	waitlistService *services.WaitlistService,
 ) *RSVPHandler {
 	return &RSVPHandler{
-		RSVPRepo:     rsvpRepo,
-		EventRepo:    eventRepo,
-		UserRepo:     userRepo,
-		EmailService: emailService,
+		RSVPRepo:        rsvpRepo,
+		EventRepo:       eventRepo,
+		UserRepo:        userRepo,
+		QuestionRepo:    questionRepo,
+		AnswerRepo:      answerRepo,
+		OverrideRepo:    overrideRepo,
+		EmailService:    emailService,
+		TokenService:    tokenService,
+		AccessService:   accessService,
+		WaitlistService: waitlistService,
 	}
 }
 
@@ -76,6 +108,25 @@ func (h *RSVPHandler) CreateOrUpdateRSVP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Private events require access before anyone can RSVP
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	if !acceptsRSVPs(w, event) {
+		return
+	}
+
+	// RSVPs to recurring events are per occurrence
+	occurrence, occurrenceStart, ok := h.resolveOccurrence(w, r, event)
+	if !ok {
+		return
+	}
+
+	if !h.withinRSVPWindow(w, event, occurrenceStart, userID) {
+		return
+	}
+
 	// Get user details for email
 	user, err := h.UserRepo.GetUserByID(userID)
 	if err != nil {
@@ -99,49 +150,97 @@ func (h *RSVPHandler) CreateOrUpdateRSVP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Attendees who aren't going don't bring anyone along
+	if req.Status == "not_going" {
+		noGuests := 0
+		req.Guests, req.GuestNames = &noGuests, nil
+	} else if err := validateGuests(&req, event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid guests: %v\n", err)
+		return
+	}
+
 	// Get previous RSVP status to check if this is a new RSVP or an update
-	previousRSVP, err := h.RSVPRepo.GetRSVPByEventAndUser(eventID, userID)
+	previousRSVP, err := h.RSVPRepo.GetRSVPByEventAndUser(eventID, userID, occurrence)
 	if err != nil {
+		http.Error(w, "Failed to get RSVP", http.StatusInternalServerError)
 		log.Printf("Error getting previous RSVP: %v\n", err)
 		return
 	}
 	isNewRSVP := previousRSVP == nil
 
-	// Create or update RSVP
-	err = h.RSVPRepo.CreateOrUpdateRSVP(eventID, userID, req.Status)
+	// Check the answers to the event's registration questions. Attendees who are
+	// going must answer the required ones, and earlier answers are kept when the
+	// request leaves them out.
+	questions, err := h.QuestionRepo.GetQuestions(eventID)
+	if err != nil {
+		http.Error(w, "Failed to get questions", http.StatusInternalServerError)
+		log.Printf("Error getting questions: %v\n", err)
+		return
+	}
+	if req.Answers != nil || req.Status == "going" {
+		answers := req.Answers
+		if answers == nil && previousRSVP != nil && len(questions) > 0 {
+			stored, err := h.AnswerRepo.GetAnswers([]int{previousRSVP.ID})
+			if err != nil {
+				http.Error(w, "Failed to get answers", http.StatusInternalServerError)
+				log.Printf("Error getting previous answers: %v\n", err)
+				return
+			}
+			answers = stored[previousRSVP.ID]
+		}
+
+		answers, err = validateAnswers(questions, answers, req.Status == "going")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			log.Printf("Invalid answers: %v\n", err)
+			return
+		}
+		if req.Answers != nil {
+			req.Answers = answers
+		}
+	}
+
+	// Create or update RSVP; "going" becomes "pending" if the event requires approval,
+	// or "waitlisted" if the event is full
+	status, promoted, err := h.RSVPRepo.CreateOrUpdateRSVP(eventID, userID, occurrence, req)
+	if err == repositories.ErrNotEnoughSeats {
+		http.Error(w, "There are not enough seats left for your guests", http.StatusConflict)
+		log.Printf("Not enough seats for the guests of user %d at event %d\n", userID, eventID)
+		return
+	}
+	if err == repositories.ErrRSVPDeclined {
+		http.Error(w, "Your request to attend this event was declined", http.StatusForbidden)
+		log.Printf("Declined user %d tried to change their RSVP to event %d\n", userID, eventID)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to create/update RSVP", http.StatusInternalServerError)
 		log.Printf("Failed to create/update RSVP: %v\n", err)
 		return
 	}
 
+	// Attendees who are going get a ticket to check in with
+	ticketCode := ""
+	if status == "going" {
+		ticketCode = h.WaitlistService.TicketCode(eventID, userID, occurrence)
+	}
+
 	// Get event organizer details
 	eventOrganizer, err := h.UserRepo.GetUserByID(event.UserID)
 	if err != nil {
 		log.Printf("Warning: Could not get event organizer details: %v\n", err)
 	} else {
+		// Convert to the expected type
+		eventModel := emailEvent(event, occurrenceStart, eventOrganizer.Email)
+
 		// Send email notifications
-		if isNewRSVP || (previousRSVP != nil && previousRSVP.Status != req.Status) {
+		if isNewRSVP || (previousRSVP != nil && previousRSVP.Status != status) {
 			// Only send emails if this is a new RSVP or the status has changed
 			// Send notification to organizer
-			if eventOrganizer != nil && eventOrganizer.Email != "" {
+			if eventOrganizer.Email != "" {
 				go func() {
-					// Convert to the expected type
-					eventModel := &models.Event{
-						ID:                 event.ID,
-						Title:              event.Title,
-						Description:        event.Description,
-						Date:               event.Date,
-						Location:           event.Location,
-						UserID:             event.UserID,
-						CreatedAt:          event.CreatedAt,
-						UpdatedAt:          event.UpdatedAt,
-						OrganizerEmail:     eventOrganizer.Email,
-						OrganizerFirstName: event.OrganizerFirstName,
-						OrganizerLastName:  event.OrganizerLastName,
-					}
-
-					err := h.EmailService.SendRSVPNotificationToOrganizer(eventModel, user, req.Status)
+					err := h.EmailService.SendRSVPNotificationToOrganizer(eventModel, user, status)
 					if err != nil {
 						log.Printf("Error sending organizer notification: %v\n", err)
 					}
@@ -151,37 +250,29 @@ func (h *RSVPHandler) CreateOrUpdateRSVP(w http.ResponseWriter, r *http.Request)
 			// Send confirmation to user
 			if user.Email != "" {
 				go func() {
-					// Convert to the expected type
-					eventModel := &models.Event{
-						ID:                 event.ID,
-						Title:              event.Title,
-						Description:        event.Description,
-						Date:               event.Date,
-						Location:           event.Location,
-						UserID:             event.UserID,
-						CreatedAt:          event.CreatedAt,
-						UpdatedAt:          event.UpdatedAt,
-						OrganizerEmail:     eventOrganizer.Email,
-						OrganizerFirstName: event.OrganizerFirstName,
-						OrganizerLastName:  event.OrganizerLastName,
-					}
-
-					err := h.EmailService.SendRSVPConfirmationToUser(eventModel, user, req.Status)
+					err := h.EmailService.SendRSVPConfirmationToUser(eventModel, user, status, ticketCode)
 					if err != nil {
 						log.Printf("Error sending user confirmation: %v\n", err)
 					}
 				}()
 			}
 		}
+
+		h.WaitlistService.NotifyPromoted(eventModel, occurrence, promoted)
 	}
 
 	// Return success response
+	response := map[string]string{
+		"message": "RSVP updated successfully",
+		"status":  status,
+	}
+	if ticketCode != "" {
+		response["ticket_code"] = ticketCode
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "RSVP updated successfully",
-	})
-	log.Printf("RSVP updated successfully for event %d by user %d with status %s\n", eventID, userID, req.Status)
+	json.NewEncoder(w).Encode(response)
+	log.Printf("RSVP updated successfully for event %d by user %d with status %s\n", eventID, userID, status)
 }
 
 // GetRSVP handles retrieving a user's RSVP for an event
@@ -217,14 +308,44 @@ func (h *RSVPHandler) GetRSVP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		log.Printf("Event not found: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	occurrence, _, ok := h.resolveOccurrence(w, r, event)
+	if !ok {
+		return
+	}
+
 	// Get RSVP
-	rsvp, err := h.RSVPRepo.GetRSVPByEventAndUser(eventID, userID)
+	rsvp, err := h.RSVPRepo.GetRSVPByEventAndUser(eventID, userID, occurrence)
 	if err != nil {
 		http.Error(w, "Failed to get RSVP", http.StatusInternalServerError)
 		log.Printf("Failed to get RSVP: %v\n", err)
 		return
 	}
 
+	if rsvp != nil {
+		if rsvp.Status == "going" {
+			rsvp.TicketCode = h.TokenService.SignTicket(rsvp.EventID, rsvp.ID)
+		}
+
+		answers, err := h.AnswerRepo.GetAnswers([]int{rsvp.ID})
+		if err != nil {
+			http.Error(w, "Failed to get RSVP", http.StatusInternalServerError)
+			log.Printf("Failed to get RSVP answers: %v\n", err)
+			return
+		}
+		rsvp.Answers = answers[rsvp.ID]
+	}
+
 	// Return RSVP (or null if not found)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(rsvp)
@@ -263,14 +384,56 @@ func (h *RSVPHandler) DeleteRSVP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete RSVP
-	err = h.RSVPRepo.DeleteRSVP(eventID, userID)
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		log.Printf("Event not found: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	occurrence, err := getOccurrenceFromQuery(r)
+	if err != nil {
+		http.Error(w, "Invalid occurrence. Use RFC 3339 format", http.StatusBadRequest)
+		log.Printf("Invalid occurrence: %v\n", err)
+		return
+	}
+
+	// Attendees can still withdraw from a cancelled occurrence, though nobody is
+	// promoted into a seat there
+	occurrenceStart, err := h.EventRepo.ResolveOccurrence(event, occurrence)
+	cancelled := err == repositories.ErrOccurrenceCancelled
+	if err != nil && !cancelled {
+		writeOccurrenceError(w, err)
+		return
+	}
+	if event.RecurrenceRule == "" {
+		occurrence = nil
+	}
+
+	if !cancelled && !h.withinRSVPWindow(w, event, occurrenceStart, userID) {
+		return
+	}
+
+	// Delete RSVP, promoting anyone waitlisted into the freed seat
+	promoted, err := h.RSVPRepo.DeleteRSVP(eventID, userID, occurrence, !cancelled)
 	if err != nil {
 		http.Error(w, "Failed to delete RSVP", http.StatusInternalServerError)
 		log.Printf("Failed to delete RSVP: %v\n", err)
 		return
 	}
 
+	if len(promoted) > 0 {
+		organizerEmail := ""
+		if organizer, err := h.UserRepo.GetUserByID(event.UserID); err == nil {
+			organizerEmail = organizer.Email
+		}
+		h.WaitlistService.NotifyPromoted(emailEvent(event, occurrenceStart, organizerEmail), occurrence, promoted)
+	}
+
 	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -304,8 +467,24 @@ func (h *RSVPHandler) GetRSVPCount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		log.Printf("Event not found: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	occurrence, _, ok := h.resolveOccurrence(w, r, event)
+	if !ok {
+		return
+	}
+
 	// Get RSVP count
-	count, err := h.RSVPRepo.GetRSVPCount(eventID)
+	count, err := h.RSVPRepo.GetRSVPCount(eventID, occurrence)
 	if err != nil {
 		http.Error(w, "Failed to get RSVP count", http.StatusInternalServerError)
 		log.Printf("Failed to get RSVP count: %v\n", err)
@@ -363,23 +542,473 @@ func (h *RSVPHandler) GetRSVPs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only the event creator can see the full list of RSVPs
-	if event.UserID != userID {
-		http.Error(w, "Unauthorized. Only the event creator can view the attendee list", http.StatusForbidden)
-		log.Printf("Unauthorized access to RSVPs: User %d tried to access RSVPs for event %d created by user %d\n", userID, eventID, event.UserID)
+	// Only the event's organizers and staff can see the full list of RSVPs
+	if !authorize(w, h.AccessService, event, userID, services.PermissionViewAttendees) {
+		return
+	}
+
+	// Optionally limit the list to a single occurrence
+	occurrence, err := getOccurrenceFromQuery(r)
+	if err != nil {
+		http.Error(w, "Invalid occurrence. Use RFC 3339 format", http.StatusBadRequest)
+		log.Printf("Invalid occurrence: %v\n", err)
+		return
+	}
+
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
 		return
 	}
 
 	// Get RSVPs
-	rsvps, err := h.RSVPRepo.GetRSVPs(eventID)
+	rsvps, next, err := h.RSVPRepo.GetRSVPs(eventID, occurrence, limit, cursor)
 	if err != nil {
+		writeListError(w, err, "Failed to get RSVPs")
+		return
+	}
+
+	if err := h.withAnswers(rsvps); err != nil {
 		http.Error(w, "Failed to get RSVPs", http.StatusInternalServerError)
-		log.Printf("Failed to get RSVPs: %v\n", err)
+		log.Printf("Failed to get RSVP answers: %v\n", err)
 		return
 	}
 
 	// Return RSVPs
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(rsvps)
+	json.NewEncoder(w).Encode(models.Page[models.RSVPWithUser]{
+		Items:      rsvps,
+		NextCursor: repositories.EncodeCursor(next),
+	})
 	log.Printf("RSVPs retrieved successfully for event %d by creator %d\n", eventID, userID)
 }
+
+// ApproveRSVPs handles approving pending RSVPs, either the one in the URL or
+// those listed in the request body
+func (h *RSVPHandler) ApproveRSVPs(w http.ResponseWriter, r *http.Request) {
+	h.reviewRSVPs(w, r, true)
+}
+
+// DeclineRSVPs handles declining pending RSVPs, either the one in the URL or
+// those listed in the request body
+func (h *RSVPHandler) DeclineRSVPs(w http.ResponseWriter, r *http.Request) {
+	h.reviewRSVPs(w, r, false)
+}
+
+// reviewRSVPs approves or declines pending RSVPs and emails each attendee the decision
+func (h *RSVPHandler) reviewRSVPs(w http.ResponseWriter, r *http.Request, approve bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionReviewRSVPs) {
+		return
+	}
+
+	// The body is optional when reviewing a single RSVP
+	var req models.RSVPReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+	if rsvpID, err := getPathID(r, "rsvps"); err == nil {
+		req.RSVPIDs = []int{rsvpID}
+	}
+	if len(req.RSVPIDs) == 0 || len(req.RSVPIDs) > maxReviewedRSVPs {
+		http.Error(w, fmt.Sprintf("Give between 1 and %d RSVP IDs", maxReviewedRSVPs), http.StatusBadRequest)
+		log.Printf("Invalid number of RSVPs to review: %d\n", len(req.RSVPIDs))
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+
+	reviewed, err := h.RSVPRepo.ReviewRSVPs(event.ID, req.RSVPIDs, approve)
+	if err != nil {
+		http.Error(w, "Failed to review RSVPs", http.StatusInternalServerError)
+		log.Printf("Failed to review RSVPs: %v\n", err)
+		return
+	}
+
+	// Let each attendee know the decision
+	organizerEmail := ""
+	if organizer, err := h.UserRepo.GetUserByID(event.UserID); err == nil {
+		organizerEmail = organizer.Email
+	}
+	for _, rsvp := range reviewed {
+		attendee, err := h.UserRepo.GetUserByID(rsvp.UserID)
+		if err != nil {
+			log.Printf("Warning: Could not get reviewed user details: %v\n", err)
+			continue
+		}
+		if attendee.Email == "" {
+			continue
+		}
+
+		start, err := h.EventRepo.ResolveOccurrence(event, rsvp.OccurrenceDate)
+		if err != nil {
+			start = event.Date
+		}
+		ticketCode := ""
+		if rsvp.Status == "going" {
+			ticketCode = h.TokenService.SignTicket(event.ID, rsvp.ID)
+		}
+		eventModel := emailEvent(event, start, organizerEmail)
+		status := rsvp.Status
+		go func() {
+			err := h.EmailService.SendRSVPDecision(eventModel, attendee, status, req.Message, ticketCode)
+			if err != nil {
+				log.Printf("Error sending RSVP decision: %v\n", err)
+			}
+		}()
+	}
+
+	decision := "declined"
+	if approve {
+		decision = "approved"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  fmt.Sprintf("%d RSVPs %s", len(reviewed), decision),
+		"reviewed": reviewed,
+		"skipped":  len(req.RSVPIDs) - len(reviewed),
+	})
+	log.Printf("User %d %s %d RSVPs for event %d\n", userID, decision, len(reviewed), event.ID)
+}
+
+// ExportRSVPs handles downloading every RSVP for an event as a CSV file, with a
+// column for each registration question
+func (h *RSVPHandler) ExportRSVPs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionViewAttendees) {
+		return
+	}
+
+	// Optionally limit the export to a single occurrence
+	occurrence, err := getOccurrenceFromQuery(r)
+	if err != nil {
+		http.Error(w, "Invalid occurrence. Use RFC 3339 format", http.StatusBadRequest)
+		log.Printf("Invalid occurrence: %v\n", err)
+		return
+	}
+
+	rsvps, err := h.RSVPRepo.GetAllRSVPs(event.ID, occurrence)
+	if err != nil {
+		http.Error(w, "Failed to export RSVPs", http.StatusInternalServerError)
+		log.Printf("Failed to get RSVPs: %v\n", err)
+		return
+	}
+
+	if err := h.withAnswers(rsvps); err != nil {
+		http.Error(w, "Failed to export RSVPs", http.StatusInternalServerError)
+		log.Printf("Failed to get RSVP answers: %v\n", err)
+		return
+	}
+
+	questions, err := h.QuestionRepo.GetQuestions(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to export RSVPs", http.StatusInternalServerError)
+		log.Printf("Failed to get questions: %v\n", err)
+		return
+	}
+
+	header := []string{"First name", "Last name", "Email", "Status", "Guests", "Guest names", "Occurrence", "RSVP date", "Checked in at"}
+	for _, question := range questions {
+		header = append(header, question.Label)
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d-rsvps.csv"`, event.ID))
+	writer := csv.NewWriter(w)
+	writer.Write(escapeCSVFormulas(header))
+	for _, rsvp := range rsvps {
+		answers := map[int]models.RSVPAnswer{}
+		for _, answer := range rsvp.Answers {
+			answers[answer.QuestionID] = answer
+		}
+
+		record := []string{
+			rsvp.FirstName,
+			rsvp.LastName,
+			rsvp.Email,
+			rsvp.Status,
+			strconv.Itoa(rsvp.Guests),
+			strings.Join(rsvp.GuestNames, "; "),
+			formatOptionalTime(rsvp.OccurrenceDate),
+			rsvp.CreatedAt.Format(time.RFC3339),
+			formatOptionalTime(rsvp.CheckedInAt),
+		}
+		for _, question := range questions {
+			answer := answers[question.ID]
+			if question.Type == models.QuestionTypeText {
+				record = append(record, answer.Text)
+			} else {
+				record = append(record, strings.Join(answer.Choices, "; "))
+			}
+		}
+		writer.Write(escapeCSVFormulas(record))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("Error writing RSVP export: %v\n", err)
+		return
+	}
+	log.Printf("RSVPs of event %d exported by user %d\n", event.ID, userID)
+}
+
+// escapeCSVFormulas prefixes cells that spreadsheets would read as formulas with
+// a quote, since names and answers are typed in by attendees
+func escapeCSVFormulas(record []string) []string {
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+	return record
+}
+
+// validateGuests checks the guests an attendee brings along, if the request sets
+// them, and trims their names
+func validateGuests(req *models.RSVPRequest, event *models.EventWithOrganizer) error {
+	if req.Guests == nil {
+		if len(req.GuestNames) > 0 {
+			return errors.New("Guest names can only be given together with the number of guests")
+		}
+		return nil
+	}
+
+	guests := *req.Guests
+	if guests < 0 {
+		return errors.New("Guests cannot be negative")
+	}
+	if guests > event.MaxGuests {
+		if event.MaxGuests == 0 {
+			return errors.New("This event does not allow guests")
+		}
+		return fmt.Errorf("You can bring at most %d guests", event.MaxGuests)
+	}
+	if event.Capacity != nil && 1+guests > *event.Capacity {
+		return fmt.Errorf("This event only has %d seats", *event.Capacity)
+	}
+
+	names := []string{}
+	for _, name := range req.GuestNames {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if len(name) > maxGuestNameLength {
+			return fmt.Errorf("Guest names can be at most %d characters", maxGuestNameLength)
+		}
+		names = append(names, name)
+	}
+	if len(names) > guests {
+		return errors.New("There are more guest names than guests")
+	}
+	req.GuestNames = names
+
+	return nil
+}
+
+// withAnswers fills in the answers given with each RSVP
+func (h *RSVPHandler) withAnswers(rsvps []models.RSVPWithUser) error {
+	rsvpIDs := make([]int, len(rsvps))
+	for i, rsvp := range rsvps {
+		rsvpIDs[i] = rsvp.ID
+	}
+
+	answers, err := h.AnswerRepo.GetAnswers(rsvpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range rsvps {
+		rsvps[i].Answers = answers[rsvps[i].ID]
+	}
+	return nil
+}
+
+// formatOptionalTime formats a time for an export, or returns an empty string if it is nil
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// notifyPromotions emails the users an event update moved from the waitlists of
+// its occurrences to "going"
+func notifyPromotions(eventRepo *repositories.EventRepository, waitlistService *services.WaitlistService, eventID int, promotions []models.WaitlistPromotion) {
+	if len(promotions) == 0 {
+		return
+	}
+
+	event, err := eventRepo.GetEventByID(eventID)
+	if err != nil {
+		log.Printf("Warning: Could not get event to notify promoted users: %v\n", err)
+		return
+	}
+
+	organizerEmail := ""
+	if organizer, err := waitlistService.UserRepo.GetUserByID(event.UserID); err == nil {
+		organizerEmail = organizer.Email
+	}
+
+	for _, promotion := range promotions {
+		start, err := eventRepo.ResolveOccurrence(event, promotion.Occurrence)
+		if err != nil {
+			log.Printf("Warning: Could not resolve occurrence of promoted users: %v\n", err)
+			continue
+		}
+		waitlistService.NotifyPromoted(emailEvent(event, start, organizerEmail), promotion.Occurrence, promotion.UserIDs)
+	}
+}
+
+// acceptsRSVPs checks that an event is published and so taking RSVPs. It writes a
+// 409 response and returns false otherwise.
+func acceptsRSVPs(w http.ResponseWriter, event *models.EventWithOrganizer) bool {
+	var message string
+	switch event.Status {
+	case models.EventStatusPublished:
+		return true
+	case models.EventStatusCancelled:
+		message = "This event has been cancelled"
+	case models.EventStatusCompleted:
+		message = "This event has already ended"
+	default:
+		message = "This event has not been published yet"
+	}
+
+	http.Error(w, message, http.StatusConflict)
+	log.Printf("Event %d is %s and not accepting RSVPs\n", event.ID, event.Status)
+	return false
+}
+
+// Error codes returned when RSVPs are changed outside an event's RSVP window
+const (
+	rsvpNotOpenCode = "rsvp_not_open"
+	rsvpClosedCode  = "rsvp_closed"
+)
+
+// rsvpWindow returns when RSVPs to the occurrence of an event starting at start
+// open, if they don't open right away, and when they close. The window of a
+// recurring event is set for its first occurrence and moves with each later one,
+// keeping the same distance from the occurrence's start.
+func rsvpWindow(event *models.EventWithOrganizer, start time.Time) (*time.Time, time.Time) {
+	shift := time.Duration(0)
+	if event.RecurrenceRule != "" {
+		shift = start.Sub(event.Date)
+	}
+
+	var opensAt *time.Time
+	if event.RSVPOpensAt != nil {
+		opens := event.RSVPOpensAt.Add(shift)
+		opensAt = &opens
+	}
+	if event.RSVPClosesAt != nil {
+		return opensAt, event.RSVPClosesAt.Add(shift)
+	}
+	return opensAt, start
+}
+
+// withinRSVPWindow writes a 403 response with an error code and returns false if
+// RSVPs to the occurrence of an event starting at start can't be changed now,
+// unless the organizers allowed the user to RSVP outside the window
+func (h *RSVPHandler) withinRSVPWindow(w http.ResponseWriter, event *models.EventWithOrganizer, start time.Time, userID int) bool {
+	opensAt, closesAt := rsvpWindow(event, start)
+	now := time.Now()
+
+	response := map[string]interface{}{
+		"closes_at": closesAt,
+	}
+	switch {
+	case opensAt != nil && now.Before(*opensAt):
+		response["code"] = rsvpNotOpenCode
+		response["message"] = "RSVPs for this event have not opened yet"
+		response["opens_at"] = opensAt
+	case !now.Before(closesAt):
+		response["code"] = rsvpClosedCode
+		response["message"] = "RSVPs for this event have closed"
+	default:
+		return true
+	}
+
+	overridden, err := h.OverrideRepo.HasOverride(event.ID, userID)
+	if err != nil {
+		http.Error(w, "Failed to check RSVP window", http.StatusInternalServerError)
+		log.Printf("Failed to check RSVP override: %v\n", err)
+		return false
+	}
+	if overridden {
+		return true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(response)
+	log.Printf("User %d changed their RSVP to event %d outside the RSVP window: %s\n", userID, event.ID, response["code"])
+	return false
+}
+
+// emailEvent converts an event to the model used by EmailService, with the
+// start and end of the occurrence that starts at start
+func emailEvent(event *models.EventWithOrganizer, start time.Time, organizerEmail string) *models.Event {
+	var end *time.Time
+	if event.EndDate != nil {
+		endDate := start.Add(event.Duration())
+		end = &endDate
+	}
+
+	return &models.Event{
+		ID:                 event.ID,
+		Title:              event.Title,
+		Description:        event.Description,
+		Date:               start,
+		EndDate:            end,
+		TimeZone:           event.TimeZone,
+		Location:           event.Location,
+		UserID:             event.UserID,
+		CreatedAt:          event.CreatedAt,
+		UpdatedAt:          event.UpdatedAt,
+		OrganizerEmail:     organizerEmail,
+		OrganizerFirstName: event.OrganizerFirstName,
+		OrganizerLastName:  event.OrganizerLastName,
+	}
+}
+
+// resolveOccurrence reads the occurrence query parameter for an event and validates it.
+// It returns nil for one-off events along with the start time of the occurrence, and
+// writes an error response and returns false if the occurrence is missing or invalid.
+func (h *RSVPHandler) resolveOccurrence(w http.ResponseWriter, r *http.Request, event *models.EventWithOrganizer) (*time.Time, time.Time, bool) {
+	occurrence, err := getOccurrenceFromQuery(r)
+	if err != nil {
+		http.Error(w, "Invalid occurrence. Use RFC 3339 format", http.StatusBadRequest)
+		log.Printf("Invalid occurrence: %v\n", err)
+		return nil, time.Time{}, false
+	}
+
+	start, err := h.EventRepo.ResolveOccurrence(event, occurrence)
+	if err != nil {
+		writeOccurrenceError(w, err)
+		return nil, time.Time{}, false
+	}
+
+	if event.RecurrenceRule == "" {
+		return nil, start, true
+	}
+	return occurrence, start, true
+}
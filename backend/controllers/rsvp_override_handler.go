@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// RSVPOverrideHandler handles HTTP requests for the users organizers allow to
+// RSVP outside an event's RSVP window
+type RSVPOverrideHandler struct {
+	OverrideRepo  *repositories.RSVPOverrideRepository
+	EventRepo     *repositories.EventRepository
+	UserRepo      *repositories.UserRepository
+	AccessService *services.AccessService
+}
+
+func NewRSVPOverrideHandler(
+	overrideRepo *repositories.RSVPOverrideRepository,
+	eventRepo *repositories.EventRepository,
+	userRepo *repositories.UserRepository,
+	accessService *services.AccessService,
+) *RSVPOverrideHandler {
+	return &RSVPOverrideHandler{
+		OverrideRepo:  overrideRepo,
+		EventRepo:     eventRepo,
+		UserRepo:      userRepo,
+		AccessService: accessService,
+	}
+}
+
+// GetOverrides handles listing the users allowed to RSVP outside the window
+func (h *RSVPOverrideHandler) GetOverrides(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	overrides, err := h.OverrideRepo.GetOverrides(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to get RSVP overrides", http.StatusInternalServerError)
+		log.Printf("Failed to get RSVP overrides: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overrides)
+}
+
+// AddOverride handles letting a user, found by email, RSVP outside the window
+func (h *RSVPOverrideHandler) AddOverride(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	var req models.RSVPOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "No user found with that email. They need to sign up first", http.StatusNotFound)
+			log.Printf("User not found for RSVP override: %s\n", req.Email)
+			return
+		}
+		http.Error(w, "Failed to add RSVP override", http.StatusInternalServerError)
+		log.Printf("Failed to get user by email: %v\n", err)
+		return
+	}
+
+	if err := h.OverrideRepo.AddOverride(event.ID, user.ID, userID); err != nil {
+		http.Error(w, "Failed to add RSVP override", http.StatusInternalServerError)
+		log.Printf("Failed to add RSVP override: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "User can now RSVP outside the RSVP window",
+		"user_id": user.ID,
+	})
+	log.Printf("User %d allowed user %d to RSVP to event %d outside the window\n", userID, user.ID, event.ID)
+}
+
+// RemoveOverride handles holding a user to the RSVP window again
+func (h *RSVPOverrideHandler) RemoveOverride(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	overrideUserID, err := getPathID(r, "rsvp-overrides")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		log.Printf("Invalid user ID: %v\n", err)
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	if err := h.OverrideRepo.RemoveOverride(event.ID, overrideUserID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "RSVP override not found", http.StatusNotFound)
+			log.Printf("User %d has no RSVP override for event %d\n", overrideUserID, event.ID)
+			return
+		}
+		http.Error(w, "Failed to remove RSVP override", http.StatusInternalServerError)
+		log.Printf("Failed to remove RSVP override: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "RSVP override removed successfully",
+	})
+	log.Printf("User %d removed the RSVP override of user %d for event %d\n", userID, overrideUserID, event.ID)
+}
@@ -0,0 +1,387 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// maxSessionFieldLength matches the size of the session text columns
+const maxSessionFieldLength = 255
+
+// SessionHandler handles HTTP requests for the sessions on an event's agenda
+type SessionHandler struct {
+	SessionRepo   *repositories.SessionRepository
+	RSVPRepo      *repositories.RSVPRepository
+	EventRepo     *repositories.EventRepository
+	AccessService *services.AccessService
+}
+
+func NewSessionHandler(
+	sessionRepo *repositories.SessionRepository,
+	rsvpRepo *repositories.RSVPRepository,
+	eventRepo *repositories.EventRepository,
+	accessService *services.AccessService,
+) *SessionHandler {
+	return &SessionHandler{
+		SessionRepo:   sessionRepo,
+		RSVPRepo:      rsvpRepo,
+		EventRepo:     eventRepo,
+		AccessService: accessService,
+	}
+}
+
+// GetAgenda handles listing an event's sessions, optionally limited to a track or
+// to the sessions the requesting user is attending
+func (h *SessionHandler) GetAgenda(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	event, err := h.EventRepo.GetEventByID(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			log.Printf("Event not found: %v\n", err)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get event: %v\n", err)
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	// The personal schedule needs to know who is asking
+	userID := getOptionalUserID(r)
+	mine := r.URL.Query().Get("mine") == "true"
+	if mine && userID == 0 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Println("Unauthorized: personal schedule requested without a token")
+		return
+	}
+
+	sessions, err := h.SessionRepo.GetSessions(eventID, userID, r.URL.Query().Get("track"), mine)
+	if err != nil {
+		http.Error(w, "Failed to get agenda", http.StatusInternalServerError)
+		log.Printf("Failed to get sessions: %v\n", err)
+		return
+	}
+
+	tracks, err := h.SessionRepo.GetTracks(eventID)
+	if err != nil {
+		http.Error(w, "Failed to get agenda", http.StatusInternalServerError)
+		log.Printf("Failed to get tracks: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Agenda{
+		EventID:  eventID,
+		Tracks:   tracks,
+		Sessions: sessions,
+	})
+}
+
+// CreateSession handles adding a session to an event's agenda
+func (h *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	// A session's times are fixed, so they cannot belong to every occurrence of a series
+	if event.RecurrenceRule != "" {
+		http.Error(w, "Sessions are not supported for recurring events", http.StatusConflict)
+		log.Printf("Session added to recurring event %d\n", event.ID)
+		return
+	}
+
+	req, ok := decodeSessionRequest(w, r, event)
+	if !ok {
+		return
+	}
+
+	id, err := h.SessionRepo.CreateSession(event.ID, req)
+	if err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		log.Printf("Failed to create session: %v\n", err)
+		return
+	}
+
+	session, err := h.SessionRepo.GetSessionByID(event.ID, id, userID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		log.Printf("Failed to get session: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+	log.Printf("Session %d added to event %d by user %d\n", id, event.ID, userID)
+}
+
+// UpdateSession handles changing the details of a session
+func (h *SessionHandler) UpdateSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, session, ok := h.getSession(w, r)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	req, ok := decodeSessionRequest(w, r, event)
+	if !ok {
+		return
+	}
+
+	if err := h.SessionRepo.UpdateSession(session.ID, req); err != nil {
+		http.Error(w, "Failed to update session", http.StatusInternalServerError)
+		log.Printf("Failed to update session: %v\n", err)
+		return
+	}
+
+	updated, err := h.SessionRepo.GetSessionByID(event.ID, session.ID, userID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		log.Printf("Failed to get session: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+	log.Printf("Session %d of event %d updated by user %d\n", session.ID, event.ID, userID)
+}
+
+// DeleteSession handles removing a session from an event's agenda
+func (h *SessionHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, session, ok := h.getSession(w, r)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	if err := h.SessionRepo.DeleteSession(session.ID); err != nil {
+		http.Error(w, "Failed to delete session", http.StatusInternalServerError)
+		log.Printf("Failed to delete session: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Session deleted successfully",
+	})
+	log.Printf("Session %d of event %d deleted by user %d\n", session.ID, event.ID, userID)
+}
+
+// JoinSession handles adding a session to the requesting user's schedule. Only
+// attendees going to the event can join its sessions.
+func (h *SessionHandler) JoinSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, session, ok := h.getSession(w, r)
+	if !ok {
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	if !acceptsRSVPs(w, event) {
+		return
+	}
+
+	rsvp, err := h.RSVPRepo.GetRSVPByEventAndUser(event.ID, userID, nil)
+	if err != nil {
+		http.Error(w, "Failed to join session", http.StatusInternalServerError)
+		log.Printf("Failed to get RSVP: %v\n", err)
+		return
+	}
+	if rsvp == nil || rsvp.Status != "going" {
+		http.Error(w, "RSVP going to the event before joining its sessions", http.StatusConflict)
+		log.Printf("User %d is not going to event %d\n", userID, event.ID)
+		return
+	}
+
+	if err := h.SessionRepo.JoinSession(session.ID, userID); err != nil {
+		if errors.Is(err, repositories.ErrSessionFull) {
+			http.Error(w, "This session is full", http.StatusConflict)
+			log.Printf("Session %d is full\n", session.ID)
+			return
+		}
+		http.Error(w, "Failed to join session", http.StatusInternalServerError)
+		log.Printf("Failed to join session: %v\n", err)
+		return
+	}
+
+	h.writeSession(w, event.ID, session.ID, userID)
+	log.Printf("User %d joined session %d of event %d\n", userID, session.ID, event.ID)
+}
+
+// LeaveSession handles removing a session from the requesting user's schedule
+func (h *SessionHandler) LeaveSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, session, ok := h.getSession(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.SessionRepo.LeaveSession(session.ID, userID); err != nil {
+		http.Error(w, "Failed to leave session", http.StatusInternalServerError)
+		log.Printf("Failed to leave session: %v\n", err)
+		return
+	}
+
+	h.writeSession(w, event.ID, session.ID, userID)
+	log.Printf("User %d left session %d of event %d\n", userID, session.ID, event.ID)
+}
+
+// writeSession writes a session as seen by userID, with up to date seat counts
+func (h *SessionHandler) writeSession(w http.ResponseWriter, eventID, sessionID, userID int) {
+	session, err := h.SessionRepo.GetSessionByID(eventID, sessionID, userID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		log.Printf("Failed to get session: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// getSession authenticates the request and loads the event and session from the URL
+func (h *SessionHandler) getSession(w http.ResponseWriter, r *http.Request) (int, *models.EventWithOrganizer, *models.EventSession, bool) {
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return 0, nil, nil, false
+	}
+
+	sessionID, err := getPathID(r, "sessions")
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		log.Printf("Invalid session ID: %v\n", err)
+		return 0, nil, nil, false
+	}
+
+	session, err := h.SessionRepo.GetSessionByID(event.ID, sessionID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			log.Printf("Session not found: %v\n", err)
+			return 0, nil, nil, false
+		}
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		log.Printf("Failed to get session: %v\n", err)
+		return 0, nil, nil, false
+	}
+
+	return userID, event, session, true
+}
+
+// decodeSessionRequest reads and validates a session create or update request.
+// Sessions must take place while the event is running. It writes a 400 response
+// and returns false if the request is invalid.
+func decodeSessionRequest(w http.ResponseWriter, r *http.Request, event *models.EventWithOrganizer) (models.SessionRequest, bool) {
+	var req models.SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return req, false
+	}
+
+	if err := validateSessionRequest(&req, event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid session: %v\n", err)
+		return req, false
+	}
+
+	return req, true
+}
+
+func validateSessionRequest(req *models.SessionRequest, event *models.EventWithOrganizer) error {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Speaker = strings.TrimSpace(req.Speaker)
+	req.Room = strings.TrimSpace(req.Room)
+	req.Track = strings.TrimSpace(req.Track)
+
+	if req.Title == "" {
+		return errors.New("Title is required")
+	}
+	for _, field := range []string{req.Title, req.Speaker, req.Room, req.Track} {
+		if len(field) > maxSessionFieldLength {
+			return errors.New("Title, speaker, room and track can be at most 255 characters")
+		}
+	}
+
+	if req.StartTime.IsZero() || req.EndTime.IsZero() {
+		return errors.New("Start time and end time are required")
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return errors.New("End time must be after start time")
+	}
+	if req.StartTime.Before(event.Date) || (event.EndDate != nil && req.EndTime.After(*event.EndDate)) {
+		return errors.New("Sessions must take place during the event")
+	}
+
+	if req.Capacity != nil && *req.Capacity <= 0 {
+		return errors.New("Capacity must be a positive number")
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// maxFeedbackLength limits the length of the comment in a survey response
+const maxFeedbackLength = 2000
+
+// SurveyHandler handles HTTP requests for post-event surveys. The survey's
+// questions are managed by a QuestionHandler with the survey question repository.
+type SurveyHandler struct {
+	SurveyRepo    *repositories.SurveyRepository
+	QuestionRepo  *repositories.QuestionRepository
+	RSVPRepo      *repositories.RSVPRepository
+	EventRepo     *repositories.EventRepository
+	AccessService *services.AccessService
+}
+
+func NewSurveyHandler(
+	surveyRepo *repositories.SurveyRepository,
+	questionRepo *repositories.QuestionRepository,
+	rsvpRepo *repositories.RSVPRepository,
+	eventRepo *repositories.EventRepository,
+	accessService *services.AccessService,
+) *SurveyHandler {
+	return &SurveyHandler{
+		SurveyRepo:    surveyRepo,
+		QuestionRepo:  questionRepo,
+		RSVPRepo:      rsvpRepo,
+		EventRepo:     eventRepo,
+		AccessService: accessService,
+	}
+}
+
+// GetSurvey handles getting an event's survey questions along with the user's
+// response and whether they can respond
+func (h *SurveyHandler) GetSurvey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !canViewEvent(w, r, h.AccessService, event) {
+		return
+	}
+
+	questions, err := h.QuestionRepo.GetQuestions(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to get survey", http.StatusInternalServerError)
+		log.Printf("Failed to get survey questions: %v\n", err)
+		return
+	}
+
+	response, err := h.SurveyRepo.GetResponse(event.ID, userID)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, "Failed to get survey", http.StatusInternalServerError)
+		log.Printf("Failed to get survey response: %v\n", err)
+		return
+	}
+
+	going, err := h.RSVPRepo.IsGoing(event.ID, userID)
+	if err != nil {
+		http.Error(w, "Failed to get survey", http.StatusInternalServerError)
+		log.Printf("Failed to check if user went to the event: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Survey{
+		Questions:  questions,
+		Response:   response,
+		CanRespond: going && event.Status == models.EventStatusCompleted,
+	})
+}
+
+// SubmitResponse handles an attendee giving or changing their feedback on an event
+// they went to, once it has ended
+func (h *SurveyHandler) SubmitResponse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if event.Status != models.EventStatusCompleted {
+		http.Error(w, "Feedback opens once the event has ended", http.StatusConflict)
+		log.Printf("User %d gave feedback on event %d before it ended\n", userID, event.ID)
+		return
+	}
+
+	going, err := h.RSVPRepo.IsGoing(event.ID, userID)
+	if err != nil {
+		http.Error(w, "Failed to save feedback", http.StatusInternalServerError)
+		log.Printf("Failed to check if user went to the event: %v\n", err)
+		return
+	}
+	if !going {
+		http.Error(w, "Forbidden: Only attendees who went to the event can give feedback", http.StatusForbidden)
+		log.Printf("Forbidden: User %d was not going to event %d\n", userID, event.ID)
+		return
+	}
+
+	var req models.SurveyResponseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	if req.Rating < 1 || req.Rating > 5 {
+		http.Error(w, "Rating must be between 1 and 5", http.StatusBadRequest)
+		log.Printf("Invalid rating: %d\n", req.Rating)
+		return
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if len(req.Comment) > maxFeedbackLength {
+		http.Error(w, fmt.Sprintf("Comment can be at most %d characters", maxFeedbackLength), http.StatusBadRequest)
+		log.Printf("Feedback comment too long: %d\n", len(req.Comment))
+		return
+	}
+
+	questions, err := h.QuestionRepo.GetQuestions(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to save feedback", http.StatusInternalServerError)
+		log.Printf("Failed to get survey questions: %v\n", err)
+		return
+	}
+	answers, err := validateAnswers(questions, req.Answers, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid survey answers: %v\n", err)
+		return
+	}
+	req.Answers = answers
+
+	if _, err := h.SurveyRepo.SaveResponse(event.ID, userID, req); err != nil {
+		http.Error(w, "Failed to save feedback", http.StatusInternalServerError)
+		log.Printf("Failed to save survey response: %v\n", err)
+		return
+	}
+
+	response, err := h.SurveyRepo.GetResponse(event.ID, userID)
+	if err != nil {
+		http.Error(w, "Failed to get feedback", http.StatusInternalServerError)
+		log.Printf("Failed to get survey response: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	log.Printf("User %d rated event %d %d out of 5\n", userID, event.ID, req.Rating)
+}
+
+// GetResults handles getting the aggregated survey responses of an event
+func (h *SurveyHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, event, ok := getUserAndEvent(w, r, h.EventRepo)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, h.AccessService, event, userID, services.PermissionEditEvent) {
+		return
+	}
+
+	questions, err := h.QuestionRepo.GetQuestions(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to get survey results", http.StatusInternalServerError)
+		log.Printf("Failed to get survey questions: %v\n", err)
+		return
+	}
+
+	results, err := h.SurveyRepo.GetResults(event.ID, questions)
+	if err != nil {
+		http.Error(w, "Failed to get survey results", http.StatusInternalServerError)
+		log.Printf("Failed to get survey results: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
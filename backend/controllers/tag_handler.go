@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// TagHandler handles tag-related HTTP requests
+type TagHandler struct {
+	TagRepo *repositories.TagRepository
+}
+
+func NewTagHandler(tagRepo *repositories.TagRepository) *TagHandler {
+	return &TagHandler{TagRepo: tagRepo}
+}
+
+// GetTags handles listing tags with their usage counts
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	tags, err := h.TagRepo.GetTags()
+	if err != nil {
+		http.Error(w, "Failed to get tags", http.StatusInternalServerError)
+		log.Printf("Failed to get tags: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
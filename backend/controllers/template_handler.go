@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// maxTemplateNameLength matches the size of the name column
+const maxTemplateNameLength = 100
+
+// TemplateHandler handles HTTP requests for a user's event templates
+type TemplateHandler struct {
+	TemplateRepo *repositories.TemplateRepository
+	EventRepo    *repositories.EventRepository
+	VenueRepo    *repositories.VenueRepository
+}
+
+func NewTemplateHandler(templateRepo *repositories.TemplateRepository, eventRepo *repositories.EventRepository, venueRepo *repositories.VenueRepository) *TemplateHandler {
+	return &TemplateHandler{
+		TemplateRepo: templateRepo,
+		EventRepo:    eventRepo,
+		VenueRepo:    venueRepo,
+	}
+}
+
+// GetTemplates handles listing the requesting user's templates
+func (h *TemplateHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return
+	}
+
+	templates, err := h.TemplateRepo.GetTemplates(userID)
+	if err != nil {
+		http.Error(w, "Failed to get templates", http.StatusInternalServerError)
+		log.Printf("Failed to get templates: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// CreateTemplate handles saving a new named template
+func (h *TemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return
+	}
+
+	req, ok := decodeTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.TemplateRepo.CreateTemplate(userID, req.Name, req.Fields)
+	if err != nil {
+		writeTemplateError(w, err, "Failed to create template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+	log.Printf("Template %d created by user %d\n", template.ID, userID)
+}
+
+// GetTemplate handles retrieving one of the requesting user's templates
+func (h *TemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	_, template, ok := h.getTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+// UpdateTemplate handles renaming a template and replacing its fields
+func (h *TemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, template, ok := h.getTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.TemplateRepo.UpdateTemplate(template.ID, req.Name, req.Fields); err != nil {
+		writeTemplateError(w, err, "Failed to update template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Template updated successfully",
+	})
+	log.Printf("Template %d updated by user %d\n", template.ID, userID)
+}
+
+// DeleteTemplate handles deleting a template
+func (h *TemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, template, ok := h.getTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.TemplateRepo.DeleteTemplate(template.ID); err != nil {
+		http.Error(w, "Failed to delete template", http.StatusInternalServerError)
+		log.Printf("Failed to delete template: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Template deleted successfully",
+	})
+	log.Printf("Template %d deleted by user %d\n", template.ID, userID)
+}
+
+// CreateEventFromTemplate handles creating an event from a template. Fields in the
+// request body, which must include the date, override the template's fields.
+func (h *TemplateHandler) CreateEventFromTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, template, ok := h.getTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.EventRequest
+	if err := json.Unmarshal(template.Fields, &req); err != nil {
+		http.Error(w, "Failed to read template", http.StatusInternalServerError)
+		log.Printf("Failed to read template %d: %v\n", template.ID, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	if id, ok := createEvent(w, h.EventRepo, h.VenueRepo, req, userID); ok {
+		log.Printf("Event %d created from template %d by user %d\n", id, template.ID, userID)
+	}
+}
+
+// getTemplate authenticates the request and loads the template from the URL.
+// Templates belong to a single user, so other users' templates are not found.
+func (h *TemplateHandler) getTemplate(w http.ResponseWriter, r *http.Request) (int, *models.EventTemplate, bool) {
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return 0, nil, false
+	}
+
+	templateID, err := getPathID(r, "templates")
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		log.Printf("Invalid template ID: %v\n", err)
+		return 0, nil, false
+	}
+
+	template, err := h.TemplateRepo.GetTemplateByID(templateID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			log.Printf("Template not found: %v\n", err)
+			return 0, nil, false
+		}
+		http.Error(w, "Failed to get template", http.StatusInternalServerError)
+		log.Printf("Failed to get template: %v\n", err)
+		return 0, nil, false
+	}
+
+	if template.UserID != userID {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		log.Printf("User %d denied access to template %d\n", userID, templateID)
+		return 0, nil, false
+	}
+
+	return userID, template, true
+}
+
+// decodeTemplateRequest reads and validates a template create or update request.
+// It writes a 400 response and returns false if the request is invalid.
+func decodeTemplateRequest(w http.ResponseWriter, r *http.Request) (models.EventTemplateRequest, bool) {
+	var req models.EventTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return req, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxTemplateNameLength {
+		http.Error(w, "Template name is required and can be at most 100 characters", http.StatusBadRequest)
+		log.Printf("Invalid template name: %q\n", req.Name)
+		return req, false
+	}
+
+	if err := validateTemplateFields(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Invalid template fields: %v\n", err)
+		return req, false
+	}
+
+	return req, true
+}
+
+// validateTemplateFields checks the event fields saved in a template. Templates
+// describe events without dates, which are given when the template is used.
+func validateTemplateFields(req *models.EventTemplateRequest) error {
+	if len(bytes.TrimSpace(req.Fields)) == 0 || bytes.Equal(bytes.TrimSpace(req.Fields), []byte("null")) {
+		req.Fields = json.RawMessage("{}")
+	}
+
+	var fields models.EventRequest
+	if err := json.Unmarshal(req.Fields, &fields); err != nil {
+		return errors.New("Invalid template fields. Fields must be an object of event fields")
+	}
+
+	if !fields.Date.IsZero() || fields.EndDate != nil || fields.PublishAt != nil {
+		return errors.New("Templates cannot set date, end_date or publish_at. Use duration_minutes for the event's length")
+	}
+
+	return validateEventFields(&fields)
+}
+
+// writeTemplateError writes the response for an error returned when saving a template
+func writeTemplateError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, repositories.ErrTemplateNameTaken) {
+		http.Error(w, "You already have a template with this name", http.StatusConflict)
+		log.Printf("Template name taken: %v\n", err)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+	log.Printf("%s: %v\n", message, err)
+}
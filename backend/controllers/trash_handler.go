@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+	"github.com/johneliud/evently/backend/services"
+)
+
+// TrashHandler handles HTTP requests for deleted events, which their owners can
+// restore until the trash job purges them
+type TrashHandler struct {
+	EventRepo    *repositories.EventRepository
+	MediaService *services.MediaService
+	TrashService *services.TrashService
+}
+
+func NewTrashHandler(
+	eventRepo *repositories.EventRepository,
+	mediaService *services.MediaService,
+	trashService *services.TrashService,
+) *TrashHandler {
+	return &TrashHandler{
+		EventRepo:    eventRepo,
+		MediaService: mediaService,
+		TrashService: trashService,
+	}
+}
+
+// GetTrash handles listing a page of the events the user deleted, most recently
+// deleted first, with when each will be purged
+func (h *TrashHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return
+	}
+
+	limit, cursor, ok := getPageParams(w, r)
+	if !ok {
+		return
+	}
+
+	events, next, err := h.EventRepo.GetDeletedEvents(userID, limit, cursor)
+	if err != nil {
+		writeListError(w, err, "Failed to get trash")
+		return
+	}
+
+	for i := range events {
+		purgeAt := h.TrashService.PurgeAt(*events[i].DeletedAt)
+		events[i].PurgeAt = &purgeAt
+		events[i].CoverImage = h.MediaService.CoverImage(events[i].CoverImageKey)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Page[models.Event]{
+		Items:      events,
+		NextCursor: repositories.EncodeCursor(next),
+	})
+}
+
+// RestoreEvent handles the owner of a deleted event taking it out of the trash.
+// The event's venue booking is checked again, since others may have booked the
+// venue while it was in the trash.
+func (h *TrashHandler) RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return
+	}
+
+	eventID, err := getEventIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		log.Printf("Invalid event ID: %v\n", err)
+		return
+	}
+
+	// The body is optional and only needed to allow venue conflicts
+	var options models.RestoreEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return
+	}
+
+	// Only the owner's own deleted events can be restored, so events in other
+	// people's trash are reported as missing
+	event, err := h.EventRepo.GetDeletedEvent(eventID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found in trash", http.StatusNotFound)
+			log.Printf("Event %d not found in the trash of user %d\n", eventID, userID)
+			return
+		}
+		http.Error(w, "Failed to get event", http.StatusInternalServerError)
+		log.Printf("Failed to get deleted event: %v\n", err)
+		return
+	}
+
+	// Cancelled events don't hold their venue
+	var conflicts []models.VenueBooking
+	if event.Status != models.EventStatusCancelled {
+		req := models.EventRequest{
+			Date:           event.Date,
+			EndDate:        event.EndDate,
+			TimeZone:       event.TimeZone,
+			RecurrenceRule: event.RecurrenceRule,
+			VenueID:        event.VenueID,
+			RoomID:         event.RoomID,
+			AllowConflicts: options.AllowConflicts,
+		}
+		var ok bool
+		if conflicts, ok = checkVenueConflicts(w, h.EventRepo, req, event.ID, userID); !ok {
+			return
+		}
+	}
+
+	if err := h.EventRepo.RestoreEvent(eventID, userID, options.AllowConflicts); err != nil {
+		if writeVenueConflictError(w, err, userID) {
+			return
+		}
+		if err == sql.ErrNoRows {
+			http.Error(w, "Event not found in trash", http.StatusNotFound)
+			log.Printf("Event %d not found in the trash of user %d\n", eventID, userID)
+			return
+		}
+		http.Error(w, "Failed to restore event", http.StatusInternalServerError)
+		log.Printf("Failed to restore event: %v\n", err)
+		return
+	}
+
+	// Return success response, including any overlapping bookings that were allowed
+	response := map[string]interface{}{
+		"message": "Event restored successfully",
+	}
+	if len(conflicts) > 0 {
+		response["conflicts"] = conflicts
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	log.Printf("Event %d restored from trash by user %d\n", eventID, userID)
+}
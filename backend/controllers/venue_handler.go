@@ -0,0 +1,624 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// maxVenueNameLength matches the size of the venue and room name columns
+const maxVenueNameLength = 255
+
+// maxAvailabilityDays limits the date range of a venue availability request
+const maxAvailabilityDays = 366
+
+// VenueHandler handles HTTP requests for venues, their rooms and their bookings
+type VenueHandler struct {
+	VenueRepo *repositories.VenueRepository
+	EventRepo *repositories.EventRepository
+}
+
+func NewVenueHandler(venueRepo *repositories.VenueRepository, eventRepo *repositories.EventRepository) *VenueHandler {
+	return &VenueHandler{
+		VenueRepo: venueRepo,
+		EventRepo: eventRepo,
+	}
+}
+
+// GetVenues handles listing venues, optionally filtered by a search query on
+// their name and address
+func (h *VenueHandler) GetVenues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	venues, err := h.VenueRepo.GetVenues(strings.TrimSpace(r.URL.Query().Get("q")))
+	if err != nil {
+		http.Error(w, "Failed to get venues", http.StatusInternalServerError)
+		log.Printf("Failed to get venues: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(venues)
+}
+
+// CreateVenue handles adding a venue, which the user who adds it manages
+func (h *VenueHandler) CreateVenue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return
+	}
+
+	req, ok := decodeVenueRequest(w, r, nil)
+	if !ok {
+		return
+	}
+
+	id, err := h.VenueRepo.CreateVenue(req, userID)
+	if err != nil {
+		http.Error(w, "Failed to create venue", http.StatusInternalServerError)
+		log.Printf("Failed to create venue: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      id,
+		"message": "Venue created successfully",
+	})
+	log.Printf("Venue %d created by user %d\n", id, userID)
+}
+
+// GetVenue handles retrieving a venue along with its rooms
+func (h *VenueHandler) GetVenue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	venue, ok := h.getVenue(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(venue)
+}
+
+// UpdateVenue handles changing the details of a venue
+func (h *VenueHandler) UpdateVenue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, venue, ok := h.getManagedVenue(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeVenueRequest(w, r, venue)
+	if !ok {
+		return
+	}
+
+	if err := h.VenueRepo.UpdateVenue(venue.ID, req); err != nil {
+		http.Error(w, "Failed to update venue", http.StatusInternalServerError)
+		log.Printf("Failed to update venue: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Venue updated successfully",
+	})
+	log.Printf("Venue %d updated by user %d\n", venue.ID, userID)
+}
+
+// DeleteVenue handles deleting a venue. Events booked there keep their location.
+func (h *VenueHandler) DeleteVenue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, venue, ok := h.getManagedVenue(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.VenueRepo.DeleteVenue(venue.ID); err != nil {
+		http.Error(w, "Failed to delete venue", http.StatusInternalServerError)
+		log.Printf("Failed to delete venue: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Venue deleted successfully",
+	})
+	log.Printf("Venue %d deleted by user %d\n", venue.ID, userID)
+}
+
+// CreateRoom handles adding a room to a venue
+func (h *VenueHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, venue, ok := h.getManagedVenue(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeRoomRequest(w, r, venue)
+	if !ok {
+		return
+	}
+
+	id, err := h.VenueRepo.CreateRoom(venue.ID, req)
+	if err != nil {
+		writeRoomError(w, err, "Failed to create room")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      id,
+		"message": "Room created successfully",
+	})
+	log.Printf("Room %d added to venue %d by user %d\n", id, venue.ID, userID)
+}
+
+// UpdateRoom handles changing the name or capacity of a room
+func (h *VenueHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, venue, ok := h.getManagedVenue(w, r)
+	if !ok {
+		return
+	}
+
+	room, ok := getRoomFromPath(w, r, venue)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeRoomRequest(w, r, venue)
+	if !ok {
+		return
+	}
+
+	if err := h.VenueRepo.UpdateRoom(room.ID, req); err != nil {
+		writeRoomError(w, err, "Failed to update room")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Room updated successfully",
+	})
+	log.Printf("Room %d of venue %d updated by user %d\n", room.ID, venue.ID, userID)
+}
+
+// DeleteRoom handles deleting a room. Events booked in it keep their location
+// but no longer hold the venue.
+func (h *VenueHandler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	userID, venue, ok := h.getManagedVenue(w, r)
+	if !ok {
+		return
+	}
+
+	room, ok := getRoomFromPath(w, r, venue)
+	if !ok {
+		return
+	}
+
+	if err := h.VenueRepo.DeleteRoom(room.ID); err != nil {
+		http.Error(w, "Failed to delete room", http.StatusInternalServerError)
+		log.Printf("Failed to delete room: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Room deleted successfully",
+	})
+	log.Printf("Room %d of venue %d deleted by user %d\n", room.ID, venue.ID, userID)
+}
+
+// GetAvailability handles listing the bookings of a venue, or of one of its rooms
+// given by room_id, between the from and to dates (YYYY-MM-DD, in UTC, both
+// inclusive). It defaults to the next 30 days. Events the requesting user may not
+// see are shown as reserved without their details.
+func (h *VenueHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Println("Method not allowed")
+		return
+	}
+
+	venue, ok := h.getVenue(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	to := from.AddDate(0, 0, 30)
+	var err error
+	if fromStr := query.Get("from"); fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			http.Error(w, "Invalid from date. Use YYYY-MM-DD format", http.StatusBadRequest)
+			log.Printf("Invalid from date: %v\n", err)
+			return
+		}
+		to = from.AddDate(0, 0, 30)
+	}
+	if toStr := query.Get("to"); toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			http.Error(w, "Invalid to date. Use YYYY-MM-DD format", http.StatusBadRequest)
+			log.Printf("Invalid to date: %v\n", err)
+			return
+		}
+	}
+	if to.Before(from) || to.Sub(from) >= maxAvailabilityDays*24*time.Hour {
+		http.Error(w, fmt.Sprintf("The to date must be on or after the from date and at most %d days later", maxAvailabilityDays-1), http.StatusBadRequest)
+		log.Printf("Invalid availability range %s to %s\n", from.Format("2006-01-02"), to.Format("2006-01-02"))
+		return
+	}
+	// Include the whole of the last day
+	end := to.AddDate(0, 0, 1)
+
+	var roomID *int
+	if roomStr := query.Get("room_id"); roomStr != "" {
+		id, err := strconv.Atoi(roomStr)
+		if err != nil || findRoom(venue, id) == nil {
+			http.Error(w, "Room not found", http.StatusNotFound)
+			log.Printf("Room %q not found in venue %d\n", roomStr, venue.ID)
+			return
+		}
+		roomID = &id
+	}
+
+	bookings, err := h.EventRepo.GetVenueBookings(venue.ID, roomID, from, end, 0)
+	if err != nil {
+		http.Error(w, "Failed to get venue availability", http.StatusInternalServerError)
+		log.Printf("Failed to get venue bookings: %v\n", err)
+		return
+	}
+	maskBookings(bookings, getOptionalUserID(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.VenueAvailability{
+		VenueID:  venue.ID,
+		RoomID:   roomID,
+		From:     from,
+		To:       end,
+		Bookings: bookings,
+	})
+}
+
+// getVenue loads the venue from the URL. It writes an error response and
+// returns false if it fails.
+func (h *VenueHandler) getVenue(w http.ResponseWriter, r *http.Request) (*models.Venue, bool) {
+	venueID, err := getPathID(r, "venues")
+	if err != nil {
+		http.Error(w, "Invalid venue ID", http.StatusBadRequest)
+		log.Printf("Invalid venue ID: %v\n", err)
+		return nil, false
+	}
+
+	venue, err := h.VenueRepo.GetVenueByID(venueID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Venue not found", http.StatusNotFound)
+			log.Printf("Venue not found: %v\n", err)
+			return nil, false
+		}
+		http.Error(w, "Failed to get venue", http.StatusInternalServerError)
+		log.Printf("Failed to get venue: %v\n", err)
+		return nil, false
+	}
+
+	return venue, true
+}
+
+// getManagedVenue authenticates the request and loads the venue from the URL,
+// checking that the user manages it. It writes an error response and returns
+// false if any step fails.
+func (h *VenueHandler) getManagedVenue(w http.ResponseWriter, r *http.Request) (int, *models.Venue, bool) {
+	// Get user ID from token
+	userID, err := getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Printf("Unauthorized: %v\n", err)
+		return 0, nil, false
+	}
+
+	venue, ok := h.getVenue(w, r)
+	if !ok {
+		return 0, nil, false
+	}
+
+	if venue.UserID != userID {
+		http.Error(w, "Forbidden: Only the user who added this venue can manage it", http.StatusForbidden)
+		log.Printf("User %d denied management of venue %d\n", userID, venue.ID)
+		return 0, nil, false
+	}
+
+	return userID, venue, true
+}
+
+// getRoomFromPath finds the room of the venue given in the URL. It writes a 404
+// response and returns false if the venue has no such room.
+func getRoomFromPath(w http.ResponseWriter, r *http.Request, venue *models.Venue) (*models.VenueRoom, bool) {
+	roomID, err := getPathID(r, "rooms")
+	if err != nil {
+		http.Error(w, "Invalid room ID", http.StatusBadRequest)
+		log.Printf("Invalid room ID: %v\n", err)
+		return nil, false
+	}
+
+	room := findRoom(venue, roomID)
+	if room == nil {
+		http.Error(w, "Room not found", http.StatusNotFound)
+		log.Printf("Room %d not found in venue %d\n", roomID, venue.ID)
+		return nil, false
+	}
+
+	return room, true
+}
+
+// findRoom returns the venue's room with the given ID, or nil if it has none
+func findRoom(venue *models.Venue, roomID int) *models.VenueRoom {
+	for i := range venue.Rooms {
+		if venue.Rooms[i].ID == roomID {
+			return &venue.Rooms[i]
+		}
+	}
+	return nil
+}
+
+// decodeVenueRequest reads and validates a venue create or update request. When
+// updating, the capacity of existing is checked against the capacity of its
+// rooms. It writes a 400 response and returns false if the request is invalid.
+func decodeVenueRequest(w http.ResponseWriter, r *http.Request, existing *models.Venue) (models.VenueRequest, bool) {
+	var req models.VenueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return req, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Address = strings.TrimSpace(req.Address)
+	if req.Name == "" || len(req.Name) > maxVenueNameLength {
+		http.Error(w, "Venue name is required and can be at most 255 characters", http.StatusBadRequest)
+		log.Printf("Invalid venue name: %q\n", req.Name)
+		return req, false
+	}
+
+	if req.Capacity != nil && *req.Capacity < 1 {
+		http.Error(w, "Capacity must be at least 1", http.StatusBadRequest)
+		log.Printf("Invalid venue capacity: %d\n", *req.Capacity)
+		return req, false
+	}
+
+	if existing != nil && req.Capacity != nil {
+		for _, room := range existing.Rooms {
+			if room.Capacity != nil && *room.Capacity > *req.Capacity {
+				http.Error(w, fmt.Sprintf("Capacity cannot be less than the capacity of room %q", room.Name), http.StatusBadRequest)
+				log.Printf("Venue capacity %d is less than room %d's\n", *req.Capacity, room.ID)
+				return req, false
+			}
+		}
+	}
+
+	return req, true
+}
+
+// decodeRoomRequest reads and validates a room create or update request. It
+// writes a 400 response and returns false if the request is invalid.
+func decodeRoomRequest(w http.ResponseWriter, r *http.Request, venue *models.Venue) (models.VenueRoomRequest, bool) {
+	var req models.VenueRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Invalid request body: %v\n", err)
+		return req, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxVenueNameLength {
+		http.Error(w, "Room name is required and can be at most 255 characters", http.StatusBadRequest)
+		log.Printf("Invalid room name: %q\n", req.Name)
+		return req, false
+	}
+
+	if req.Capacity != nil && *req.Capacity < 1 {
+		http.Error(w, "Capacity must be at least 1", http.StatusBadRequest)
+		log.Printf("Invalid room capacity: %d\n", *req.Capacity)
+		return req, false
+	}
+	if req.Capacity != nil && venue.Capacity != nil && *req.Capacity > *venue.Capacity {
+		http.Error(w, fmt.Sprintf("Capacity cannot exceed the venue's capacity of %d", *venue.Capacity), http.StatusBadRequest)
+		log.Printf("Room capacity %d exceeds venue %d's\n", *req.Capacity, venue.ID)
+		return req, false
+	}
+
+	return req, true
+}
+
+// writeRoomError writes the response for an error returned when saving a room
+func writeRoomError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, repositories.ErrRoomNameTaken) {
+		http.Error(w, "This venue already has a room with this name", http.StatusConflict)
+		log.Printf("Room name taken: %v\n", err)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+	log.Printf("%s: %v\n", message, err)
+}
+
+// applyVenue checks the venue and room an event request books, and that the
+// event's capacity fits in them. An empty location is filled in from the venue.
+// It writes an error response and returns false if the booking is invalid.
+func applyVenue(w http.ResponseWriter, venueRepo *repositories.VenueRepository, req *models.EventRequest) bool {
+	if req.VenueID == nil {
+		return true
+	}
+
+	venue, err := venueRepo.GetVenueByID(*req.VenueID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Venue not found", http.StatusBadRequest)
+			log.Printf("Venue %d not found\n", *req.VenueID)
+			return false
+		}
+		http.Error(w, "Failed to get venue", http.StatusInternalServerError)
+		log.Printf("Failed to get venue: %v\n", err)
+		return false
+	}
+
+	limit, space := venue.Capacity, "venue"
+	if req.RoomID != nil {
+		room := findRoom(venue, *req.RoomID)
+		if room == nil {
+			http.Error(w, "Room not found at this venue", http.StatusBadRequest)
+			log.Printf("Room %d not found in venue %d\n", *req.RoomID, venue.ID)
+			return false
+		}
+		if room.Capacity != nil {
+			limit, space = room.Capacity, "room"
+		}
+	}
+
+	if req.Capacity != nil && limit != nil && *req.Capacity > *limit {
+		http.Error(w, fmt.Sprintf("Capacity cannot exceed the %s's capacity of %d", space, *limit), http.StatusBadRequest)
+		log.Printf("Event capacity %d exceeds %s capacity %d\n", *req.Capacity, space, *limit)
+		return false
+	}
+
+	if strings.TrimSpace(req.Location) == "" {
+		req.Location = venue.Name
+		if venue.Address != "" {
+			req.Location += ", " + venue.Address
+		}
+	}
+
+	return true
+}
+
+// checkVenueConflicts looks for bookings of the requested venue or room that
+// overlap the event. They are rejected with a 409 response listing them unless
+// the request allows conflicts, in which case they are returned as warnings.
+// excludeEventID is the event being updated, or 0 for a new event. It writes an
+// error response and returns false if the event cannot be saved.
+func checkVenueConflicts(w http.ResponseWriter, eventRepo *repositories.EventRepository, req models.EventRequest, excludeEventID, userID int) ([]models.VenueBooking, bool) {
+	if req.VenueID == nil {
+		return nil, true
+	}
+
+	// Bookings need an end to be compared
+	if req.EndDate == nil {
+		http.Error(w, "An end date or duration is required to book a venue", http.StatusBadRequest)
+		log.Println("Venue booking without an end date")
+		return nil, false
+	}
+
+	conflicts, err := eventRepo.FindVenueConflicts(req, excludeEventID)
+	if err != nil {
+		http.Error(w, "Failed to check venue availability", http.StatusInternalServerError)
+		log.Printf("Failed to find venue conflicts: %v\n", err)
+		return nil, false
+	}
+	maskBookings(conflicts, userID)
+
+	if len(conflicts) > 0 && !req.AllowConflicts {
+		writeVenueConflicts(w, conflicts)
+		log.Printf("Venue %d has %d conflicting bookings\n", *req.VenueID, len(conflicts))
+		return nil, false
+	}
+
+	return conflicts, true
+}
+
+// writeVenueConflictError writes the 409 response and returns true if err reports
+// that the venue was booked by another event after checkVenueConflicts passed
+func writeVenueConflictError(w http.ResponseWriter, err error, userID int) bool {
+	var conflictErr *repositories.VenueConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+
+	maskBookings(conflictErr.Conflicts, userID)
+	writeVenueConflicts(w, conflictErr.Conflicts)
+	log.Printf("Venue was booked concurrently: %v\n", err)
+	return true
+}
+
+// writeVenueConflicts writes the 409 response listing the bookings that overlap
+// the requested venue or room
+func writeVenueConflicts(w http.ResponseWriter, conflicts []models.VenueBooking) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "The venue is already booked at this time. Set allow_conflicts to book it anyway",
+		"conflicts": conflicts,
+	})
+}
+
+// maskBookings hides the details of bookings for events that are not publicly
+// listed, unless they belong to userID
+func maskBookings(bookings []models.VenueBooking, userID int) {
+	for i := range bookings {
+		booking := &bookings[i]
+		if booking.UserID == userID && userID != 0 {
+			continue
+		}
+		listed := booking.Status == models.EventStatusPublished || booking.Status == models.EventStatusCompleted
+		if booking.Visibility != "public" || !listed {
+			booking.EventID = 0
+			booking.Title = "Reserved"
+			booking.OccurrenceDate = nil
+		}
+	}
+}
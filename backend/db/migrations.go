@@ -51,8 +51,7 @@ func RunMigrations(db *sql.DB) error {
             user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
             status VARCHAR(20) NOT NULL CHECK (status IN ('going', 'maybe', 'not_going')),
             created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-            UNIQUE(event_id, user_id)
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
         )
     `)
 	if err != nil {
@@ -76,5 +75,530 @@ func RunMigrations(db *sql.DB) error {
 		return err
 	}
 
+	// Add recurrence rule columns to events
+	_, err = db.Exec(`
+        ALTER TABLE events
+            ADD COLUMN IF NOT EXISTS recurrence_rule TEXT NOT NULL DEFAULT '',
+            ADD COLUMN IF NOT EXISTS recurrence_end TIMESTAMP WITH TIME ZONE
+    `)
+	if err != nil {
+		log.Println("Error adding recurrence columns to events table: ", err)
+		return err
+	}
+
+	// Create event_occurrence_exceptions table
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_occurrence_exceptions (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            occurrence_date TIMESTAMP WITH TIME ZONE NOT NULL,
+            cancelled BOOLEAN NOT NULL DEFAULT FALSE,
+            new_date TIMESTAMP WITH TIME ZONE,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(event_id, occurrence_date)
+        )
+    `)
+	if err != nil {
+		log.Println("Error creating event_occurrence_exceptions table: ", err)
+		return err
+	}
+
+	// Make RSVPs per-occurrence: one RSVP per user for each occurrence of an event
+	_, err = db.Exec(`
+        ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS occurrence_date TIMESTAMP WITH TIME ZONE;
+        ALTER TABLE rsvps DROP CONSTRAINT IF EXISTS rsvps_event_id_user_id_key;
+        CREATE UNIQUE INDEX IF NOT EXISTS rsvps_event_user_occurrence_key
+            ON rsvps (event_id, user_id, COALESCE(occurrence_date, '1970-01-01 00:00:00+00'::timestamptz));
+    `)
+	if err != nil {
+		log.Println("Error adding occurrence column to rsvps table: ", err)
+		return err
+	}
+
+	// Add end date and IANA time zone columns to events
+	_, err = db.Exec(`
+        ALTER TABLE events
+            ADD COLUMN IF NOT EXISTS end_date TIMESTAMP WITH TIME ZONE,
+            ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC'
+    `)
+	if err != nil {
+		log.Println("Error adding end date and timezone columns to events table: ", err)
+		return err
+	}
+
+	// Add capacity to events and a waitlist to RSVPs
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS capacity INTEGER CHECK (capacity > 0);
+        ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS waitlisted_at TIMESTAMP WITH TIME ZONE;
+        ALTER TABLE rsvps DROP CONSTRAINT IF EXISTS rsvps_status_check;
+        ALTER TABLE rsvps ADD CONSTRAINT rsvps_status_check
+            CHECK (status IN ('going', 'maybe', 'not_going', 'waitlisted'));
+    `)
+	if err != nil {
+		log.Println("Error adding capacity and waitlist columns: ", err)
+		return err
+	}
+
+	// Add visibility to events
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+            CHECK (visibility IN ('public', 'unlisted', 'private'))
+    `)
+	if err != nil {
+		log.Println("Error adding visibility column to events table: ", err)
+		return err
+	}
+
+	// Create event_invite_tokens table
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_invite_tokens (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            nonce VARCHAR(64) UNIQUE NOT NULL,
+            created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            revoked_at TIMESTAMP WITH TIME ZONE
+        )
+    `)
+	if err != nil {
+		log.Println("Error creating event_invite_tokens table: ", err)
+		return err
+	}
+
+	// Create event_members table. The event's creator is always its owner and is
+	// not stored here.
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_members (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            role VARCHAR(20) NOT NULL CHECK (role IN ('co_organizer', 'checkin_staff', 'viewer')),
+            invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(event_id, user_id)
+        )
+    `)
+	if err != nil {
+		log.Println("Error creating event_members table: ", err)
+		return err
+	}
+
+	// Create tags and event_tags tables
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS tags (
+            id SERIAL PRIMARY KEY,
+            name VARCHAR(50) UNIQUE NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE IF NOT EXISTS event_tags (
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+            PRIMARY KEY (event_id, tag_id)
+        );
+        CREATE INDEX IF NOT EXISTS event_tags_tag_id_idx ON event_tags (tag_id);
+    `)
+	if err != nil {
+		log.Println("Error creating tags tables: ", err)
+		return err
+	}
+
+	// Add full-text search over title, description, location and organizer name.
+	// The organizer's name lives in users, so the vector is kept up to date by triggers
+	// on both tables instead of a generated column.
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector;
+        CREATE INDEX IF NOT EXISTS events_search_vector_idx ON events USING GIN (search_vector);
+
+        CREATE OR REPLACE FUNCTION events_search_vector(event_title TEXT, event_description TEXT, event_location TEXT, organizer_id INTEGER)
+        RETURNS tsvector AS $$
+            SELECT setweight(to_tsvector('english', COALESCE(event_title, '')), 'A') ||
+                   setweight(to_tsvector('english', COALESCE(event_location, '')), 'B') ||
+                   setweight(to_tsvector('english', COALESCE((SELECT first_name || ' ' || last_name FROM users WHERE id = organizer_id), '')), 'B') ||
+                   setweight(to_tsvector('english', COALESCE(event_description, '')), 'C')
+        $$ LANGUAGE sql STABLE;
+
+        CREATE OR REPLACE FUNCTION events_search_vector_trigger() RETURNS trigger AS $$
+        BEGIN
+            NEW.search_vector := events_search_vector(NEW.title, NEW.description, NEW.location, NEW.user_id);
+            RETURN NEW;
+        END
+        $$ LANGUAGE plpgsql;
+
+        DROP TRIGGER IF EXISTS events_search_vector_update ON events;
+        CREATE TRIGGER events_search_vector_update
+            BEFORE INSERT OR UPDATE OF title, description, location, user_id ON events
+            FOR EACH ROW EXECUTE FUNCTION events_search_vector_trigger();
+
+        CREATE OR REPLACE FUNCTION users_search_vector_trigger() RETURNS trigger AS $$
+        BEGIN
+            UPDATE events SET search_vector = events_search_vector(title, description, location, user_id)
+            WHERE user_id = NEW.id;
+            RETURN NULL;
+        END
+        $$ LANGUAGE plpgsql;
+
+        DROP TRIGGER IF EXISTS users_search_vector_update ON users;
+        CREATE TRIGGER users_search_vector_update
+            AFTER UPDATE OF first_name, last_name ON users
+            FOR EACH ROW EXECUTE FUNCTION users_search_vector_trigger();
+
+        UPDATE events SET search_vector = events_search_vector(title, description, location, user_id)
+        WHERE search_vector IS NULL;
+    `)
+	if err != nil {
+		log.Println("Error adding full-text search to events table: ", err)
+		return err
+	}
+
+	// Add coordinates to events
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90);
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180);
+        CREATE INDEX IF NOT EXISTS events_coordinates_idx ON events (latitude, longitude);
+    `)
+	if err != nil {
+		log.Println("Error adding coordinates to events table: ", err)
+		return err
+	}
+
+	// Add lifecycle states to events. Existing events are already live, so they
+	// start out published.
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+            CHECK (status IN ('draft', 'scheduled', 'published', 'cancelled', 'completed'));
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS cancellation_reason TEXT NOT NULL DEFAULT '';
+        CREATE INDEX IF NOT EXISTS events_status_idx ON events (status);
+    `)
+	if err != nil {
+		log.Println("Error adding lifecycle states to events table: ", err)
+		return err
+	}
+
+	// Create event_templates table
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_templates (
+            id SERIAL PRIMARY KEY,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            name VARCHAR(100) NOT NULL,
+            fields JSONB NOT NULL DEFAULT '{}',
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(user_id, name)
+        )
+    `)
+	if err != nil {
+		log.Println("Error creating event_templates table: ", err)
+		return err
+	}
+
+	// Add cover images to events
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS cover_image_key TEXT NOT NULL DEFAULT ''
+    `)
+	if err != nil {
+		log.Println("Error adding cover images to events table: ", err)
+		return err
+	}
+
+	// Create event_attachments table
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_attachments (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            file_name VARCHAR(255) NOT NULL,
+            content_type VARCHAR(255) NOT NULL,
+            size_bytes BIGINT NOT NULL,
+            storage_key TEXT NOT NULL,
+            uploaded_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE INDEX IF NOT EXISTS idx_event_attachments_event_id ON event_attachments(event_id);
+    `)
+	if err != nil {
+		log.Println("Error creating event_attachments table: ", err)
+		return err
+	}
+
+	// Add check-in times to RSVPs
+	_, err = db.Exec(`
+        ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP WITH TIME ZONE
+    `)
+	if err != nil {
+		log.Println("Error adding check-in column to rsvps table: ", err)
+		return err
+	}
+
+	// Create event_sessions table
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_sessions (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            title VARCHAR(255) NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            speaker VARCHAR(255) NOT NULL DEFAULT '',
+            start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+            end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+            room VARCHAR(255) NOT NULL DEFAULT '',
+            track VARCHAR(255) NOT NULL DEFAULT '',
+            capacity INTEGER CHECK (capacity > 0),
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            CHECK (end_time > start_time)
+        );
+        CREATE INDEX IF NOT EXISTS idx_event_sessions_event_id ON event_sessions(event_id, start_time);
+    `)
+	if err != nil {
+		log.Println("Error creating event_sessions table: ", err)
+		return err
+	}
+
+	// Create session_rsvps table
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS session_rsvps (
+            session_id INTEGER NOT NULL REFERENCES event_sessions(id) ON DELETE CASCADE,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY (session_id, user_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_session_rsvps_user_id ON session_rsvps(user_id);
+    `)
+	if err != nil {
+		log.Println("Error creating session_rsvps table: ", err)
+		return err
+	}
+
+	// Create venues and venue_rooms tables
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS venues (
+            id SERIAL PRIMARY KEY,
+            name VARCHAR(255) NOT NULL,
+            address TEXT NOT NULL DEFAULT '',
+            capacity INTEGER CHECK (capacity > 0),
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE IF NOT EXISTS venue_rooms (
+            id SERIAL PRIMARY KEY,
+            venue_id INTEGER NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+            name VARCHAR(255) NOT NULL,
+            capacity INTEGER CHECK (capacity > 0),
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (venue_id, name),
+            UNIQUE (id, venue_id)
+        );
+    `)
+	if err != nil {
+		log.Println("Error creating venues tables: ", err)
+		return err
+	}
+
+	// Add venue bookings to events. The room is checked together with the venue so
+	// it always belongs to it, and deleting the room clears both.
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS venue_id INTEGER REFERENCES venues(id) ON DELETE SET NULL;
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS room_id INTEGER;
+        ALTER TABLE events DROP CONSTRAINT IF EXISTS events_room_id_fkey;
+        ALTER TABLE events ADD CONSTRAINT events_room_id_fkey
+            FOREIGN KEY (room_id, venue_id) REFERENCES venue_rooms(id, venue_id) ON DELETE SET NULL;
+        CREATE INDEX IF NOT EXISTS idx_events_venue_id ON events(venue_id, date) WHERE venue_id IS NOT NULL;
+    `)
+	if err != nil {
+		log.Println("Error adding venue columns to events table: ", err)
+		return err
+	}
+
+	// Create event_questions and rsvp_answers tables
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_questions (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            label VARCHAR(255) NOT NULL,
+            type VARCHAR(20) NOT NULL CHECK (type IN ('text', 'single_choice', 'multiple_choice')),
+            options TEXT[] NOT NULL DEFAULT '{}',
+            required BOOLEAN NOT NULL DEFAULT false,
+            position INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE INDEX IF NOT EXISTS idx_event_questions_event_id ON event_questions(event_id, position);
+        CREATE TABLE IF NOT EXISTS rsvp_answers (
+            rsvp_id INTEGER NOT NULL REFERENCES rsvps(id) ON DELETE CASCADE,
+            question_id INTEGER NOT NULL REFERENCES event_questions(id) ON DELETE CASCADE,
+            text TEXT NOT NULL DEFAULT '',
+            choices TEXT[] NOT NULL DEFAULT '{}',
+            PRIMARY KEY (rsvp_id, question_id)
+        );
+    `)
+	if err != nil {
+		log.Println("Error creating registration question tables: ", err)
+		return err
+	}
+
+	// Add guests to RSVPs and a maximum per RSVP to events
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS max_guests INTEGER NOT NULL DEFAULT 0 CHECK (max_guests >= 0);
+        ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS guests INTEGER NOT NULL DEFAULT 0 CHECK (guests >= 0);
+        ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS guest_names TEXT[] NOT NULL DEFAULT '{}';
+    `)
+	if err != nil {
+		log.Println("Error adding guest columns: ", err)
+		return err
+	}
+
+	// Add RSVP windows to events and the users allowed to RSVP outside them
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS rsvp_opens_at TIMESTAMP WITH TIME ZONE;
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS rsvp_closes_at TIMESTAMP WITH TIME ZONE;
+        CREATE TABLE IF NOT EXISTS rsvp_overrides (
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            granted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY (event_id, user_id)
+        );
+    `)
+	if err != nil {
+		log.Println("Error adding RSVP window columns: ", err)
+		return err
+	}
+
+	// Add approval mode to events and the pending and declined RSVP statuses
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS requires_approval BOOLEAN NOT NULL DEFAULT false;
+        ALTER TABLE rsvps DROP CONSTRAINT IF EXISTS rsvps_status_check;
+        ALTER TABLE rsvps ADD CONSTRAINT rsvps_status_check
+            CHECK (status IN ('going', 'maybe', 'not_going', 'waitlisted', 'pending', 'declined'));
+    `)
+	if err != nil {
+		log.Println("Error adding approval columns: ", err)
+		return err
+	}
+
+	// Create the table for discussion comments on events
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_comments (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            parent_id INTEGER REFERENCES event_comments(id) ON DELETE CASCADE,
+            body TEXT NOT NULL,
+            pinned BOOLEAN NOT NULL DEFAULT false,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            edited_at TIMESTAMP WITH TIME ZONE,
+            deleted_at TIMESTAMP WITH TIME ZONE
+        );
+        CREATE INDEX IF NOT EXISTS idx_event_comments_event_id ON event_comments(event_id, parent_id, created_at);
+        CREATE INDEX IF NOT EXISTS idx_event_comments_parent_id ON event_comments(parent_id);
+    `)
+	if err != nil {
+		log.Println("Error creating event_comments table: ", err)
+		return err
+	}
+
+	// Create the table for announcements organizers send to attendees
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_announcements (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            sent_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            subject VARCHAR(255) NOT NULL,
+            body TEXT NOT NULL,
+            statuses TEXT[] NOT NULL,
+            recipient_count INTEGER NOT NULL DEFAULT 0,
+            delivered_count INTEGER NOT NULL DEFAULT 0,
+            failed_count INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            delivered_at TIMESTAMP WITH TIME ZONE
+        );
+        CREATE INDEX IF NOT EXISTS idx_event_announcements_event_id ON event_announcements(event_id, created_at);
+    `)
+	if err != nil {
+		log.Println("Error creating event_announcements table: ", err)
+		return err
+	}
+
+	// Create the tables for post-event surveys and mark which completed events still need theirs sent
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS surveys_due BOOLEAN NOT NULL DEFAULT false;
+        CREATE TABLE IF NOT EXISTS survey_questions (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            label VARCHAR(255) NOT NULL,
+            type VARCHAR(20) NOT NULL CHECK (type IN ('text', 'single_choice', 'multiple_choice')),
+            options TEXT[] NOT NULL DEFAULT '{}',
+            required BOOLEAN NOT NULL DEFAULT false,
+            position INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE INDEX IF NOT EXISTS idx_survey_questions_event_id ON survey_questions(event_id, position);
+        CREATE TABLE IF NOT EXISTS survey_responses (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+            comment TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (event_id, user_id)
+        );
+        CREATE TABLE IF NOT EXISTS survey_answers (
+            response_id INTEGER NOT NULL REFERENCES survey_responses(id) ON DELETE CASCADE,
+            question_id INTEGER NOT NULL REFERENCES survey_questions(id) ON DELETE CASCADE,
+            text TEXT NOT NULL DEFAULT '',
+            choices TEXT[] NOT NULL DEFAULT '{}',
+            PRIMARY KEY (response_id, question_id)
+        );
+    `)
+	if err != nil {
+		log.Println("Error creating survey tables: ", err)
+		return err
+	}
+
+	// Create the append-only change history of events
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS event_revisions (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            number INTEGER NOT NULL,
+            user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+            changes JSONB NOT NULL DEFAULT '[]',
+            snapshot JSONB NOT NULL,
+            restored_from INTEGER,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (event_id, number)
+        )
+    `)
+	if err != nil {
+		log.Println("Error creating event_revisions table: ", err)
+		return err
+	}
+
+	// Add a version to events that is bumped on every change, for optimistic concurrency
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1
+    `)
+	if err != nil {
+		log.Println("Error adding version column to events table: ", err)
+		return err
+	}
+
+	// Keep deleted events in a trash they can be restored from until they are purged
+	_, err = db.Exec(`
+        ALTER TABLE events ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+        CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events(deleted_at) WHERE deleted_at IS NOT NULL;
+    `)
+	if err != nil {
+		log.Println("Error adding deleted_at column to events table: ", err)
+		return err
+	}
+
 	return nil
 }
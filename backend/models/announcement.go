@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Announcement is a message an organizer emailed to the attendees of an event
+// with one of the given RSVP statuses
+type Announcement struct {
+	ID              int        `json:"id"`
+	EventID         int        `json:"event_id"`
+	SentBy          *int       `json:"sent_by,omitempty"` // nil once the sender's account is deleted
+	SenderFirstName string     `json:"sender_first_name,omitempty"`
+	SenderLastName  string     `json:"sender_last_name,omitempty"`
+	Subject         string     `json:"subject"`
+	Body            string     `json:"body"`
+	Statuses        []string   `json:"statuses"`
+	RecipientCount  int        `json:"recipient_count"`
+	DeliveredCount  int        `json:"delivered_count"`
+	FailedCount     int        `json:"failed_count"`
+	CreatedAt       time.Time  `json:"created_at"`
+	DeliveredAt     *time.Time `json:"delivered_at,omitempty"` // nil while emails are being sent
+}
+
+// AnnouncementRequest represents the data needed to send an announcement
+type AnnouncementRequest struct {
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body"`
+	Statuses []string `json:"statuses"` // defaults to attendees who are going
+}
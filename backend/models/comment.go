@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Comment is a post in an event's discussion. Top-level comments start a thread
+// and carry their replies; replies are never nested further.
+type Comment struct {
+	ID        int        `json:"id"`
+	EventID   int        `json:"event_id"`
+	UserID    int        `json:"user_id"`
+	ParentID  *int       `json:"parent_id,omitempty"` // the top-level comment replied to
+	Body      string     `json:"body"`                // empty once deleted
+	Pinned    bool       `json:"pinned"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	Deleted   bool       `json:"deleted"` // kept as a placeholder while its replies remain
+	Replies   []Comment  `json:"replies,omitempty"`
+}
+
+// CommentRequest represents the data needed to post or edit a comment
+type CommentRequest struct {
+	Body     string `json:"body"`
+	ParentID *int   `json:"parent_id,omitempty"` // set to reply to a comment
+}
@@ -4,37 +4,203 @@ import "time"
 
 // Event represents an event in the system
 type Event struct {
-	ID                 int       `json:"id"`
-	Title              string    `json:"title"`
-	Description        string    `json:"description"`
-	Date               time.Time `json:"date"`
-	Location           string    `json:"location"`
-	UserID             int       `json:"user_id"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
-	OrganizerEmail     string    `json:"organizer_email,omitempty"`
-	OrganizerFirstName string    `json:"organizer_first_name,omitempty"`
-	OrganizerLastName  string    `json:"organizer_last_name,omitempty"`
+	ID                 int         `json:"id"`
+	Title              string      `json:"title"`
+	Description        string      `json:"description"`
+	Date               time.Time   `json:"date"`
+	EndDate            *time.Time  `json:"end_date,omitempty"`
+	TimeZone           string      `json:"timezone"`
+	Location           string      `json:"location"`
+	Latitude           *float64    `json:"latitude,omitempty"`
+	Longitude          *float64    `json:"longitude,omitempty"`
+	RecurrenceRule     string      `json:"recurrence_rule,omitempty"`
+	Capacity           *int        `json:"capacity,omitempty"`
+	MaxGuests          int         `json:"max_guests"`
+	RSVPOpensAt        *time.Time  `json:"rsvp_opens_at,omitempty"`
+	RSVPClosesAt       *time.Time  `json:"rsvp_closes_at,omitempty"`
+	RequiresApproval   bool        `json:"requires_approval"`
+	Visibility         string      `json:"visibility"`
+	Status             string      `json:"status"`
+	PublishAt          *time.Time  `json:"publish_at,omitempty"`
+	CancelledAt        *time.Time  `json:"cancelled_at,omitempty"`
+	CancellationReason string      `json:"cancellation_reason,omitempty"`
+	Tags               []string    `json:"tags"`
+	CoverImageKey      string      `json:"-"`
+	CoverImage         *CoverImage `json:"cover_image,omitempty"`
+	VenueID            *int        `json:"venue_id,omitempty"`
+	RoomID             *int        `json:"room_id,omitempty"`
+	OccurrenceDate     *time.Time  `json:"occurrence_date,omitempty"`
+	Rescheduled        bool        `json:"rescheduled,omitempty"`
+	UserID             int         `json:"user_id"`
+	Role               string      `json:"role,omitempty"` // the requesting user's role on the event
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+	DeletedAt          *time.Time  `json:"deleted_at,omitempty"` // only set for events in the trash
+	PurgeAt            *time.Time  `json:"purge_at,omitempty"`   // when an event in the trash is deleted for good
+	OrganizerEmail     string      `json:"organizer_email,omitempty"`
+	OrganizerFirstName string      `json:"organizer_first_name,omitempty"`
+	OrganizerLastName  string      `json:"organizer_last_name,omitempty"`
 }
 
 // EventWithOrganizer extends Event with organizer information
 type EventWithOrganizer struct {
-	ID                 int       `json:"id"`
-	Title              string    `json:"title"`
-	Description        string    `json:"description"`
-	Date               time.Time `json:"date"`
-	Location           string    `json:"location"`
-	UserID             int       `json:"user_id"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
-	OrganizerFirstName string    `json:"organizer_first_name"`
-	OrganizerLastName  string    `json:"organizer_last_name"`
+	ID                 int               `json:"id"`
+	Title              string            `json:"title"`
+	Description        string            `json:"description"`
+	Date               time.Time         `json:"date"`
+	EndDate            *time.Time        `json:"end_date,omitempty"`
+	TimeZone           string            `json:"timezone"`
+	Location           string            `json:"location"`
+	Latitude           *float64          `json:"latitude,omitempty"`
+	Longitude          *float64          `json:"longitude,omitempty"`
+	RecurrenceRule     string            `json:"recurrence_rule,omitempty"`
+	Capacity           *int              `json:"capacity,omitempty"`
+	MaxGuests          int               `json:"max_guests"`
+	RSVPOpensAt        *time.Time        `json:"rsvp_opens_at,omitempty"`
+	RSVPClosesAt       *time.Time        `json:"rsvp_closes_at,omitempty"`
+	RequiresApproval   bool              `json:"requires_approval"`
+	Visibility         string            `json:"visibility"`
+	Status             string            `json:"status"`
+	PublishAt          *time.Time        `json:"publish_at,omitempty"`
+	CancelledAt        *time.Time        `json:"cancelled_at,omitempty"`
+	CancellationReason string            `json:"cancellation_reason,omitempty"`
+	Tags               []string          `json:"tags"`
+	CoverImageKey      string            `json:"-"`
+	CoverImage         *CoverImage       `json:"cover_image,omitempty"`
+	VenueID            *int              `json:"venue_id,omitempty"`
+	RoomID             *int              `json:"room_id,omitempty"`
+	Venue              *Venue            `json:"venue,omitempty"` // only included for a single event
+	OccurrenceDate     *time.Time        `json:"occurrence_date,omitempty"`
+	Rescheduled        bool              `json:"rescheduled,omitempty"`
+	UserID             int               `json:"user_id"`
+	Role               string            `json:"role,omitempty"` // the requesting user's role on the event
+	Version            int               `json:"version"`        // bumped on every change, used as the event's ETag
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	OrganizerFirstName string            `json:"organizer_first_name"`
+	OrganizerLastName  string            `json:"organizer_last_name"`
+	DistanceKm         *float64          `json:"distance_km,omitempty"` // distance from the search's near point
+	Snippet            string            `json:"snippet,omitempty"`     // search match with <mark> highlights
+	Attachments        []EventAttachment `json:"attachments,omitempty"` // only included for a single event
+	Rank               float64           `json:"-"`                     // search relevance
+}
+
+// Duration returns the length of the event, or zero if it has no end date
+func (e *EventWithOrganizer) Duration() time.Duration {
+	if e.EndDate == nil {
+		return 0
+	}
+	return e.EndDate.Sub(e.Date)
+}
+
+// RestoreEventRequest represents the options for restoring an event from the trash
+type RestoreEventRequest struct {
+	AllowConflicts bool `json:"allow_conflicts,omitempty"` // restore despite overlapping venue bookings
+}
+
+// PurgedEvent is an event permanently deleted from the trash, with the keys of
+// the stored files that have to be deleted with it
+type PurgedEvent struct {
+	ID             int
+	CoverImageKey  string
+	AttachmentKeys []string
 }
 
 // EventRequest represents the data needed to create or update an event
 type EventRequest struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
-	Location    string    `json:"location"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	Date             time.Time  `json:"date"`
+	EndDate          *time.Time `json:"end_date,omitempty"`
+	DurationMinutes  int        `json:"duration_minutes,omitempty"` // alternative to end_date
+	TimeZone         string     `json:"timezone"`                   // IANA zone name, defaults to UTC
+	Location         string     `json:"location"`
+	Latitude         *float64   `json:"latitude,omitempty"` // set together with longitude
+	Longitude        *float64   `json:"longitude,omitempty"`
+	RecurrenceRule   string     `json:"recurrence_rule,omitempty"`   // RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=MO
+	Capacity         *int       `json:"capacity,omitempty"`          // maximum headcount of "going" RSVPs, unlimited when nil
+	MaxGuests        int        `json:"max_guests,omitempty"`        // guests each attendee may bring, none by default
+	RSVPOpensAt      *time.Time `json:"rsvp_opens_at,omitempty"`     // RSVPs are accepted from this time, or right away when nil
+	RSVPClosesAt     *time.Time `json:"rsvp_closes_at,omitempty"`    // RSVPs can be changed until this time, or until the event starts when nil
+	RequiresApproval bool       `json:"requires_approval,omitempty"` // attendees going are held as pending until an organizer approves them
+	Visibility       string     `json:"visibility"`                  // public, unlisted or private; defaults to public
+	Tags             []string   `json:"tags"`                        // tags are left unchanged on update when omitted
+	Status           string     `json:"status,omitempty"`            // draft or published on create, defaults to published
+	PublishAt        *time.Time `json:"publish_at,omitempty"`        // publishes a new event at this time instead
+	VenueID          *int       `json:"venue_id,omitempty"`          // books the whole venue unless room_id is set
+	RoomID           *int       `json:"room_id,omitempty"`
+	AllowConflicts   bool       `json:"allow_conflicts,omitempty"` // save despite overlapping venue bookings
+}
+
+// Lifecycle states of an event
+const (
+	EventStatusDraft     = "draft"     // only visible to the event's organizers
+	EventStatusScheduled = "scheduled" // a draft that is published automatically at publish_at
+	EventStatusPublished = "published"
+	EventStatusCancelled = "cancelled" // kept with its RSVPs, but no longer accepting them
+	EventStatusCompleted = "completed" // set automatically once the event has ended
+)
+
+// IsUnpublished reports whether the event is a draft or still waiting to be published
+func (e *EventWithOrganizer) IsUnpublished() bool {
+	return e.Status == EventStatusDraft || e.Status == EventStatusScheduled
+}
+
+// EventStatusRequest represents the data needed to publish or cancel an event
+type EventStatusRequest struct {
+	PublishAt *time.Time `json:"publish_at,omitempty"` // schedule instead of publishing now
+	Reason    string     `json:"reason,omitempty"`     // why the event was cancelled
+}
+
+// Sort orders for event search
+const (
+	SortByRelevance = "relevance"
+	SortByDate      = "date"
+	SortByDistance  = "distance"
+)
+
+// Coordinates is a point on the map in decimal degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// EventFilter narrows down the events returned by listings and search
+type EventFilter struct {
+	Query        string
+	Location     string
+	SortBy       string // relevance or date
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Tags         []string
+	MatchAllTags bool         // require every tag instead of any of them
+	Near         *Coordinates // only events within RadiusKm of this point
+	RadiusKm     float64
+}
+
+// Occurrence represents a single instance of a recurring event
+type Occurrence struct {
+	OccurrenceDate time.Time  `json:"occurrence_date"` // original start, identifies the occurrence
+	Date           time.Time  `json:"date"`            // actual start after any reschedule
+	EndDate        *time.Time `json:"end_date,omitempty"`
+	Cancelled      bool       `json:"cancelled"`
+	Rescheduled    bool       `json:"rescheduled"`
+}
+
+// OccurrenceException cancels or reschedules a single occurrence of a recurring event
+type OccurrenceException struct {
+	ID             int        `json:"id"`
+	EventID        int        `json:"event_id"`
+	OccurrenceDate time.Time  `json:"occurrence_date"`
+	Cancelled      bool       `json:"cancelled"`
+	NewDate        *time.Time `json:"new_date,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// OccurrenceRequest represents the data needed to cancel, reschedule or restore an occurrence
+type OccurrenceRequest struct {
+	OccurrenceDate time.Time  `json:"occurrence_date"`
+	NewDate        *time.Time `json:"new_date,omitempty"`
+	AllowConflicts bool       `json:"allow_conflicts,omitempty"` // reschedule despite overlapping venue bookings
 }
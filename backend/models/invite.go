@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// EventInvite represents a shareable invite link for a private event
+type EventInvite struct {
+	ID        int        `json:"id"`
+	EventID   int        `json:"event_id"`
+	Nonce     string     `json:"-"`
+	Token     string     `json:"token,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	CreatedBy int        `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// CoverImage links to an event's cover image and its thumbnails
+type CoverImage struct {
+	URL        string            `json:"url"`
+	Thumbnails map[string]string `json:"thumbnails"` // keyed by size, e.g. small and medium
+}
+
+// EventAttachment represents a file such as an agenda or slides attached to an event
+type EventAttachment struct {
+	ID          int       `json:"id"`
+	EventID     int       `json:"event_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	StorageKey  string    `json:"-"`
+	URL         string    `json:"url"`
+	UploadedBy  int       `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Roles a user can hold on an event
+const (
+	RoleOwner        = "owner"
+	RoleCoOrganizer  = "co_organizer"
+	RoleCheckInStaff = "checkin_staff"
+	RoleViewer       = "viewer"
+)
+
+// EventMember represents a user who helps run an event
+type EventMember struct {
+	EventID   int       `json:"event_id"`
+	UserID    int       `json:"user_id"`
+	Role      string    `json:"role"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	InvitedBy *int      `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EventMemberRequest represents the data needed to add a member to an event
+type EventMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
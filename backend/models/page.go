@@ -0,0 +1,7 @@
+package models
+
+// Page is one page of results from a list endpoint
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"` // empty on the last page
+}
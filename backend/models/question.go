@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Types of registration questions
+const (
+	QuestionTypeText           = "text"
+	QuestionTypeSingleChoice   = "single_choice"
+	QuestionTypeMultipleChoice = "multiple_choice"
+)
+
+// EventQuestion is a question an event asks attendees when they RSVP, such as
+// their dietary requirements or T-shirt size, or in its post-event survey
+type EventQuestion struct {
+	ID        int       `json:"id"`
+	EventID   int       `json:"event_id"`
+	Label     string    `json:"label"`
+	Type      string    `json:"type"`    // text, single_choice or multiple_choice
+	Options   []string  `json:"options"` // the choices of choice questions
+	Required  bool      `json:"required"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuestionRequest represents the data needed to create or update a question
+type QuestionRequest struct {
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+	Position *int     `json:"position,omitempty"` // new questions are added last when omitted
+}
+
+// RSVPAnswer is an attendee's answer to a registration or survey question
+type RSVPAnswer struct {
+	QuestionID int      `json:"question_id"`
+	Text       string   `json:"text,omitempty"`    // the answer to a text question
+	Choices    []string `json:"choices,omitempty"` // the options chosen for a choice question
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventRevision is an entry in the append-only change history of an event. It
+// records who changed which fields and the event's details after the change.
+type EventRevision struct {
+	ID           int           `json:"id"`
+	EventID      int           `json:"event_id"`
+	Number       int           `json:"number"`            // counts up from 1 for each event
+	UserID       *int          `json:"user_id,omitempty"` // nil once the author's account is deleted
+	FirstName    string        `json:"first_name,omitempty"`
+	LastName     string        `json:"last_name,omitempty"`
+	Changes      []FieldChange `json:"changes"`                 // empty for the first revision
+	RestoredFrom *int          `json:"restored_from,omitempty"` // the revision number this one restored
+	Snapshot     EventSnapshot `json:"snapshot"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// FieldChange is a field of an event that changed in a revision, with its JSON
+// values before and after. Values that were unset are null.
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// EventSnapshot holds the details of an event that are tracked by its revisions:
+// the fields an organizer can edit
+type EventSnapshot struct {
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	Date             time.Time  `json:"date"`
+	EndDate          *time.Time `json:"end_date"`
+	TimeZone         string     `json:"timezone"`
+	Location         string     `json:"location"`
+	Latitude         *float64   `json:"latitude"`
+	Longitude        *float64   `json:"longitude"`
+	RecurrenceRule   string     `json:"recurrence_rule"`
+	Capacity         *int       `json:"capacity"`
+	MaxGuests        int        `json:"max_guests"`
+	RSVPOpensAt      *time.Time `json:"rsvp_opens_at"`
+	RSVPClosesAt     *time.Time `json:"rsvp_closes_at"`
+	RequiresApproval bool       `json:"requires_approval"`
+	Visibility       string     `json:"visibility"`
+	VenueID          *int       `json:"venue_id"`
+	RoomID           *int       `json:"room_id"`
+	Tags             []string   `json:"tags"`
+}
+
+// Request returns the event update that brings an event back to the snapshot
+func (s EventSnapshot) Request() EventRequest {
+	return EventRequest{
+		Title:            s.Title,
+		Description:      s.Description,
+		Date:             s.Date,
+		EndDate:          s.EndDate,
+		TimeZone:         s.TimeZone,
+		Location:         s.Location,
+		Latitude:         s.Latitude,
+		Longitude:        s.Longitude,
+		RecurrenceRule:   s.RecurrenceRule,
+		Capacity:         s.Capacity,
+		MaxGuests:        s.MaxGuests,
+		RSVPOpensAt:      s.RSVPOpensAt,
+		RSVPClosesAt:     s.RSVPClosesAt,
+		RequiresApproval: s.RequiresApproval,
+		Visibility:       s.Visibility,
+		VenueID:          s.VenueID,
+		RoomID:           s.RoomID,
+		Tags:             append([]string{}, s.Tags...),
+	}
+}
+
+// RestoreRevisionRequest represents the options for restoring an event to a revision
+type RestoreRevisionRequest struct {
+	AllowConflicts bool `json:"allow_conflicts,omitempty"` // restore despite overlapping venue bookings
+}
@@ -4,12 +4,18 @@ import "time"
 
 // RSVP represents an RSVP in the system
 type RSVP struct {
-	ID        int       `json:"id"`
-	EventID   int       `json:"event_id"`
-	UserID    int       `json:"user_id"`
-	Status    string    `json:"status"` // going, maybe, not_going
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             int          `json:"id"`
+	EventID        int          `json:"event_id"`
+	UserID         int          `json:"user_id"`
+	Status         string       `json:"status"` // going, maybe, not_going, waitlisted, pending or declined
+	Guests         int          `json:"guests"` // people the attendee brings along
+	GuestNames     []string     `json:"guest_names,omitempty"`
+	OccurrenceDate *time.Time   `json:"occurrence_date,omitempty"` // only set for recurring events
+	CheckedInAt    *time.Time   `json:"checked_in_at,omitempty"`
+	TicketCode     string       `json:"ticket_code,omitempty"` // only set on the attendee's own "going" RSVP
+	Answers        []RSVPAnswer `json:"answers,omitempty"`     // answers to the event's registration questions
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
 }
 
 // RSVPWithUser extends RSVP with user information
@@ -22,13 +28,64 @@ type RSVPWithUser struct {
 
 // RSVPCount represents the count of RSVPs by status
 type RSVPCount struct {
-	EventID  int `json:"event_id"`
-	Going    int `json:"going"`
-	Maybe    int `json:"maybe"`
-	NotGoing int `json:"not_going"`
+	EventID        int        `json:"event_id"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	Going          int        `json:"going"`     // attendees going, not counting their guests
+	Guests         int        `json:"guests"`    // guests brought by attendees going
+	Headcount      int        `json:"headcount"` // attendees going together with their guests
+	Maybe          int        `json:"maybe"`
+	NotGoing       int        `json:"not_going"`
+	Waitlisted     int        `json:"waitlisted"`
+	Pending        int        `json:"pending"` // waiting for an organizer's approval
+	CheckedIn      int        `json:"checked_in"`
+	Capacity       *int       `json:"capacity,omitempty"`
+	RemainingSeats *int       `json:"remaining_seats,omitempty"`
+}
+
+// WaitlistPromotion lists the users moved from the waitlist of an event occurrence
+// to "going" at once
+type WaitlistPromotion struct {
+	Occurrence *time.Time // nil for one-off events
+	UserIDs    []int
 }
 
 // RSVPRequest represents the data needed to create or update an RSVP
 type RSVPRequest struct {
-	Status string `json:"status"` // going, maybe, not_going
+	Status     string       `json:"status"`           // going, maybe, not_going
+	Guests     *int         `json:"guests,omitempty"` // previous guests are kept when omitted
+	GuestNames []string     `json:"guest_names,omitempty"`
+	Answers    []RSVPAnswer `json:"answers,omitempty"` // previous answers are kept when omitted
+}
+
+// RSVPReviewRequest represents the pending RSVPs an organizer approves or declines at once
+type RSVPReviewRequest struct {
+	RSVPIDs []int  `json:"rsvp_ids"`
+	Message string `json:"message,omitempty"` // included in the email to each attendee
+}
+
+// CheckInRequest represents the ticket code scanned at the door
+type CheckInRequest struct {
+	Code string `json:"code"`
+}
+
+// CheckInResult represents a successful check-in with the updated counts
+type CheckInResult struct {
+	Attendee RSVPWithUser `json:"attendee"`
+	Count    RSVPCount    `json:"count"`
+}
+
+// RSVPOverride lets a user RSVP to an event outside its RSVP window
+type RSVPOverride struct {
+	EventID   int       `json:"event_id"`
+	UserID    int       `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	GrantedBy *int      `json:"granted_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RSVPOverrideRequest represents the data needed to let a user RSVP outside the window
+type RSVPOverrideRequest struct {
+	Email string `json:"email"`
 }
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// EventSession is a talk or workshop within an event, such as one slot of a
+// conference agenda
+type EventSession struct {
+	ID             int       `json:"id"`
+	EventID        int       `json:"event_id"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Speaker        string    `json:"speaker"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	Room           string    `json:"room"`
+	Track          string    `json:"track"`
+	Capacity       *int      `json:"capacity,omitempty"`
+	AttendeeCount  int       `json:"attendee_count"`
+	RemainingSeats *int      `json:"remaining_seats,omitempty"`
+	Attending      bool      `json:"attending"` // whether the requesting user is attending
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SessionRequest represents the data needed to create or update a session
+type SessionRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Speaker     string    `json:"speaker"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Room        string    `json:"room"`
+	Track       string    `json:"track"`
+	Capacity    *int      `json:"capacity,omitempty"`
+}
+
+// Agenda is the schedule of an event's sessions
+type Agenda struct {
+	EventID  int            `json:"event_id"`
+	Tracks   []string       `json:"tracks"`
+	Sessions []EventSession `json:"sessions"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// SurveyResponse is an attendee's feedback on an event they went to
+type SurveyResponse struct {
+	ID        int          `json:"id"`
+	EventID   int          `json:"event_id"`
+	UserID    int          `json:"user_id"`
+	Rating    int          `json:"rating"` // 1 to 5
+	Comment   string       `json:"comment"`
+	Answers   []RSVPAnswer `json:"answers"` // answers to the organizer's survey questions
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// SurveyResponseRequest represents the data needed to submit or update feedback
+type SurveyResponseRequest struct {
+	Rating  int          `json:"rating"`
+	Comment string       `json:"comment"`
+	Answers []RSVPAnswer `json:"answers"`
+}
+
+// Survey is an event's post-event survey as seen by an attendee
+type Survey struct {
+	Questions  []EventQuestion `json:"questions"`
+	Response   *SurveyResponse `json:"response"`    // nil until the attendee responds
+	CanRespond bool            `json:"can_respond"` // the event has ended and the attendee went to it, never for series without an end
+}
+
+// SurveyResults are the aggregated responses to an event's survey
+type SurveyResults struct {
+	EventID       int                     `json:"event_id"`
+	ResponseCount int                     `json:"response_count"`
+	AverageRating *float64                `json:"average_rating"` // nil without responses
+	Ratings       map[int]int             `json:"ratings"`        // number of responses for each rating from 1 to 5
+	Comments      []SurveyComment         `json:"comments"`
+	Questions     []SurveyQuestionResults `json:"questions"`
+}
+
+// SurveyComment is the free-text feedback of a response, shown without the name
+// of the attendee who gave it
+type SurveyComment struct {
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SurveyQuestionResults are the answers given to a survey question
+type SurveyQuestionResults struct {
+	Question    EventQuestion  `json:"question"`
+	AnswerCount int            `json:"answer_count"`
+	Choices     map[string]int `json:"choices,omitempty"` // how often each option was chosen
+	Texts       []string       `json:"texts,omitempty"`   // the answers to a text question
+}
+
+// OrganizerProfile is the public profile of a user who organizes events
+type OrganizerProfile struct {
+	ID            int      `json:"id"`
+	FirstName     string   `json:"first_name"`
+	LastName      string   `json:"last_name"`
+	AverageRating *float64 `json:"average_rating"` // across the surveys of all their events, nil without ratings
+	RatingCount   int      `json:"rating_count"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// Tag represents a label used to categorize events
+type Tag struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	EventCount int    `json:"event_count"`
+}
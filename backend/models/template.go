@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventTemplate is a named set of event fields a user can create new events from
+type EventTemplate struct {
+	ID        int             `json:"id"`
+	UserID    int             `json:"user_id"`
+	Name      string          `json:"name"`
+	Fields    json.RawMessage `json:"fields"` // a partial EventRequest without any dates
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// EventTemplateRequest represents the data needed to create or update an event template
+type EventTemplateRequest struct {
+	Name   string          `json:"name"`
+	Fields json.RawMessage `json:"fields"`
+}
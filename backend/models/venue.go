@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Venue is a place events can be booked at, optionally divided into rooms
+type Venue struct {
+	ID        int         `json:"id"`
+	Name      string      `json:"name"`
+	Address   string      `json:"address"`
+	Capacity  *int        `json:"capacity,omitempty"`
+	Rooms     []VenueRoom `json:"rooms"`
+	UserID    int         `json:"user_id"` // the user who added the venue and manages it
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// VenueRoom is a room of a venue that can be booked on its own
+type VenueRoom struct {
+	ID        int       `json:"id"`
+	VenueID   int       `json:"venue_id"`
+	Name      string    `json:"name"`
+	Capacity  *int      `json:"capacity,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VenueRequest represents the data needed to create or update a venue
+type VenueRequest struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Capacity *int   `json:"capacity,omitempty"`
+}
+
+// VenueRoomRequest represents the data needed to create or update a room
+type VenueRoomRequest struct {
+	Name     string `json:"name"`
+	Capacity *int   `json:"capacity,omitempty"`
+}
+
+// VenueBooking is the time an event, or one occurrence of a recurring event,
+// holds a venue or one of its rooms
+type VenueBooking struct {
+	EventID        int        `json:"event_id,omitempty"` // omitted for events the requester may not see
+	Title          string     `json:"title"`
+	RoomID         *int       `json:"room_id,omitempty"` // nil when the whole venue is booked
+	RoomName       string     `json:"room_name,omitempty"`
+	Start          time.Time  `json:"start"`
+	End            time.Time  `json:"end"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	Visibility     string     `json:"-"`
+	Status         string     `json:"-"`
+	UserID         int        `json:"-"` // the event's owner
+}
+
+// VenueAvailability lists the bookings of a venue, or of one of its rooms,
+// within a date range
+type VenueAvailability struct {
+	VenueID  int            `json:"venue_id"`
+	RoomID   *int           `json:"room_id,omitempty"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Bookings []VenueBooking `json:"bookings"`
+}
@@ -0,0 +1,572 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// maxVersion is the largest symbol version supported, which holds up to 213 bytes
+// at the medium error correction level
+const maxVersion = 10
+
+// quietZone is the number of blank modules drawn around the symbol
+const quietZone = 4
+
+// ErrTooLong is returned when the content does not fit in the largest supported version
+var ErrTooLong = errors.New("content is too long for a QR code")
+
+// Error correction codewords per block and number of blocks for each version at
+// the medium error correction level, indexed by version
+var (
+	eccCodewordsPerBlock = [maxVersion + 1]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	numECCBlocks         = [maxVersion + 1]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+)
+
+// formatLevelBits identifies the medium error correction level in the format information
+const formatLevelBits = 0
+
+// Code is an encoded QR code symbol
+type Code struct {
+	Size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// Encode encodes content in byte mode at the medium error correction level, using
+// the smallest version it fits in
+func Encode(content string) (*Code, error) {
+	c, err := newCode(content)
+	if err != nil {
+		return nil, err
+	}
+	c.setMask(c.bestMask())
+	return c, nil
+}
+
+// newCode draws the function patterns and codewords of a symbol for content,
+// before any mask is applied
+func newCode(content string) (*Code, error) {
+	data := []byte(content)
+
+	version := 0
+	for v := 1; v <= maxVersion; v++ {
+		if 4+countBits(v)+len(data)*8 <= numDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addECCAndInterleave(encodeData(data, version), version)
+
+	size := version*4 + 17
+	c := &Code{
+		Size:     size,
+		modules:  newGrid(size),
+		function: newGrid(size),
+	}
+	c.drawFunctionPatterns(version)
+	c.drawCodewords(codewords)
+
+	return c, nil
+}
+
+// bestMask returns the mask that gives the lowest penalty
+func (c *Code) bestMask() int {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.setMask(mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		c.applyMask(mask) // masks are their own inverse
+	}
+	return best
+}
+
+// setMask applies a mask to the data modules and records it in the format
+// information
+func (c *Code) setMask(mask int) {
+	c.applyMask(mask)
+	c.drawFormatBits(mask)
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Image renders the code with each module drawn as a scale by scale square,
+// surrounded by a quiet zone
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	width := (c.Size + quietZone*2) * scale
+	img := image.NewPaletted(image.Rect(0, 0, width, width), color.Palette{color.White, color.Black})
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG renders the code as a PNG image
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PNG encodes content and renders it as a PNG image
+func PNG(content string, scale int) ([]byte, error) {
+	c, err := Encode(content)
+	if err != nil {
+		return nil, err
+	}
+	return c.PNG(scale)
+}
+
+func newGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+	return grid
+}
+
+// countBits returns the length of the byte mode character count for a version
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// numRawDataModules returns the number of modules available for data and error
+// correction in a version, after the function patterns
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// numDataCodewords returns the number of data codewords a version holds
+func numDataCodewords(version int) int {
+	return numRawDataModules(version)/8 - eccCodewordsPerBlock[version]*numECCBlocks[version]
+}
+
+// encodeData builds the data codewords: the mode, length and content followed by
+// the terminator and padding
+func encodeData(data []byte, version int) []byte {
+	var bits bitBuffer
+	bits.append(0x4, 4) // byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := numDataCodewords(version) * 8
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - uint(i&7))
+		}
+	}
+	return codewords
+}
+
+// addECCAndInterleave splits the data into blocks, appends the error correction
+// codewords of each block and interleaves the blocks
+func addECCAndInterleave(data []byte, version int) []byte {
+	numBlocks := numECCBlocks[version]
+	eccLen := eccCodewordsPerBlock[version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := range blocks {
+		dataLen := shortBlockLen - eccLen
+		if i >= numShortBlocks {
+			dataLen++
+		}
+		block := append([]byte{}, data[k:k+dataLen]...)
+		k += dataLen
+		ecc := reedSolomonRemainder(block, divisor)
+		if i < numShortBlocks {
+			// Keep every block the same length; the placeholder is skipped below
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-eccLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree, without
+// its leading term
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords for a block of data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies two elements of GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns and
+// reserves the format and version information areas
+func (c *Code) drawFunctionPatterns(version int) {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	positions := alignmentPositions(version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the three corners taken by finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(x, y)
+		}
+	}
+
+	c.drawFormatBits(0)
+	c.drawVersion(version)
+}
+
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the row and column centers of the alignment patterns
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	size := version*4 + 17
+
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, size-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// drawFormatBits draws both copies of the format information for a mask
+func (c *Code) drawFormatBits(mask int) {
+	data := formatLevelBits<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	c.setFunction(8, c.Size-8, true) // always dark
+}
+
+// drawVersion draws both copies of the version information for versions 7 and up
+func (c *Code) drawVersion(version int) {
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order, two columns at a time
+// from the bottom right corner
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !c.function[y][x] && i < len(codewords)*8 {
+					c.modules[y][x] = bit(int(codewords[i>>3]), 7-(i&7))
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to read, following the rules of the
+// QR code specification
+func (c *Code) penalty() int {
+	result := 0
+
+	// Runs of five or more modules of the same color, and finder-like patterns
+	for i := 0; i < c.Size; i++ {
+		row := make([]bool, c.Size)
+		col := make([]bool, c.Size)
+		for j := 0; j < c.Size; j++ {
+			row[j] = c.modules[i][j]
+			col[j] = c.modules[j][i]
+		}
+		result += linePenalty(row) + linePenalty(col)
+	}
+
+	// 2x2 blocks of the same color
+	for y := 0; y < c.Size-1; y++ {
+		for x := 0; x < c.Size-1; x++ {
+			dark := c.modules[y][x]
+			if dark == c.modules[y][x+1] && dark == c.modules[y+1][x] && dark == c.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+		}
+	}
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	result += k * 10
+
+	return result
+}
+
+// finderLike is the 1:1:3:1:1 pattern of a finder, which must be preceded or
+// followed by four light modules to be penalized
+var finderLike = []bool{true, false, true, true, true, false, true}
+
+func linePenalty(line []bool) int {
+	result := 0
+
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			result += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike) <= len(line); i++ {
+		matches := true
+		for j, dark := range finderLike {
+			if line[i+j] != dark {
+				matches = false
+				break
+			}
+		}
+		if matches && (lightRun(line, i-4, i) || lightRun(line, i+len(finderLike), i+len(finderLike)+4)) {
+			result += 40
+		}
+	}
+
+	return result
+}
+
+// lightRun reports whether modules from start up to end are light, treating
+// modules outside the symbol as the light quiet zone
+func lightRun(line []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+func bit(x, i int) bool {
+	return (x>>uint(i))&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// bitBuffer is a sequence of bits, most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, bit(value, i))
+	}
+}
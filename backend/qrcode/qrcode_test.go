@@ -0,0 +1,307 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The reference symbols below were generated with github.com/skip2/go-qrcode at
+// the medium error correction level, with "#" for dark and "." for light
+// modules and without the quiet zone. Lowercase content keeps both encoders in
+// byte mode, and the cases cover versions with one and several error correction
+// blocks, alignment patterns and version information. Only the data placement is
+// compared for the mask each reference chose, as encoders may weigh the mask
+// penalties differently and any mask gives a valid symbol.
+var referenceSymbols = []struct {
+	content string
+	version int
+	rows    []string
+}{
+	{
+		content: "hello",
+		version: 1,
+		rows: []string{
+			"#######.......#######",
+			"#.....#..#.##.#.....#",
+			"#.###.#.#.###.#.###.#",
+			"#.###.#.#.#.#.#.###.#",
+			"#.###.#.#.#.#.#.###.#",
+			"#.....#.#..#..#.....#",
+			"#######.#.#.#.#######",
+			"........#.#..........",
+			"#.#####...##..#####..",
+			"###.#..#..#####..##.#",
+			".##.#.#.....#.##.###.",
+			"....##.#...####..##..",
+			".#.#..####..#..#....#",
+			"........###.#..#.#..#",
+			"#######..#.#.#..#.##.",
+			"#.....#.#.#....#####.",
+			"#.###.#.##.#.#..#..#.",
+			"#.###.#.##.#####.#...",
+			"#.###.#.#...#.##..#..",
+			"#.....#..#.####.###..",
+			"#######.#...#...#..#.",
+		},
+	},
+	{
+		content: "https://evently.example/events/42",
+		version: 3,
+		rows: []string{
+			"#######.#.#....##.###.#######",
+			"#.....#.#..#..##.##.#.#.....#",
+			"#.###.#....#####..###.#.###.#",
+			"#.###.#.###.#...####..#.###.#",
+			"#.###.#..#..##.....#..#.###.#",
+			"#.....#....#..#..###..#.....#",
+			"#######.#.#.#.#.#.#.#.#######",
+			"........#..####..##.#........",
+			"#.##.###..#..##.#####.#..#.##",
+			"..##.#.....#...##.###.###...#",
+			"#######.##.#..##.##..#.##.##.",
+			"..#.....###.####..###.###...#",
+			".#..#.##..#.#....#.#.....##..",
+			"#..#.#...#..##...###..#...###",
+			"#######.......#.######.#..###",
+			".#.#.#.#..####....#..#.##..#.",
+			".##.###.#.#.....#.#.##.###.#.",
+			".##.#..#...#.#.##...##.#.###.",
+			"#.#..###...#...#.....##...#..",
+			"..#.......###...###.##....#..",
+			".####.#..##..#...##.#######..",
+			"........#.#.###..####...#####",
+			"#######.#....#..#.###.#.##.#.",
+			"#.....#.#.###..#....#...##.##",
+			"#.###.#..####.#..#..#####.##.",
+			"#.###.#.##.#..##.####...##..#",
+			"#.###.#.##.#.#....#....#..#.#",
+			"#.....#...#.#.....#.#.####.#.",
+			"#######.######..#.###.#....#.",
+		},
+	},
+	{
+		content: "evently ticket: a fairly long payload that needs a bigger symbol version 5",
+		version: 5,
+		rows: []string{
+			"#######.##...####..###...#....#######",
+			"#.....#.#..#..#....#.....##...#.....#",
+			"#.###.#.#..####.#.##.##..#.##.#.###.#",
+			"#.###.#..#.#.#####..##..##..#.#.###.#",
+			"#.###.#.#...#.#...##.#..#.#...#.###.#",
+			"#.....#..#.#..###...####.#..#.#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#.#.#######",
+			".........#..###..####...##.#.........",
+			"#..########.#..........##.#.##..#.###",
+			"###.##.#.####.#.###..#.#...##..####..",
+			"####.###.#.#..###..######.##..#.#...#",
+			".##.....#...##..##.##.#...#####..###.",
+			".#.####.####.#.#.......#..##..#..#..#",
+			"##...#..###...###.#....#..##....##...",
+			".#..#.#..#.###.#..###.#.##.##.##..###",
+			"..###....#......#...#...........###..",
+			"#...###.##...#.#.##.#.#..#.##.#..##..",
+			"...###.#...##.#..#...##....#.#...##..",
+			"##..######..#.#.####...#####.#..#.#.#",
+			"#.#.#..#.##.#####..#..#.###.######.##",
+			".#######...#.#..##.#....#.##.#####.##",
+			"..####.##..#.#.#.###.###.###....##...",
+			".###.##.#..#.#.#....##.##..###.####.#",
+			"#..###.######.#.#.#.....#.#...#.#####",
+			"....###...##.##..#.#...##..##.##...##",
+			"###..#....####....#.##.######...##...",
+			"##.##.#.#.#....#...#....#.##..##.####",
+			"#.#..#..#..##.#.#.#.#.#...##.#..###..",
+			"#..######.#..###..#...#..#.######.##.",
+			"........#..#..##.######.....#...####.",
+			"#######.#..###.######..#..#.#.#.#####",
+			"#.....#.#.##..#.#..##.#.#####...##..#",
+			"#.###.#.#.#...###.##...##.########.##",
+			"#.###.#.#.##.......##.##..##.###..#.#",
+			"#.###.#...#.##...##.#.##..####.##.#.#",
+			"#.....#....##.##.####.....#...#...###",
+			"#######.##.#.##.....#.#.#...#....#..#",
+		},
+	},
+	{
+		content: "evently ticket 8: " + strings.Repeat("abcdefghij", 11),
+		version: 8,
+		rows: []string{
+			"#######..#..##..#.#.....#.....##.#.##...#.#######",
+			"#.....#...#.##...#..######.#.##.....#####.#.....#",
+			"#.###.#.##.######.#.##.##.#####.###....##.#.###.#",
+			"#.###.#.###.....#.##.##.#.#...##.#.###.#..#.###.#",
+			"#.###.#.#.###.##..#..######..###.#.#.#....#.###.#",
+			"#.....#.##..#..#...#..#...#.....#.#.#.#...#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######",
+			"........#..###.#.##...#...###.#.#.....###........",
+			"#.#####..#####.#....#######....#.####.###.#####..",
+			"...###.#...##....###.#####..####...###...##.###..",
+			"...#.##.#.#.#.######.##.#.#......####.###......##",
+			"#.#.##.#.##..###...#......###...#..#..#..#..#..##",
+			"###.###.#..#.##.#.#..####......#.#..#..##.##.##.#",
+			"#.##.#.#.##..##.##.##.####...####...##...#######.",
+			"#...#####....#.#..#.###...###....##.#.#.##...#.##",
+			"######...#####.##..##.#..#.#.#....#.##...##.#..##",
+			"....#####.#.#.#.#...##.##.#.#..#####.####..#.####",
+			"##...#.#..###.#..###...###.###..#.#..#...###.....",
+			"..##.#######..#..##.....#.#.#.####.#..#....#.####",
+			"#.##...###...####.##.....######.###....####.#..#.",
+			"###.####....##.##...#####.#..###.########.##.####",
+			".#...........#....##.#.#.###.##..#...#...##..#...",
+			"#########.##...##.#.#.#####.....###.#.###########",
+			".####...#..#..#...##..#...###.#.#......##...#...#",
+			"#####.#.#.#..###....###.#.#..#.#..####..#.#.###..",
+			"#####...#...###..#.#..#...#####.#...##.##...###..",
+			"#...#####.#..###.##...#####.....#####.###########",
+			"####.#....##.#.##...##..#..##.#.#.....#.#..#.....",
+			"##.##.##..##..##...#.#..#....###....##.##.#.###..",
+			"#..###.#.##..#.......###.#.#.##.#...##.###.#.##..",
+			".###.##..#.#....##.#..#..#.##....####.##.###...##",
+			"##.#.#.####.#.#...#..#.#..#...##.#..#.#.#.......#",
+			".######.#.#.#.##.#..##....##.##.....#..#....###.#",
+			".###.#.....###.##.##.#####....##.#...#..####.....",
+			".....###.##.##.#.#.###..##.#.##.......##..#.#..##",
+			"#.##....#.#..#.#.##..##...#####.##....#.#........",
+			"#.#.#####.#####.##..#.#..#.....#.#.#####.##.#####",
+			".##.##.#...#......##.#.####.#####..###.#.#.#.#...",
+			".#...#####...#######..#..#.#...#.##.#.#.###...###",
+			".###...##...#..##.##.#....#####.###.....#..#...#.",
+			"###...####.####...#.#.#####...##.#.##.###########",
+			"........##.#.###..##.##...#..###...#.#.##...##...",
+			"#######..#.###.#..#####.#.#.....###.#.###.#.#..##",
+			"#.....#.#..######.##.##...###.#.#..#..###...#..##",
+			"#.###.#.#..#.##...#...#####....#.####.#########..",
+			"#.###.#.##.#.##.##.####.....####...###.########.#",
+			"#.###.#.###..#...##.##.###.......####.#..###.##..",
+			"#.....#..###.#.###.####....#.#....#...##.##.#...#",
+			"#######.#..###...##.#...##..#..#######.....######",
+		},
+	},
+	{
+		content: "evently ticket 10: " + strings.Repeat("qrstuvwxyz", 19),
+		version: 10,
+		rows: []string{
+			"#######.#####.###..#..####...##.#....#.####..###..#######",
+			"#.....#.#.##...#..#...#.##..#.#.####.#....#..#.#..#.....#",
+			"#.###.#..###..#.#..#...#.#.#.#..######.#...##.##..#.###.#",
+			"#.###.#.#.#..#.#.#.##..#..#..###...###...##....#..#.###.#",
+			"#.###.#...####.#.#...##...######.#...###....##.#..#.###.#",
+			"#.....#..#..##.##.#..#....#...##.#..#.#..##..##...#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######",
+			"........#.##.#..###...#..##...##..#.#####..#.#.#.........",
+			"#.##.###.#.#....##..##.##.#####...##.#.##.....###.#..#.##",
+			"..###..###...#.#.#####.###.####.#....#.####.#..###.#.####",
+			"##..#.##...#...####.#....###..#..##..#.#..###.##.#.#..###",
+			"#...##.....#####..#..##....#..#.#####..#.#..#.#.#..#.#.#.",
+			"####..##.####..##.#.##.####...##..#####..#.#..##.##..#.#.",
+			"#.......###..#.#.##.##.#...##..#.#..#####..#.#...###.##..",
+			"...##.#.##..####.......#.#.####..#.#..#.####.#.#..#.#.#..",
+			"######..#.#.#...#..####.##..#.######..#.#.###..###..####.",
+			".#.##.#...#........#..#.##.#...##.#....#.#.##.#.#.##..#.#",
+			"##.#.#...#.###..###.#..#....##.#.##.....#..#####....#...#",
+			"##..#####.#......#####.####..#.#..######.#..#.#...####.#.",
+			".###.#.##.#.#.###...#..######..#....#.######..##...##....",
+			".#....##.#......#.#...###.###....#.#..####...####..#####.",
+			"..#....##.#..###....#..##.#..###...###...####..###.######",
+			".#.##.#.#.######.....####.....#.####.#....######.#.##..##",
+			"...###..##.########.####...#....######.#....#.#..#.#.#...",
+			"##..###.###.#.#.#..#..##.##..#.#.####.#....#.###..#..#..#",
+			"###.##.######.######.#....#......#.#.##....###...##.#..#.",
+			".#..#####.##....#.####.##.######.#..#.#..##..#..######...",
+			"....#...##.....###.#.##.###...######.#..#########...###.#",
+			"#...#.#.###..##..###..#.#.#.#.####...###...###.##.#.#.###",
+			"....#...##.##....###..##..#...#..###...###...####...#.#.#",
+			"#...######..#..#..#####..######.#.#####..#.#.########.##.",
+			"#.#......###...####...###...#.##..#.#..#####.#..##.#...#.",
+			"...#####..#.#...#...#.....##.#....##.#.##.....#.#..#.###.",
+			".....#.##......#.#..####........#....#.####.#..#.#....##.",
+			"####..####.####.#.#.#..###.#...####..#....#.#########..#.",
+			"##.###..#...#.###..#..###.#####.#.####......#####.#.##.#.",
+			"...##.#.#.####..##..##.#....#..#...###.#.###..#.#.#.##...",
+			"#.#.....##......#.##..#....#...#.#..###.##.###.##.##...#.",
+			"##....##........#...###...#..#.#.#....#..#####..#........",
+			"####...####...#...####...##..#.##..#.#..#..#####.#######.",
+			"###...##..#.#....#.#.#..#.####.##.....##.####.####.##.#.#",
+			"..###..####...##....#..#.#.##.#####.#....#.####..#.###..#",
+			"..#..###.####.#.###.##.####.###.#.#..#####.#..#.##..#..#.",
+			"..#.##.#.#.#.#.##...###.......##.#..#.####.......#.#....#",
+			"#..#####.##.#..###########.#.#...#.#..#####..#..###...###",
+			"..##.....##.###....#.####.#..###...#.#..#####...#.#..####",
+			"#.#..##.#.#.#.###.##.#.###..#...###..#.#..#..##.####.####",
+			"#####..###.##.#...#####..##..##.#..##..#.#..#.###.####...",
+			"......#####.#......#...#.#######.####.#....#..#.######.#.",
+			"........#.#####...##..#...#...#.##.#.##.....##..#...####.",
+			"#######.###.##.#.#.#..##.##.#.####.#..#####.....#.#.##...",
+			"#.....#.#..#####.#####....#...######..#.#..##...#...###.#",
+			"#.###.#....###..#.#....#.#########...###...###..#####.#.#",
+			"#.###.#.#.....#########...##.#...###...###...#####.#...#.",
+			"#.###.#.#.#.#####.####...#....##..######.#....#####......",
+			"#.....#...##.#..#......###.##..#....#.####.#..######....#",
+			"#######.##.#####.....####..####....#.##.#.....##..#####..",
+		},
+	},
+}
+
+// referenceMask reads the mask a reference symbol was drawn with from its format
+// information
+func referenceMask(rows []string) int {
+	var mask int
+	for _, x := range []int{2, 3, 4} {
+		mask <<= 1
+		if rows[8][x] == '#' {
+			mask |= 1
+		}
+	}
+	return mask ^ 0x5412>>10&7
+}
+
+func TestEncodeMatchesReferenceSymbols(t *testing.T) {
+	for _, ref := range referenceSymbols {
+		code, err := newCode(ref.content)
+		if err != nil {
+			t.Fatalf("newCode(%q): %v", ref.content, err)
+		}
+		code.setMask(referenceMask(ref.rows))
+
+		if want := ref.version*4 + 17; code.Size != want {
+			t.Errorf("newCode(%q): size %d, want %d (version %d)", ref.content, code.Size, want, ref.version)
+			continue
+		}
+
+		differences := 0
+		for y, row := range ref.rows {
+			for x, module := range row {
+				if code.Dark(x, y) != (module == '#') {
+					differences++
+				}
+			}
+		}
+		if differences > 0 {
+			t.Errorf("newCode(%q): %d modules of the version %d symbol differ from the reference", ref.content, differences, ref.version)
+		}
+	}
+}
+
+// TestReedSolomonRemainder checks the error correction codewords of the version
+// 1-M example symbol in Annex I of ISO/IEC 18004, which encodes "01234567"
+func TestReedSolomonRemainder(t *testing.T) {
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+
+	got := reedSolomonRemainder(data, reedSolomonDivisor(len(want)))
+	if !bytes.Equal(got, want) {
+		t.Errorf("reedSolomonRemainder = % X, want % X", got, want)
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	// Version 10 holds at most 213 bytes at the medium level
+	if _, err := Encode(strings.Repeat("a", 213)); err != nil {
+		t.Errorf("Encode of 213 bytes: %v", err)
+	}
+	if _, err := Encode(strings.Repeat("a", 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode of 214 bytes: got %v, want ErrTooLong", err)
+	}
+}
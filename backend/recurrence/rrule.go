@@ -0,0 +1,371 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency values supported from RFC 5545
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// maxPeriods bounds how many periods are walked while expanding a rule so a
+// malformed or very long rule can never spin forever
+const maxPeriods = 10000
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY entry such as MO, 2TU or -1FR
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int // 0 means every matching weekday in the period
+}
+
+// Rule is a parsed RRULE
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// An optional "RRULE:" prefix is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.ToUpper(value), "RRULE:")
+	if value == "" {
+		return nil, errors.New("recurrence rule is empty")
+	}
+
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		switch key {
+		case "FREQ":
+			if val != Daily && val != Weekly && val != Monthly {
+				return nil, fmt.Errorf("unsupported frequency %q", val)
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid interval %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid count %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, s := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(s)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid month day %q", s)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, errors.New("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("recurrence rule requires FREQ")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("recurrence rule cannot have both COUNT and UNTIL")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return nil, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, errors.New("ordinal BYDAY values are only supported with FREQ=MONTHLY")
+		}
+	}
+
+	return rule, nil
+}
+
+func parseUntil(val string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, val); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until %q", val)
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", code)
+	}
+	weekday, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", code)
+	}
+
+	n := 0
+	if prefix := code[:len(code)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday %q", code)
+		}
+	}
+	return WeekdayNum{Weekday: weekday, N: n}, nil
+}
+
+// String returns the normalized RRULE value
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			code := strings.ToUpper(day.Weekday.String()[:2])
+			if day.N != 0 {
+				code = strconv.Itoa(day.N) + code
+			}
+			codes[i] = code
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences of the rule starting at dtstart that fall
+// within [from, to], returning at most limit results when limit is positive.
+// Occurrences keep the wall-clock time of dtstart in dtstart's location.
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var occurrences []time.Time
+	r.walk(dtstart, func(t time.Time) bool {
+		if t.After(to) {
+			return false
+		}
+		if !t.Before(from) {
+			occurrences = append(occurrences, t)
+			if limit > 0 && len(occurrences) >= limit {
+				return false
+			}
+		}
+		return true
+	})
+	return occurrences
+}
+
+// Includes reports whether t is an occurrence of the rule starting at dtstart
+func (r *Rule) Includes(dtstart, t time.Time) bool {
+	found := false
+	r.walk(dtstart, func(candidate time.Time) bool {
+		if candidate.Equal(t) {
+			found = true
+		}
+		return candidate.Before(t)
+	})
+	return found
+}
+
+// End returns an upper bound for the last occurrence of the rule, or false
+// when the series never ends
+func (r *Rule) End(dtstart time.Time) (time.Time, bool) {
+	if r.Until != nil {
+		return *r.Until, true
+	}
+	if r.Count == 0 {
+		return time.Time{}, false
+	}
+
+	last := dtstart
+	r.walk(dtstart, func(t time.Time) bool {
+		last = t
+		return true
+	})
+	return last, true
+}
+
+// walk calls fn with each occurrence in order until fn returns false or the
+// series is exhausted. dtstart is always the first occurrence.
+func (r *Rule) walk(dtstart time.Time, fn func(time.Time) bool) {
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		emitted++
+		if !fn(t) {
+			return false
+		}
+		return r.Count == 0 || emitted < r.Count
+	}
+
+	if !emit(dtstart) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(dtstart, period) {
+			if !t.After(dtstart) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// candidates returns the sorted occurrences within the given period index
+func (r *Rule) candidates(dtstart time.Time, period int) []time.Time {
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	ns := dtstart.Nanosecond()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hh, mm, ss, ns, loc)
+	}
+
+	var result []time.Time
+	switch r.Freq {
+	case Daily:
+		t := at(y, m, d+period*r.Interval)
+		if len(r.ByDay) == 0 || r.matchesWeekday(t.Weekday()) {
+			result = append(result, t)
+		}
+	case Weekly:
+		// Weeks start on Monday
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		weekStart := at(y, m, d-offset+period*r.Interval*7)
+		if len(r.ByDay) == 0 {
+			result = append(result, at(weekStart.Year(), weekStart.Month(), weekStart.Day()+offset))
+			break
+		}
+		for i := 0; i < 7; i++ {
+			t := at(weekStart.Year(), weekStart.Month(), weekStart.Day()+i)
+			if r.matchesWeekday(t.Weekday()) {
+				result = append(result, t)
+			}
+		}
+	case Monthly:
+		first := time.Date(y, m+time.Month(period*r.Interval), 1, 0, 0, 0, 0, loc)
+		year, month := first.Year(), first.Month()
+		daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+
+		days := map[int]bool{}
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, n := range r.ByMonthDay {
+				if n < 0 {
+					n = daysInMonth + n + 1
+				}
+				if n >= 1 && n <= daysInMonth {
+					days[n] = true
+				}
+			}
+		case len(r.ByDay) > 0:
+			for _, wd := range r.ByDay {
+				var matches []int
+				for day := 1; day <= daysInMonth; day++ {
+					if time.Date(year, month, day, 0, 0, 0, 0, loc).Weekday() == wd.Weekday {
+						matches = append(matches, day)
+					}
+				}
+				switch {
+				case wd.N == 0:
+					for _, day := range matches {
+						days[day] = true
+					}
+				case wd.N > 0 && wd.N <= len(matches):
+					days[matches[wd.N-1]] = true
+				case wd.N < 0 && -wd.N <= len(matches):
+					days[matches[len(matches)+wd.N]] = true
+				}
+			}
+		default:
+			if d <= daysInMonth {
+				days[d] = true
+			}
+		}
+
+		for day := range days {
+			result = append(result, at(year, month, day))
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	}
+
+	return result
+}
+
+func (r *Rule) matchesWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour int, loc *time.Location) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, loc)
+}
+
+func mustParse(t *testing.T, value string) *Rule {
+	t.Helper()
+	rule, err := Parse(value)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", value, err)
+	}
+	return rule
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"rrule:freq=weekly;byday=mo,we;interval=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;BYDAY=2TU,-1FR;COUNT=3", "FREQ=MONTHLY;BYDAY=2TU,-1FR;COUNT=3"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1", "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+		{"FREQ=WEEKLY;WKST=MO;UNTIL=20260201T000000Z", "FREQ=WEEKLY;UNTIL=20260201T000000Z"},
+		{"FREQ=DAILY;INTERVAL=1;;", "FREQ=DAILY"},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.value).String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []string{
+		"",
+		"RRULE:",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+	}
+
+	for _, value := range tests {
+		if rule, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %q, want an error", value, rule.String())
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	utc := time.UTC
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []time.Time
+	}{
+		{
+			name:    "weekly on several days",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			dtstart: date(2026, time.January, 5, 9, utc),
+			want: []time.Time{
+				date(2026, time.January, 5, 9, utc),
+				date(2026, time.January, 7, 9, utc),
+				date(2026, time.January, 12, 9, utc),
+				date(2026, time.January, 14, 9, utc),
+			},
+		},
+		{
+			name:    "every other week",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			dtstart: date(2026, time.January, 7, 9, utc),
+			want: []time.Time{
+				date(2026, time.January, 7, 9, utc),
+				date(2026, time.January, 21, 9, utc),
+				date(2026, time.February, 4, 9, utc),
+			},
+		},
+		{
+			name:    "dtstart counts even off the rule",
+			rule:    "FREQ=WEEKLY;BYDAY=MO;COUNT=3",
+			dtstart: date(2026, time.January, 6, 9, utc),
+			want: []time.Time{
+				date(2026, time.January, 6, 9, utc),
+				date(2026, time.January, 12, 9, utc),
+				date(2026, time.January, 19, 9, utc),
+			},
+		},
+		{
+			name:    "second Tuesday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=2TU;COUNT=3",
+			dtstart: date(2026, time.January, 13, 18, utc),
+			want: []time.Time{
+				date(2026, time.January, 13, 18, utc),
+				date(2026, time.February, 10, 18, utc),
+				date(2026, time.March, 10, 18, utc),
+			},
+		},
+		{
+			name:    "last Friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			dtstart: date(2026, time.January, 30, 18, utc),
+			want: []time.Time{
+				date(2026, time.January, 30, 18, utc),
+				date(2026, time.February, 27, 18, utc),
+				date(2026, time.March, 27, 18, utc),
+			},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=4",
+			dtstart: date(2026, time.January, 31, 12, utc),
+			want: []time.Time{
+				date(2026, time.January, 31, 12, utc),
+				date(2026, time.February, 28, 12, utc),
+				date(2026, time.March, 31, 12, utc),
+				date(2026, time.April, 30, 12, utc),
+			},
+		},
+		{
+			name:    "monthly skips months without the day",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: date(2026, time.January, 31, 12, utc),
+			want: []time.Time{
+				date(2026, time.January, 31, 12, utc),
+				date(2026, time.March, 31, 12, utc),
+				date(2026, time.May, 31, 12, utc),
+			},
+		},
+		{
+			name:    "until is inclusive",
+			rule:    "FREQ=DAILY;INTERVAL=2;UNTIL=20260107T090000Z",
+			dtstart: date(2026, time.January, 1, 9, utc),
+			want: []time.Time{
+				date(2026, time.January, 1, 9, utc),
+				date(2026, time.January, 3, 9, utc),
+				date(2026, time.January, 5, 9, utc),
+				date(2026, time.January, 7, 9, utc),
+			},
+		},
+		{
+			name:    "date-only until includes the whole day",
+			rule:    "FREQ=DAILY;UNTIL=20260103",
+			dtstart: date(2026, time.January, 1, 22, utc),
+			want: []time.Time{
+				date(2026, time.January, 1, 22, utc),
+				date(2026, time.January, 2, 22, utc),
+				date(2026, time.January, 3, 22, utc),
+			},
+		},
+		{
+			name:    "wall-clock time kept across a DST change",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: date(2026, time.March, 7, 9, newYork),
+			want: []time.Time{
+				date(2026, time.March, 7, 9, newYork),
+				date(2026, time.March, 8, 9, newYork),
+				date(2026, time.March, 9, 9, newYork),
+			},
+		},
+		{
+			name:    "weekly across the end of DST",
+			rule:    "FREQ=WEEKLY;BYDAY=SA;COUNT=2",
+			dtstart: date(2026, time.October, 31, 19, newYork),
+			want: []time.Time{
+				date(2026, time.October, 31, 19, newYork),
+				date(2026, time.November, 7, 19, newYork),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustParse(t, tt.rule)
+			got := rule.Between(tt.dtstart, tt.dtstart, tt.dtstart.AddDate(1, 0, 0), 0)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Between = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBetweenDSTOffsets(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// 9 AM stays 9 AM local time, so the UTC time moves an hour earlier
+	rule := mustParse(t, "FREQ=DAILY;COUNT=2")
+	dtstart := date(2026, time.March, 7, 9, newYork)
+	got := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 0, 7), 0)
+	if len(got) != 2 {
+		t.Fatalf("Between returned %d occurrences, want 2", len(got))
+	}
+	if want := date(2026, time.March, 7, 14, time.UTC); !got[0].Equal(want) {
+		t.Errorf("first occurrence = %v, want %v", got[0].UTC(), want)
+	}
+	if want := date(2026, time.March, 8, 13, time.UTC); !got[1].Equal(want) {
+		t.Errorf("second occurrence = %v, want %v", got[1].UTC(), want)
+	}
+}
+
+func TestBetweenWindowAndLimit(t *testing.T) {
+	rule := mustParse(t, "FREQ=DAILY")
+	dtstart := date(2026, time.January, 1, 9, time.UTC)
+	from := date(2026, time.January, 10, 9, time.UTC)
+	to := date(2026, time.January, 12, 9, time.UTC)
+
+	if got := rule.Between(dtstart, from, to, 0); len(got) != 3 || !got[0].Equal(from) || !got[2].Equal(to) {
+		t.Errorf("Between(%v, %v) = %v, want 3 days from and to inclusive", from, to, got)
+	}
+	if got := rule.Between(dtstart, from, to, 2); len(got) != 2 {
+		t.Errorf("Between with limit 2 returned %d occurrences", len(got))
+	}
+	if got := rule.Between(dtstart, to.Add(time.Hour), to.Add(2*time.Hour), 0); len(got) != 0 {
+		t.Errorf("Between a window without occurrences = %v, want none", got)
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	rule := mustParse(t, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4")
+	dtstart := date(2026, time.January, 5, 9, time.UTC)
+
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{dtstart, true},
+		{date(2026, time.January, 7, 9, time.UTC), true},
+		{date(2026, time.January, 14, 9, time.UTC), true},
+		{date(2026, time.January, 7, 10, time.UTC), false},
+		{date(2026, time.January, 6, 9, time.UTC), false},
+		{date(2026, time.January, 19, 9, time.UTC), false}, // past COUNT
+		{date(2025, time.December, 31, 9, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := rule.Includes(dtstart, tt.t); got != tt.want {
+			t.Errorf("Includes(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestEnd(t *testing.T) {
+	dtstart := date(2026, time.January, 5, 9, time.UTC)
+
+	end, ok := mustParse(t, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4").End(dtstart)
+	if want := date(2026, time.January, 14, 9, time.UTC); !ok || !end.Equal(want) {
+		t.Errorf("End with COUNT = %v, %v, want %v", end, ok, want)
+	}
+
+	end, ok = mustParse(t, "FREQ=DAILY;UNTIL=20260110T000000Z").End(dtstart)
+	if want := date(2026, time.January, 10, 0, time.UTC); !ok || !end.Equal(want) {
+		t.Errorf("End with UNTIL = %v, %v, want %v", end, ok, want)
+	}
+
+	if _, ok := mustParse(t, "FREQ=DAILY").End(dtstart); ok {
+		t.Error("End without COUNT or UNTIL reported an end")
+	}
+}
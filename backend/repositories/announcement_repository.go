@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// Organizers can send at most AnnouncementLimit announcements for an event in
+// any AnnouncementWindow, so attendees aren't flooded with email
+const (
+	AnnouncementLimit  = 3
+	AnnouncementWindow = time.Hour
+)
+
+// ErrAnnouncementLimit is returned when an event has sent its quota of announcements
+var ErrAnnouncementLimit = errors.New("announcement limit reached")
+
+// announcementSort identifies cursors for announcement listings, which are
+// ordered newest first
+const announcementSort = "announcements"
+
+// AnnouncementRepository handles database operations for the announcements
+// organizers send to attendees
+type AnnouncementRepository struct {
+	DB *sql.DB
+}
+
+func NewAnnouncementRepository(db *sql.DB) *AnnouncementRepository {
+	return &AnnouncementRepository{DB: db}
+}
+
+// CreateAnnouncement records an announcement about to be sent to recipientCount
+// attendees. It returns ErrAnnouncementLimit if the event already sent
+// AnnouncementLimit announcements in the last AnnouncementWindow.
+func (r *AnnouncementRepository) CreateAnnouncement(eventID, userID int, req models.AnnouncementRequest, recipientCount int) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Lock the event so concurrent sends can't both slip under the limit
+	if _, err := tx.Exec("SELECT id FROM events WHERE id = $1 FOR UPDATE", eventID); err != nil {
+		log.Printf("Error locking event: %v", err)
+		return 0, err
+	}
+
+	var recent int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM event_announcements
+		WHERE event_id = $1 AND created_at > $2
+	`, eventID, time.Now().Add(-AnnouncementWindow)).Scan(&recent)
+	if err != nil {
+		log.Printf("Error counting recent announcements: %v", err)
+		return 0, err
+	}
+	if recent >= AnnouncementLimit {
+		return 0, ErrAnnouncementLimit
+	}
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO event_announcements (event_id, sent_by, subject, body, statuses, recipient_count)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, eventID, userID, req.Subject, req.Body, pq.Array(req.Statuses), recipientCount).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating announcement: %v", err)
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// NextAnnouncementAt returns when the event can send its next announcement
+func (r *AnnouncementRepository) NextAnnouncementAt(eventID int) (time.Time, error) {
+	var oldest time.Time
+	err := r.DB.QueryRow(`
+		SELECT created_at FROM event_announcements
+		WHERE event_id = $1 AND created_at > $2
+		ORDER BY created_at DESC
+		OFFSET $3 LIMIT 1
+	`, eventID, time.Now().Add(-AnnouncementWindow), AnnouncementLimit-1).Scan(&oldest)
+	if err == sql.ErrNoRows {
+		return time.Now(), nil
+	}
+	if err != nil {
+		log.Printf("Error getting next announcement time: %v", err)
+		return time.Time{}, err
+	}
+	return oldest.Add(AnnouncementWindow), nil
+}
+
+// RecordDelivery stores how many emails of an announcement were delivered and
+// how many failed once sending has finished
+func (r *AnnouncementRepository) RecordDelivery(announcementID, delivered, failed int) error {
+	_, err := r.DB.Exec(`
+		UPDATE event_announcements
+		SET delivered_count = $1, failed_count = $2, delivered_at = NOW()
+		WHERE id = $3
+	`, delivered, failed, announcementID)
+	if err != nil {
+		log.Printf("Error recording announcement delivery: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetAnnouncementByID gets an announcement of an event
+func (r *AnnouncementRepository) GetAnnouncementByID(eventID, announcementID int) (*models.Announcement, error) {
+	announcement, err := scanAnnouncement(r.DB.QueryRow(`
+		SELECT a.id, a.event_id, a.sent_by, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+			   a.subject, a.body, a.statuses, a.recipient_count, a.delivered_count, a.failed_count,
+			   a.created_at, a.delivered_at
+		FROM event_announcements a
+		LEFT JOIN users u ON a.sent_by = u.id
+		WHERE a.id = $1 AND a.event_id = $2
+	`, announcementID, eventID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting announcement: %v", err)
+		}
+		return nil, err
+	}
+
+	return &announcement, nil
+}
+
+// GetAnnouncements gets a page of an event's announcements, newest first
+func (r *AnnouncementRepository) GetAnnouncements(eventID, limit int, after *Cursor) ([]models.Announcement, *Cursor, error) {
+	if after != nil && after.Sort != announcementSort {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	var afterDate *time.Time
+	var afterID int
+	if after != nil {
+		afterDate, afterID = &after.Date, after.ID
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT a.id, a.event_id, a.sent_by, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+			   a.subject, a.body, a.statuses, a.recipient_count, a.delivered_count, a.failed_count,
+			   a.created_at, a.delivered_at
+		FROM event_announcements a
+		LEFT JOIN users u ON a.sent_by = u.id
+		WHERE a.event_id = $1
+			AND ($2::timestamptz IS NULL OR (a.created_at, a.id) < ($2::timestamptz, $3::integer))
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $4
+	`, eventID, afterDate, afterID, limit+1)
+	if err != nil {
+		log.Printf("Error getting announcements: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	announcements := []models.Announcement{}
+	for rows.Next() {
+		announcement, err := scanAnnouncement(rows)
+		if err != nil {
+			log.Printf("Error scanning announcement row: %v", err)
+			return nil, nil, err
+		}
+		announcements = append(announcements, announcement)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating announcement rows: %v", err)
+		return nil, nil, err
+	}
+
+	if len(announcements) <= limit {
+		return announcements, nil, nil
+	}
+	announcements = announcements[:limit]
+	last := announcements[limit-1]
+	return announcements, &Cursor{Sort: announcementSort, Date: last.CreatedAt, ID: last.ID}, nil
+}
+
+// scanAnnouncement scans a row of event_announcements joined with its sender
+func scanAnnouncement(row rowScanner) (models.Announcement, error) {
+	var announcement models.Announcement
+	err := row.Scan(
+		&announcement.ID,
+		&announcement.EventID,
+		&announcement.SentBy,
+		&announcement.SenderFirstName,
+		&announcement.SenderLastName,
+		&announcement.Subject,
+		&announcement.Body,
+		pq.Array(&announcement.Statuses),
+		&announcement.RecipientCount,
+		&announcement.DeliveredCount,
+		&announcement.FailedCount,
+		&announcement.CreatedAt,
+		&announcement.DeliveredAt,
+	)
+	return announcement, err
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// AnswerRepository handles database operations for the answers attendees give to
+// an event's registration questions when they RSVP
+type AnswerRepository struct {
+	DB *sql.DB
+}
+
+func NewAnswerRepository(db *sql.DB) *AnswerRepository {
+	return &AnswerRepository{DB: db}
+}
+
+// GetAnswers gets the answers given with the RSVPs, keyed by RSVP ID and ordered
+// like the questions
+func (r *AnswerRepository) GetAnswers(rsvpIDs []int) (map[int][]models.RSVPAnswer, error) {
+	answers := map[int][]models.RSVPAnswer{}
+	if len(rsvpIDs) == 0 {
+		return answers, nil
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT a.rsvp_id, a.question_id, a.text, a.choices
+		FROM rsvp_answers a
+		JOIN event_questions q ON q.id = a.question_id
+		WHERE a.rsvp_id = ANY($1)
+		ORDER BY q.position, q.id
+	`, pq.Array(rsvpIDs))
+	if err != nil {
+		log.Printf("Error getting answers: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rsvpID int
+		var answer models.RSVPAnswer
+		if err := rows.Scan(&rsvpID, &answer.QuestionID, &answer.Text, pq.Array(&answer.Choices)); err != nil {
+			log.Printf("Error scanning answer row: %v", err)
+			return nil, err
+		}
+		if len(answer.Choices) == 0 {
+			answer.Choices = nil
+		}
+		answers[rsvpID] = append(answers[rsvpID], answer)
+	}
+
+	return answers, rows.Err()
+}
+
+// setRSVPAnswers replaces the answers stored with an RSVP
+func setRSVPAnswers(tx *sql.Tx, rsvpID int, answers []models.RSVPAnswer) error {
+	if _, err := tx.Exec("DELETE FROM rsvp_answers WHERE rsvp_id = $1", rsvpID); err != nil {
+		log.Printf("Error clearing RSVP answers: %v", err)
+		return err
+	}
+
+	for _, answer := range answers {
+		choices := answer.Choices
+		if choices == nil {
+			choices = []string{}
+		}
+		_, err := tx.Exec(`
+			INSERT INTO rsvp_answers (rsvp_id, question_id, text, choices)
+			VALUES ($1, $2, $3, $4)
+		`, rsvpID, answer.QuestionID, answer.Text, pq.Array(choices))
+		if err != nil {
+			log.Printf("Error saving RSVP answer: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
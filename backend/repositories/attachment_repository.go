@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// AttachmentRepository handles database operations for event attachments
+type AttachmentRepository struct {
+	DB *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{DB: db}
+}
+
+// CreateAttachment stores a new attachment for an event. The event's version is
+// bumped since its attachments are part of it.
+func (r *AttachmentRepository) CreateAttachment(attachment *models.EventAttachment) error {
+	err := r.DB.QueryRow(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE id = $1)
+		INSERT INTO event_attachments (event_id, file_name, content_type, size_bytes, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, attachment.EventID, attachment.FileName, attachment.ContentType, attachment.SizeBytes, attachment.StorageKey, attachment.UploadedBy,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+
+	if err != nil {
+		log.Printf("Error creating attachment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetAttachments gets the attachments of an event in the order they were uploaded
+func (r *AttachmentRepository) GetAttachments(eventID int) ([]models.EventAttachment, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, event_id, file_name, content_type, size_bytes, storage_key, uploaded_by, created_at
+		FROM event_attachments
+		WHERE event_id = $1
+		ORDER BY created_at, id
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting attachments: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []models.EventAttachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			log.Printf("Error scanning attachment row: %v", err)
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+// CountAttachments counts the attachments of an event
+func (r *AttachmentRepository) CountAttachments(eventID int) (int, error) {
+	var count int
+	err := r.DB.QueryRow("SELECT COUNT(*) FROM event_attachments WHERE event_id = $1", eventID).Scan(&count)
+	if err != nil {
+		log.Printf("Error counting attachments: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetAttachmentByID gets a single attachment of an event
+func (r *AttachmentRepository) GetAttachmentByID(eventID, attachmentID int) (*models.EventAttachment, error) {
+	attachment, err := scanAttachment(r.DB.QueryRow(`
+		SELECT id, event_id, file_name, content_type, size_bytes, storage_key, uploaded_by, created_at
+		FROM event_attachments
+		WHERE id = $1 AND event_id = $2
+	`, attachmentID, eventID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting attachment: %v", err)
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// DeleteAttachment deletes an attachment and bumps its event's version
+func (r *AttachmentRepository) DeleteAttachment(attachmentID int) error {
+	_, err := r.DB.Exec(`
+		WITH deleted AS (DELETE FROM event_attachments WHERE id = $1 RETURNING event_id)
+		UPDATE events SET version = version + 1 WHERE id IN (SELECT event_id FROM deleted)
+	`, attachmentID)
+	if err != nil {
+		log.Printf("Error deleting attachment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// scanAttachment scans a row of event_attachments
+func scanAttachment(row rowScanner) (models.EventAttachment, error) {
+	var attachment models.EventAttachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.EventID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.SizeBytes,
+		&attachment.StorageKey,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+	)
+	return attachment, err
+}
@@ -84,22 +84,39 @@ func (r *CalendarRepository) AddEventToCalendar(ctx context.Context, token *oaut
 		return nil, err
 	}
 
+	// Use the event's own time zone so the entry shows the organizer's wall-clock time
+	loc, err := time.LoadLocation(event.TimeZone)
+	if err != nil || event.TimeZone == "" {
+		loc = time.UTC
+	}
+
+	start := event.Date.In(loc)
+	// Add 2 hours by default if no end time is specified
+	end := start.Add(2 * time.Hour)
+	if event.EndDate != nil {
+		end = event.EndDate.In(loc)
+	}
+
 	// Create calendar event
 	calendarEvent := &calendar.Event{
 		Summary:     event.Title,
 		Description: event.Description,
 		Start: &calendar.EventDateTime{
-			DateTime: event.Date.Format(time.RFC3339),
-			TimeZone: "UTC",
+			DateTime: start.Format(time.RFC3339),
+			TimeZone: loc.String(),
 		},
 		End: &calendar.EventDateTime{
-			// Add 2 hours by default if no end time is specified
-			DateTime: event.Date.Add(2 * time.Hour).Format(time.RFC3339),
-			TimeZone: "UTC",
+			DateTime: end.Format(time.RFC3339),
+			TimeZone: loc.String(),
 		},
 		Location: event.Location,
 	}
 
+	// Recurring events are added as a whole series
+	if event.RecurrenceRule != "" {
+		calendarEvent.Recurrence = []string{"RRULE:" + event.RecurrenceRule}
+	}
+
 	// Insert the event
 	calendarEvent, err = srv.Events.Insert("primary", calendarEvent).Do()
 	if err != nil {
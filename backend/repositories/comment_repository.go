@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// CommentRepository handles database operations for event discussion comments
+type CommentRepository struct {
+	DB *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) *CommentRepository {
+	return &CommentRepository{DB: db}
+}
+
+// commentSort identifies cursors for comment listings, which show pinned threads
+// first and then the newest threads
+const commentSort = "comments"
+
+// commentColumns are the columns scanned by scanComment
+const commentColumns = `
+	c.id, c.event_id, c.user_id, c.parent_id, c.body, c.pinned,
+	u.first_name, u.last_name, c.created_at, c.updated_at, c.edited_at, c.deleted_at IS NOT NULL
+`
+
+// CreateComment adds a comment to an event's discussion
+func (r *CommentRepository) CreateComment(eventID, userID int, req models.CommentRequest) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`
+		INSERT INTO event_comments (event_id, user_id, parent_id, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, eventID, userID, req.ParentID, req.Body).Scan(&id)
+
+	if err != nil {
+		log.Printf("Error creating comment: %v", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetCommentByID gets a comment of an event, without its replies
+func (r *CommentRepository) GetCommentByID(eventID, commentID int) (*models.Comment, error) {
+	comment, err := scanComment(r.DB.QueryRow(`
+		SELECT `+commentColumns+`
+		FROM event_comments c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.id = $1 AND c.event_id = $2
+	`, commentID, eventID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting comment: %v", err)
+		}
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// GetComments gets a page of an event's threads, pinned threads first and then
+// the newest, each with its replies oldest first. Deleted comments are left out
+// unless replies to them remain.
+func (r *CommentRepository) GetComments(eventID, limit int, after *Cursor) ([]models.Comment, *Cursor, error) {
+	if after != nil && (after.Sort != commentSort || after.Value == nil) {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	var afterPinned *float64
+	var afterDate interface{}
+	var afterID int
+	if after != nil {
+		afterPinned, afterDate, afterID = after.Value, after.Date, after.ID
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT `+commentColumns+`
+		FROM event_comments c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.event_id = $1 AND c.parent_id IS NULL
+			AND (c.deleted_at IS NULL OR EXISTS (SELECT 1 FROM event_comments reply WHERE reply.parent_id = c.id))
+			AND ($2::double precision IS NULL OR (CASE WHEN c.pinned THEN 1 ELSE 0 END, c.created_at, c.id) < ($2::double precision, $3::timestamptz, $4::integer))
+		ORDER BY c.pinned DESC, c.created_at DESC, c.id DESC
+		LIMIT $5
+	`, eventID, afterPinned, afterDate, afterID, limit+1)
+	if err != nil {
+		log.Printf("Error getting comments: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			log.Printf("Error scanning comment row: %v", err)
+			return nil, nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating comment rows: %v", err)
+		return nil, nil, err
+	}
+
+	var next *Cursor
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[limit-1]
+		pinned := 0.0
+		if last.Pinned {
+			pinned = 1
+		}
+		next = &Cursor{Sort: commentSort, Value: &pinned, Date: last.CreatedAt, ID: last.ID}
+	}
+
+	if err := r.withReplies(comments); err != nil {
+		return nil, nil, err
+	}
+
+	return comments, next, nil
+}
+
+// withReplies fills in the replies to each of the top-level comments
+func (r *CommentRepository) withReplies(comments []models.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(comments))
+	byID := map[int]*models.Comment{}
+	for i := range comments {
+		ids[i] = comments[i].ID
+		comments[i].Replies = []models.Comment{}
+		byID[comments[i].ID] = &comments[i]
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT `+commentColumns+`
+		FROM event_comments c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.parent_id = ANY($1)
+		ORDER BY c.created_at, c.id
+	`, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error getting comment replies: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		reply, err := scanComment(rows)
+		if err != nil {
+			log.Printf("Error scanning comment reply row: %v", err)
+			return err
+		}
+		parent := byID[*reply.ParentID]
+		parent.Replies = append(parent.Replies, reply)
+	}
+
+	return rows.Err()
+}
+
+// UpdateComment replaces the body of a comment and marks it as edited
+func (r *CommentRepository) UpdateComment(commentID int, body string) error {
+	_, err := r.DB.Exec(`
+		UPDATE event_comments
+		SET body = $1, edited_at = NOW(), updated_at = NOW()
+		WHERE id = $2
+	`, body, commentID)
+	if err != nil {
+		log.Printf("Error updating comment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteComment deletes a comment. Threads that still have replies keep their
+// top-level comment as an empty placeholder, and a placeholder is removed along
+// with the last reply to it.
+func (r *CommentRepository) DeleteComment(commentID int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var parentID sql.NullInt64
+	var hasReplies bool
+	err = tx.QueryRow(`
+		SELECT parent_id, EXISTS (SELECT 1 FROM event_comments WHERE parent_id = $1)
+		FROM event_comments
+		WHERE id = $1
+		FOR UPDATE
+	`, commentID).Scan(&parentID, &hasReplies)
+	if err != nil {
+		log.Printf("Error getting comment to delete: %v", err)
+		return err
+	}
+
+	if hasReplies {
+		_, err = tx.Exec(`
+			UPDATE event_comments
+			SET body = '', pinned = false, deleted_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+		`, commentID)
+	} else {
+		_, err = tx.Exec("DELETE FROM event_comments WHERE id = $1", commentID)
+	}
+	if err != nil {
+		log.Printf("Error deleting comment: %v", err)
+		return err
+	}
+
+	// Clear away a deleted thread once its last reply is gone
+	if parentID.Valid {
+		_, err = tx.Exec(`
+			DELETE FROM event_comments c
+			WHERE c.id = $1 AND c.deleted_at IS NOT NULL
+				AND NOT EXISTS (SELECT 1 FROM event_comments reply WHERE reply.parent_id = c.id)
+		`, parentID.Int64)
+		if err != nil {
+			log.Printf("Error deleting emptied comment thread: %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetPinned pins a top-level comment to the top of the discussion or unpins it
+func (r *CommentRepository) SetPinned(commentID int, pinned bool) error {
+	_, err := r.DB.Exec(`
+		UPDATE event_comments
+		SET pinned = $1, updated_at = NOW()
+		WHERE id = $2
+	`, pinned, commentID)
+	if err != nil {
+		log.Printf("Error pinning comment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// scanComment scans a row of commentColumns
+func scanComment(row rowScanner) (models.Comment, error) {
+	var comment models.Comment
+	err := row.Scan(
+		&comment.ID,
+		&comment.EventID,
+		&comment.UserID,
+		&comment.ParentID,
+		&comment.Body,
+		&comment.Pinned,
+		&comment.FirstName,
+		&comment.LastName,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+		&comment.EditedAt,
+		&comment.Deleted,
+	)
+	return comment, err
+}
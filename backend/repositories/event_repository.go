@@ -2,11 +2,20 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"html"
 	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/recurrence"
+	"github.com/lib/pq"
 )
 
 // EventRepository handles database operations for events
@@ -20,10 +29,26 @@ func NewEventRepository(db *sql.DB) *EventRepository {
 
 // CreateEvent creates a new event in the database
 func (r *EventRepository) CreateEvent(event models.EventRequest, userID int) (int, error) {
+	recurrenceEnd, err := recurrenceEnd(event)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting event transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := bookVenue(tx, event, 0); err != nil {
+		return 0, err
+	}
+
 	var id int
-	err := r.DB.QueryRow(
-		"INSERT INTO events (title, description, date, location, user_id) VALUES ($1, $2, $3, $4, $5) RETURNING id",
-		event.Title, event.Description, event.Date, event.Location, userID,
+	err = tx.QueryRow(
+		"INSERT INTO events (title, description, date, end_date, timezone, location, latitude, longitude, recurrence_rule, recurrence_end, capacity, max_guests, rsvp_opens_at, rsvp_closes_at, requires_approval, visibility, status, publish_at, venue_id, room_id, user_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21) RETURNING id",
+		event.Title, event.Description, event.Date, event.EndDate, event.TimeZone, event.Location, event.Latitude, event.Longitude, event.RecurrenceRule, recurrenceEnd, event.Capacity, event.MaxGuests, event.RSVPOpensAt, event.RSVPClosesAt, event.RequiresApproval, event.Visibility, event.Status, event.PublishAt, event.VenueID, event.RoomID, userID,
 	).Scan(&id)
 
 	if err != nil {
@@ -31,18 +56,50 @@ func (r *EventRepository) CreateEvent(event models.EventRequest, userID int) (in
 		return 0, err
 	}
 
+	if err := setEventTags(tx, id, event.Tags); err != nil {
+		return 0, err
+	}
+
+	if _, err := insertRevision(tx, id, userID, []models.FieldChange{}, newSnapshot(event), nil, nil); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing event: %v", err)
+		return 0, err
+	}
+
 	return id, nil
 }
 
-// GetEventsByUserID retrieves all events for a specific user
-func (r *EventRepository) GetEventsByUserID(userID int) ([]models.Event, error) {
-	rows, err := r.DB.Query(
-		"SELECT id, title, description, date, location, user_id, created_at, updated_at FROM events WHERE user_id = $1 ORDER BY date",
-		userID,
-	)
+// GetEventsByUserID retrieves a page of the events a user owns or is a member of,
+// ordered by date. It returns the cursor for the next page when there is one.
+func (r *EventRepository) GetEventsByUserID(userID int, limit int, after *Cursor) ([]models.Event, *Cursor, error) {
+	if after != nil && after.Sort != models.SortByDate {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	where := "(e.user_id = $1 OR m.user_id IS NOT NULL)"
+	args := []interface{}{userID}
+	if after != nil {
+		condition, keysetArgs := eventKeysetCondition(after, "", 2)
+		where += condition
+		args = append(args, keysetArgs...)
+	}
+	args = append(args, limit+1)
+
+	rows, err := r.DB.Query(`
+		SELECT e.id, e.title, e.description, e.date, e.end_date, e.timezone, e.location, e.latitude, e.longitude, e.recurrence_rule, e.capacity, e.max_guests, e.rsvp_opens_at, e.rsvp_closes_at, e.requires_approval, e.visibility,
+			`+eventStatusColumns+`, `+eventTagsColumn+`, e.cover_image_key, e.venue_id, e.room_id, e.user_id,
+			CASE WHEN e.user_id = $1 THEN 'owner' ELSE m.role END, e.created_at, e.updated_at
+		FROM events e
+		LEFT JOIN event_members m ON m.event_id = e.id AND m.user_id = $1
+		WHERE e.deleted_at IS NULL AND (`+where+`)
+		ORDER BY e.date, e.id
+		LIMIT $`+strconv.Itoa(len(args)), args...)
 	if err != nil {
 		log.Printf("Error getting events: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -55,64 +112,123 @@ func (r *EventRepository) GetEventsByUserID(userID int) ([]models.Event, error)
 			&event.Title,
 			&event.Description,
 			&event.Date,
+			&event.EndDate,
+			&event.TimeZone,
 			&event.Location,
+			&event.Latitude,
+			&event.Longitude,
+			&event.RecurrenceRule,
+			&event.Capacity,
+			&event.MaxGuests,
+			&event.RSVPOpensAt,
+			&event.RSVPClosesAt,
+			&event.RequiresApproval,
+			&event.Visibility,
+			&event.Status,
+			&event.PublishAt,
+			&event.CancelledAt,
+			&event.CancellationReason,
+			pq.Array(&event.Tags),
+			&event.CoverImageKey,
+			&event.VenueID,
+			&event.RoomID,
 			&event.UserID,
+			&event.Role,
 			&event.CreatedAt,
 			&event.UpdatedAt,
 		); err != nil {
 			log.Printf("Error scanning event row: %v", err)
-			return nil, err
+			return nil, nil, err
 		}
+		event.Date, event.EndDate = inTimeZone(event.TimeZone, event.Date, event.EndDate)
 		events = append(events, event)
 	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating event rows: %v", err)
+		return nil, nil, err
+	}
 
-	return events, nil
+	if len(events) <= limit {
+		return events, nil, nil
+	}
+	events = events[:limit]
+	last := events[limit-1]
+	return events, &Cursor{Sort: models.SortByDate, Date: last.Date, ID: last.ID}, nil
 }
 
-// GetUpcomingEvents retrieves all upcoming events
-func (r *EventRepository) GetUpcomingEvents() ([]models.Event, error) {
-	rows, err := r.DB.Query(`
-		SELECT e.id, e.title, e.description, e.date, e.location, e.user_id, e.created_at, e.updated_at,
-			   u.first_name, u.last_name
-		FROM events e
-		JOIN users u ON e.user_id = u.id
-		WHERE e.date > NOW()
-		ORDER BY e.date ASC
-		LIMIT 20
-	`)
+// GetUpcomingEvents retrieves a page of upcoming published public events matching the filter's
+// tags, ordered by date and expanding recurring events into their occurrences. It
+// returns the cursor for the next page when there is one.
+func (r *EventRepository) GetUpcomingEvents(filter models.EventFilter, limit int, after *Cursor) ([]models.Event, *Cursor, error) {
+	if after != nil && after.Sort != models.SortByDate {
+		return nil, nil, ErrInvalidCursor
+	}
+	now := time.Now()
+
+	conditions := "e.visibility = 'public' AND " + listedStatusCondition
+	var args []interface{}
+	if len(filter.Tags) > 0 {
+		condition, tagArgs := tagCondition(filter.Tags, filter.MatchAllTags, 1)
+		conditions += condition
+		args = append(args, tagArgs...)
+	}
+
+	oneOffConditions := conditions
+	oneOffArgs := append([]interface{}{}, args...)
+	if after != nil {
+		condition, keysetArgs := eventKeysetCondition(after, "", len(oneOffArgs)+1)
+		oneOffConditions += condition
+		oneOffArgs = append(oneOffArgs, keysetArgs...)
+	}
+	oneOffArgs = append(oneOffArgs, limit+1)
+
+	oneOff, err := r.queryEvents(oneOffConditions+fmt.Sprintf(" AND e.recurrence_rule = '' AND e.date > NOW() ORDER BY e.date ASC, e.id ASC LIMIT $%d", len(oneOffArgs)), oneOffArgs...)
 	if err != nil {
 		log.Printf("Error getting upcoming events: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
-	defer rows.Close()
 
-	events := []models.Event{}
+	series, err := r.queryEvents(conditions+" AND e.recurrence_rule <> '' AND "+seriesActiveCondition("NOW()"), args...)
+	if err != nil {
+		log.Printf("Error getting upcoming recurring events: %v", err)
+		return nil, nil, err
+	}
 
-	for rows.Next() {
-		var event models.EventWithOrganizer
-		if err := rows.Scan(
-			&event.ID,
-			&event.Title,
-			&event.Description,
-			&event.Date,
-			&event.Location,
-			&event.UserID,
-			&event.CreatedAt,
-			&event.UpdatedAt,
-			&event.OrganizerFirstName,
-			&event.OrganizerLastName,
-		); err != nil {
-			log.Printf("Error scanning event row: %v", err)
-			return nil, err
-		}
+	occurrences, err := r.expandOccurrences(series, now, now.Add(occurrenceHorizon), limit+1, models.SortByDate, after)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	upcoming, next := paginate(append(oneOff, occurrences...), models.SortByDate, limit)
+
+	events := []models.Event{}
+	for _, event := range upcoming {
 		// Convert EventWithOrganizer to Event
 		eventModel := models.Event{
 			ID:                 event.ID,
 			Title:              event.Title,
 			Description:        event.Description,
 			Date:               event.Date,
+			EndDate:            event.EndDate,
+			TimeZone:           event.TimeZone,
 			Location:           event.Location,
+			Latitude:           event.Latitude,
+			Longitude:          event.Longitude,
+			RecurrenceRule:     event.RecurrenceRule,
+			Capacity:           event.Capacity,
+			MaxGuests:          event.MaxGuests,
+			RSVPOpensAt:        event.RSVPOpensAt,
+			RSVPClosesAt:       event.RSVPClosesAt,
+			RequiresApproval:   event.RequiresApproval,
+			Visibility:         event.Visibility,
+			Status:             event.Status,
+			PublishAt:          event.PublishAt,
+			Tags:               event.Tags,
+			CoverImageKey:      event.CoverImageKey,
+			VenueID:            event.VenueID,
+			RoomID:             event.RoomID,
+			OccurrenceDate:     event.OccurrenceDate,
+			Rescheduled:        event.Rescheduled,
 			UserID:             event.UserID,
 			CreatedAt:          event.CreatedAt,
 			UpdatedAt:          event.UpdatedAt,
@@ -123,30 +239,17 @@ func (r *EventRepository) GetUpcomingEvents() ([]models.Event, error) {
 		events = append(events, eventModel)
 	}
 
-	return events, nil
+	return events, next, nil
 }
 
 // GetEventByID retrieves a single event by ID with organizer information
 func (r *EventRepository) GetEventByID(id int) (*models.EventWithOrganizer, error) {
-	var event models.EventWithOrganizer
-	err := r.DB.QueryRow(`
-		SELECT e.id, e.title, e.description, e.date, e.location, e.user_id, e.created_at, e.updated_at,
-			   u.first_name, u.last_name
+	event, err := scanEventWithOrganizer(r.DB.QueryRow(`
+		SELECT `+eventColumns+`
 		FROM events e
 		JOIN users u ON e.user_id = u.id
-		WHERE e.id = $1
-	`, id).Scan(
-		&event.ID,
-		&event.Title,
-		&event.Description,
-		&event.Date,
-		&event.Location,
-		&event.UserID,
-		&event.CreatedAt,
-		&event.UpdatedAt,
-		&event.OrganizerFirstName,
-		&event.OrganizerLastName,
-	)
+		WHERE e.id = $1 AND e.deleted_at IS NULL
+	`, id))
 
 	if err != nil {
 		log.Printf("Error getting event by ID: %v", err)
@@ -156,9 +259,16 @@ func (r *EventRepository) GetEventByID(id int) (*models.EventWithOrganizer, erro
 	return &event, nil
 }
 
-// DeleteEvent deletes an event by ID
+// trashSort identifies cursors for the trash, which is ordered by most recently deleted
+const trashSort = "trash"
+
+// DeleteEvent moves an event to the trash. It is left out of every query until
+// it is restored, and keeps its RSVPs and files until it is purged.
 func (r *EventRepository) DeleteEvent(eventID int) error {
-	_, err := r.DB.Exec("DELETE FROM events WHERE id = $1", eventID)
+	_, err := r.DB.Exec(`
+		UPDATE events SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`, eventID)
 	if err != nil {
 		log.Printf("Error deleting event: %v", err)
 		return err
@@ -166,96 +276,1467 @@ func (r *EventRepository) DeleteEvent(eventID int) error {
 	return nil
 }
 
-// UpdateEvent updates an existing event
-func (r *EventRepository) UpdateEvent(eventID int, event models.EventRequest) error {
-	_, err := r.DB.Exec(
-		"UPDATE events SET title = $1, description = $2, date = $3, location = $4, updated_at = NOW() WHERE id = $5",
-		event.Title, event.Description, event.Date, event.Location, eventID,
-	)
+// GetDeletedEvents gets a page of the events a user owns that are in the trash,
+// most recently deleted first
+func (r *EventRepository) GetDeletedEvents(userID, limit int, after *Cursor) ([]models.Event, *Cursor, error) {
+	if after != nil && after.Sort != trashSort {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	var afterDate *time.Time
+	var afterID int
+	if after != nil {
+		afterDate, afterID = &after.Date, after.ID
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT e.id, e.title, e.description, e.date, e.end_date, e.timezone, e.location, e.visibility, e.status,
+			   `+eventTagsColumn+`, e.cover_image_key, e.user_id, e.created_at, e.updated_at, e.deleted_at
+		FROM events e
+		WHERE e.user_id = $1 AND e.deleted_at IS NOT NULL
+			AND ($2::timestamptz IS NULL OR (e.deleted_at, e.id) < ($2::timestamptz, $3::integer))
+		ORDER BY e.deleted_at DESC, e.id DESC
+		LIMIT $4
+	`, userID, afterDate, afterID, limit+1)
 	if err != nil {
-		log.Printf("Error updating event: %v", err)
-		return err
+		log.Printf("Error getting deleted events: %v", err)
+		return nil, nil, err
 	}
-	return nil
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(
+			&event.ID,
+			&event.Title,
+			&event.Description,
+			&event.Date,
+			&event.EndDate,
+			&event.TimeZone,
+			&event.Location,
+			&event.Visibility,
+			&event.Status,
+			pq.Array(&event.Tags),
+			&event.CoverImageKey,
+			&event.UserID,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+			&event.DeletedAt,
+		); err != nil {
+			log.Printf("Error scanning deleted event row: %v", err)
+			return nil, nil, err
+		}
+		event.Date, event.EndDate = inTimeZone(event.TimeZone, event.Date, event.EndDate)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating deleted event rows: %v", err)
+		return nil, nil, err
+	}
+
+	if len(events) <= limit {
+		return events, nil, nil
+	}
+	events = events[:limit]
+	last := events[limit-1]
+	return events, &Cursor{Sort: trashSort, Date: *last.DeletedAt, ID: last.ID}, nil
 }
 
-// SearchEvents searches for events based on title, location, and date range
-func (r *EventRepository) SearchEvents(query string, location string, startDate, endDate *time.Time) ([]models.EventWithOrganizer, error) {
-	// Build the query dynamically based on provided filters
-	queryBuilder := `
-		SELECT e.id, e.title, e.description, e.date, e.location, e.user_id, e.created_at, e.updated_at,
-			   u.first_name, u.last_name
+// GetDeletedEvent gets an event the user deleted that is still in the trash
+func (r *EventRepository) GetDeletedEvent(eventID, userID int) (*models.EventWithOrganizer, error) {
+	event, err := scanEventWithOrganizer(r.DB.QueryRow(`
+		SELECT `+eventColumns+`
 		FROM events e
 		JOIN users u ON e.user_id = u.id
-		WHERE 1=1
-	`
-	var args []interface{}
-	argPosition := 1
+		WHERE e.id = $1 AND e.user_id = $2 AND e.deleted_at IS NOT NULL
+	`, eventID, userID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting deleted event: %v", err)
+		}
+		return nil, err
+	}
+	return &event, nil
+}
 
-	// Add title search if query is provided
-	if query != "" {
-		queryBuilder += fmt.Sprintf(" AND e.title ILIKE $%d", argPosition)
-		args = append(args, "%"+query+"%")
-		argPosition++
+// RestoreEvent takes an event its owner deleted out of the trash, failing with a
+// VenueConflictError if its venue was booked in the meantime unless allowConflicts
+// is set. It returns sql.ErrNoRows if the user has no such event in the trash.
+func (r *EventRepository) RestoreEvent(eventID, userID int, allowConflicts bool) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
 	}
+	defer tx.Rollback()
 
-	// Add location filter if provided
-	if location != "" {
-		queryBuilder += fmt.Sprintf(" AND e.location ILIKE $%d", argPosition)
-		args = append(args, "%"+location+"%")
-		argPosition++
+	booking := models.EventRequest{AllowConflicts: allowConflicts}
+	var status string
+	err = tx.QueryRow(`
+		SELECT date, end_date, timezone, recurrence_rule, venue_id, room_id, status
+		FROM events
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+		FOR UPDATE
+	`, eventID, userID).Scan(&booking.Date, &booking.EndDate, &booking.TimeZone, &booking.RecurrenceRule, &booking.VenueID, &booking.RoomID, &status)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting deleted event: %v", err)
+		}
+		return err
 	}
 
-	// Add date range filters if provided
-	if startDate != nil {
-		queryBuilder += fmt.Sprintf(" AND e.date >= $%d", argPosition)
-		args = append(args, startDate)
-		argPosition++
+	// Cancelled events don't hold their venue
+	if status != models.EventStatusCancelled {
+		if err := bookVenue(tx, booking, eventID); err != nil {
+			return err
+		}
 	}
 
-	if endDate != nil {
-		queryBuilder += fmt.Sprintf(" AND e.date <= $%d", argPosition)
-		args = append(args, endDate)
-		argPosition++
+	if _, err := tx.Exec(`
+		UPDATE events SET deleted_at = NULL, version = version + 1, updated_at = NOW()
+		WHERE id = $1
+	`, eventID); err != nil {
+		log.Printf("Error restoring event: %v", err)
+		return err
 	}
 
-	// Only show future events by default if no date filters are provided
-	if startDate == nil && endDate == nil {
-		queryBuilder += " AND e.date >= NOW()"
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing event restore: %v", err)
+		return err
 	}
+	return nil
+}
 
-	// Order by date
-	queryBuilder += " ORDER BY e.date ASC LIMIT 100"
+// PurgeDeletedEvents permanently deletes the events that were moved to the trash
+// before the given time, along with their RSVPs and everything else that belongs
+// to them. It returns the purged events so their stored files can be removed.
+func (r *EventRepository) PurgeDeletedEvents(deletedBefore time.Time) ([]models.PurgedEvent, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	// Execute the query
-	rows, err := r.DB.Query(queryBuilder, args...)
+	// Attachment rows are removed with the events, so look up their files first
+	rows, err := tx.Query(`
+		SELECT e.id, e.cover_image_key,
+			   COALESCE((SELECT array_agg(a.storage_key) FROM event_attachments a WHERE a.event_id = e.id), '{}')
+		FROM events e
+		WHERE e.deleted_at < $1
+		FOR UPDATE OF e
+	`, deletedBefore)
 	if err != nil {
-		log.Printf("Error searching events: %v", err)
+		log.Printf("Error getting events to purge: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	events := []models.EventWithOrganizer{}
-
+	purged := []models.PurgedEvent{}
+	ids := []int64{}
 	for rows.Next() {
-		var event models.EventWithOrganizer
-		if err := rows.Scan(
-			&event.ID,
-			&event.Title,
-			&event.Description,
-			&event.Date,
-			&event.Location,
-			&event.UserID,
-			&event.CreatedAt,
-			&event.UpdatedAt,
-			&event.OrganizerFirstName,
-			&event.OrganizerLastName,
-		); err != nil {
-			log.Printf("Error scanning event row: %v", err)
+		var event models.PurgedEvent
+		if err := rows.Scan(&event.ID, &event.CoverImageKey, pq.Array(&event.AttachmentKeys)); err != nil {
+			log.Printf("Error scanning event to purge: %v", err)
 			return nil, err
 		}
-		events = append(events, event)
+		purged = append(purged, event)
+		ids = append(ids, int64(event.ID))
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating events to purge: %v", err)
+		return nil, err
+	}
+	rows.Close()
+
+	if len(purged) == 0 {
+		return purged, nil
+	}
+
+	if _, err := tx.Exec("DELETE FROM events WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		log.Printf("Error purging deleted events: %v", err)
+		return nil, err
+	}
+
+	return purged, tx.Commit()
+}
+
+// ErrVersionMismatch is returned when an event has changed since the version an
+// update was based on
+var ErrVersionMismatch = errors.New("event version mismatch")
+
+// ErrScheduleLocked is returned when an update would move or drop occurrences of
+// an event that RSVPs or occurrence exceptions are tied to
+var ErrScheduleLocked = errors.New("event schedule is locked by RSVPs or exceptions")
+
+// UpdateEvent updates an existing event on behalf of a user and records the
+// changed fields in the event's history. The update only applies while the event
+// is at the given version, or at any version when it is 0, and fails with
+// ErrVersionMismatch otherwise. It returns the event's new version, the number of
+// the new revision, or 0 when nothing changed, and the users promoted from the
+// waitlist when the capacity was raised or removed.
+func (r *EventRepository) UpdateEvent(eventID, userID, version int, event models.EventRequest) (int, int, []models.WaitlistPromotion, error) {
+	return r.updateEvent(eventID, userID, version, event, nil)
+}
+
+// RestoreRevision updates an event back to the details of one of its revisions,
+// given as an event request, and records the restore as a new revision. The
+// version is checked like in UpdateEvent.
+func (r *EventRepository) RestoreRevision(eventID, userID, number, version int, event models.EventRequest) (int, int, []models.WaitlistPromotion, error) {
+	return r.updateEvent(eventID, userID, version, event, &number)
+}
+
+func (r *EventRepository) updateEvent(eventID, userID, version int, event models.EventRequest, restoredFrom *int) (int, int, []models.WaitlistPromotion, error) {
+	recurrenceEnd, err := recurrenceEnd(event)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting event transaction: %v", err)
+		return 0, 0, nil, err
+	}
+	defer tx.Rollback()
+
+	locked, err := lockEvent(tx, eventID)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if version != 0 && locked.version != version {
+		return 0, 0, nil, ErrVersionMismatch
+	}
+
+	if err := bookVenue(tx, event, eventID); err != nil {
+		return 0, 0, nil, err
+	}
+
+	// RSVPs and exceptions of a series are keyed by occurrence date, so they would
+	// be stranded if their occurrences moved
+	if scheduleChanged(locked.snapshot, event) {
+		if err := checkOccurrencesKept(tx, eventID, event); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	var newVersion int
+	err = tx.QueryRow(
+		"UPDATE events SET title = $1, description = $2, date = $3, end_date = $4, timezone = $5, location = $6, latitude = $7, longitude = $8, recurrence_rule = $9, recurrence_end = $10, capacity = $11, max_guests = $12, rsvp_opens_at = $13, rsvp_closes_at = $14, requires_approval = $15, visibility = $16, venue_id = $17, room_id = $18, version = version + 1, updated_at = NOW() WHERE id = $19 RETURNING version",
+		event.Title, event.Description, event.Date, event.EndDate, event.TimeZone, event.Location, event.Latitude, event.Longitude, event.RecurrenceRule, recurrenceEnd, event.Capacity, event.MaxGuests, event.RSVPOpensAt, event.RSVPClosesAt, event.RequiresApproval, event.Visibility, event.VenueID, event.RoomID, eventID,
+	).Scan(&newVersion)
+	if err != nil {
+		log.Printf("Error updating event: %v", err)
+		return 0, 0, nil, err
+	}
+
+	// Tags are only replaced when the request includes them
+	if event.Tags != nil {
+		if err := setEventTags(tx, eventID, event.Tags); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	// New seats go to the waitlist straight away
+	var promotions []models.WaitlistPromotion
+	if seatsAdded(locked.snapshot.Capacity, event.Capacity) {
+		capacity := sql.NullInt64{}
+		if event.Capacity != nil {
+			capacity = sql.NullInt64{Int64: int64(*event.Capacity), Valid: true}
+		}
+		if promotions, err = promoteAllWaitlisted(tx, eventID, capacity); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	revision, err := recordUpdate(tx, eventID, userID, locked, event, restoredFrom)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing event update: %v", err)
+		return 0, 0, nil, err
+	}
+	return newVersion, revision, promotions, nil
+}
+
+// scheduleChanged reports whether an update changes the dates of an event's
+// occurrences, including turning a one-off event into a series or back
+func scheduleChanged(before models.EventSnapshot, after models.EventRequest) bool {
+	if before.RecurrenceRule != after.RecurrenceRule {
+		return true
+	}
+	if before.RecurrenceRule == "" {
+		return false
+	}
+	return !before.Date.Equal(after.Date.Truncate(time.Microsecond)) || before.TimeZone != after.TimeZone
+}
+
+// checkOccurrencesKept checks that every occurrence RSVPs or exceptions of an event
+// are tied to is still an occurrence under the event's new schedule, and returns
+// ErrScheduleLocked otherwise
+func checkOccurrencesKept(tx *sql.Tx, eventID int, event models.EventRequest) error {
+	var rule *recurrence.Rule
+	if event.RecurrenceRule != "" {
+		var err error
+		if rule, err = recurrence.Parse(event.RecurrenceRule); err != nil {
+			return err
+		}
+	}
+	start, _ := inTimeZone(event.TimeZone, event.Date, nil)
+
+	rows, err := tx.Query(`
+		SELECT occurrence_date FROM rsvps WHERE event_id = $1
+		UNION
+		SELECT occurrence_date FROM event_occurrence_exceptions WHERE event_id = $1
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting tied occurrences: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var occurrence *time.Time
+		if err := rows.Scan(&occurrence); err != nil {
+			log.Printf("Error scanning tied occurrence: %v", err)
+			return err
+		}
+
+		// One-off events only have RSVPs without an occurrence, and series only
+		// those with one
+		if rule == nil {
+			if occurrence != nil {
+				return ErrScheduleLocked
+			}
+		} else if occurrence == nil || !rule.Includes(start, *occurrence) {
+			return ErrScheduleLocked
+		}
+	}
+	return rows.Err()
+}
+
+// seatsAdded reports whether a change of capacity made room for more attendees
+func seatsAdded(before, after *int) bool {
+	if before == nil {
+		return false
+	}
+	return after == nil || *after > *before
+}
+
+// SetCoverImage sets or clears the cover image of an event and returns the key of
+// the image it replaced, if any
+func (r *EventRepository) SetCoverImage(eventID int, key string) (string, error) {
+	var previous string
+	err := r.DB.QueryRow(`
+		UPDATE events SET cover_image_key = $1, version = version + 1, updated_at = NOW()
+		FROM (SELECT cover_image_key FROM events WHERE id = $2 AND deleted_at IS NULL FOR UPDATE) old
+		WHERE events.id = $2
+		RETURNING old.cover_image_key
+	`, key, eventID).Scan(&previous)
+	if err != nil {
+		log.Printf("Error setting cover image: %v", err)
+		return "", err
+	}
+	return previous, nil
+}
+
+// setEventTags replaces the tags of an event, creating any tags that do not exist yet
+func setEventTags(tx *sql.Tx, eventID int, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM event_tags WHERE event_id = $1", eventID); err != nil {
+		log.Printf("Error clearing event tags: %v", err)
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING",
+		pq.Array(tags),
+	); err != nil {
+		log.Printf("Error creating tags: %v", err)
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO event_tags (event_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2)",
+		eventID, pq.Array(tags),
+	); err != nil {
+		log.Printf("Error setting event tags: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ErrInvalidTransition is returned when an event cannot move to the requested state
+var ErrInvalidTransition = errors.New("invalid event status transition")
+
+// PublishEvent publishes a draft or scheduled event, or schedules it to be published
+// at publishAt when that is set. Events that are already live cannot be rescheduled.
+func (r *EventRepository) PublishEvent(eventID int, publishAt *time.Time) error {
+	status := models.EventStatusPublished
+	if publishAt != nil {
+		status = models.EventStatusScheduled
+	}
+
+	return r.setEventStatus(eventID, `
+		UPDATE events SET status = $2, publish_at = $3, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND (status = 'draft' OR (status = 'scheduled' AND publish_at > NOW()))
+	`, status, publishAt)
+}
+
+// UnpublishEvent turns a scheduled event that has not been published yet back into a draft
+func (r *EventRepository) UnpublishEvent(eventID int) error {
+	return r.setEventStatus(eventID, `
+		UPDATE events SET status = 'draft', publish_at = NULL, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND status = 'scheduled' AND publish_at > NOW()
+	`)
+}
+
+// CancelEvent cancels an event that has not ended yet. Its RSVPs are kept so
+// attendees can still see what happened to the event.
+func (r *EventRepository) CancelEvent(eventID int, reason string) error {
+	return r.setEventStatus(eventID, `
+		UPDATE events SET status = 'cancelled', cancelled_at = NOW(), cancellation_reason = $2, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND status IN ('draft', 'scheduled', 'published')
+	`, reason)
+}
+
+// setEventStatus runs a status update whose WHERE clause only matches events in a
+// state the transition is allowed from, returning ErrInvalidTransition otherwise
+func (r *EventRepository) setEventStatus(eventID int, query string, args ...interface{}) error {
+	result, err := r.DB.Exec(query, append([]interface{}{eventID}, args...)...)
+	if err != nil {
+		log.Printf("Error updating event status: %v", err)
+		return err
 	}
 
-	return events, nil
+	updated, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error updating event status: %v", err)
+		return err
+	}
+	if updated == 0 {
+		return ErrInvalidTransition
+	}
+	return nil
+}
+
+// PublishScheduledEvents publishes scheduled events whose publish time has passed.
+// It returns the number of events published.
+func (r *EventRepository) PublishScheduledEvents() (int64, error) {
+	result, err := r.DB.Exec(`
+		UPDATE events SET status = 'published', version = version + 1, updated_at = NOW()
+		WHERE status = 'scheduled' AND publish_at <= NOW() AND deleted_at IS NULL
+	`)
+	if err != nil {
+		log.Printf("Error publishing scheduled events: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CompleteEndedEvents marks published events as completed once they have ended.
+// A recurring event ends with its last occurrence, so series that never end are
+// never completed. Completed events are marked for their post-event survey to be
+// sent. It returns the number of events completed.
+func (r *EventRepository) CompleteEndedEvents() (int64, error) {
+	result, err := r.DB.Exec(`
+		UPDATE events e SET status = 'completed', surveys_due = true, version = version + 1, updated_at = NOW()
+		WHERE e.status = 'published' AND e.deleted_at IS NULL AND (
+			(e.recurrence_rule = '' AND COALESCE(e.end_date, e.date) < NOW()) OR
+			(e.recurrence_rule <> '' AND e.recurrence_end IS NOT NULL
+				AND e.recurrence_end + COALESCE(e.end_date - e.date, INTERVAL '0') < NOW()
+				AND NOT EXISTS (
+					SELECT 1 FROM event_occurrence_exceptions x
+					WHERE x.event_id = e.id AND x.new_date + COALESCE(e.end_date - e.date, INTERVAL '0') >= NOW()
+				))
+		)
+	`)
+	if err != nil {
+		log.Printf("Error completing ended events: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SearchEvents searches published public events based on text, location, tags and date range.
+// The text query is matched with full-text search against the title, description,
+// location and organizer name, and results are ordered by relevance unless the
+// filter asks for date order. An event matches the range if any part of it overlaps
+// the range, and recurring events are expanded into the occurrences that overlap it.
+// It returns a page of at most limit events and the cursor for the next page when there is one.
+func (r *EventRepository) SearchEvents(filter models.EventFilter, limit int, after *Cursor) ([]models.EventWithOrganizer, *Cursor, error) {
+	startDate, endDate := filter.StartDate, filter.EndDate
+
+	// Build the filters shared by one-off events and recurring series
+	conditions := " AND e.visibility = 'public' AND " + listedStatusCondition
+	var args []interface{}
+	argPosition := 1
+
+	// Add full-text search if query is provided. The tsquery is always $1 so the
+	// rank and snippet columns can refer to it.
+	tsQuery := prefixTSQuery(filter.Query)
+	rankColumns := "0, ''"
+	if tsQuery != "" {
+		conditions += " AND e.search_vector @@ to_tsquery('english', $1)"
+		args = append(args, tsQuery)
+		argPosition++
+		rankColumns = searchRankColumns
+	}
+
+	// Add radius filter if a point is provided
+	distanceColumn := "NULL::double precision"
+	if filter.Near != nil {
+		distanceColumn = distanceExpression(argPosition, argPosition+1)
+		args = append(args, filter.Near.Latitude, filter.Near.Longitude)
+		argPosition += 2
+
+		condition, boxArgs := boundingBoxCondition(*filter.Near, filter.RadiusKm, argPosition)
+		conditions += condition
+		args = append(args, boxArgs...)
+		argPosition += len(boxArgs)
+
+		conditions += fmt.Sprintf(" AND %s <= $%d", distanceColumn, argPosition)
+		args = append(args, filter.RadiusKm)
+		argPosition++
+	}
+	extraColumns := rankColumns + ", " + distanceColumn
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		switch {
+		case tsQuery != "":
+			sortBy = models.SortByRelevance
+		case filter.Near != nil:
+			sortBy = models.SortByDistance
+		}
+	}
+	if (sortBy == models.SortByRelevance && tsQuery == "") || (sortBy == models.SortByDistance && filter.Near == nil) {
+		sortBy = models.SortByDate
+	}
+	if sortBy == "" {
+		sortBy = models.SortByDate
+	}
+	if after != nil && after.Sort != sortBy {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	// Non-date sorts order by a value first, ascending like every other key
+	valueExpression := ""
+	switch sortBy {
+	case models.SortByRelevance:
+		valueExpression = "(-" + searchRankExpression + ")::double precision"
+	case models.SortByDistance:
+		valueExpression = distanceColumn
+	}
+
+	// Add location filter if provided
+	if filter.Location != "" {
+		conditions += fmt.Sprintf(" AND e.location ILIKE $%d", argPosition)
+		args = append(args, "%"+filter.Location+"%")
+		argPosition++
+	}
+
+	// Add tag filter if provided
+	if len(filter.Tags) > 0 {
+		condition, tagArgs := tagCondition(filter.Tags, filter.MatchAllTags, argPosition)
+		conditions += condition
+		args = append(args, tagArgs...)
+		argPosition += len(tagArgs)
+	}
+
+	// Only show future events by default if no date filters are provided
+	from := time.Now()
+	to := from.Add(occurrenceHorizon)
+	if startDate != nil || endDate != nil {
+		from = time.Time{}
+		if startDate != nil {
+			from = *startDate
+		}
+		if endDate != nil {
+			to = *endDate
+		}
+	}
+
+	// One-off events are filtered by date directly
+	oneOffConditions := conditions
+	oneOffArgs := append([]interface{}{}, args...)
+	oneOffPosition := argPosition
+	if startDate != nil {
+		oneOffConditions += fmt.Sprintf(" AND COALESCE(e.end_date, e.date) >= $%d", oneOffPosition)
+		oneOffArgs = append(oneOffArgs, startDate)
+		oneOffPosition++
+	}
+
+	if endDate != nil {
+		oneOffConditions += fmt.Sprintf(" AND e.date <= $%d", oneOffPosition)
+		oneOffArgs = append(oneOffArgs, endDate)
+		oneOffPosition++
+	}
+
+	if startDate == nil && endDate == nil {
+		oneOffConditions += " AND COALESCE(e.end_date, e.date) >= NOW()"
+	}
+
+	if after != nil {
+		condition, keysetArgs := eventKeysetCondition(after, valueExpression, oneOffPosition)
+		oneOffConditions += condition
+		oneOffArgs = append(oneOffArgs, keysetArgs...)
+		oneOffPosition += len(keysetArgs)
+	}
+
+	order := " ORDER BY e.date ASC, e.id ASC"
+	if valueExpression != "" {
+		order = " ORDER BY " + valueExpression + " ASC, e.date ASC, e.id ASC"
+	}
+	oneOffArgs = append(oneOffArgs, limit+1)
+
+	oneOff, err := r.querySearchEvents(extraColumns, "e.recurrence_rule = ''"+oneOffConditions+order+fmt.Sprintf(" LIMIT $%d", oneOffPosition), oneOffArgs...)
+	if err != nil {
+		log.Printf("Error searching events: %v", err)
+		return nil, nil, err
+	}
+
+	// Recurring series are kept if any occurrence may still fall in the range
+	seriesConditions := conditions
+	seriesArgs := append([]interface{}{}, args...)
+	seriesConditions += fmt.Sprintf(" AND e.date <= $%d", argPosition)
+	seriesArgs = append(seriesArgs, to)
+	argPosition++
+	seriesConditions += " AND " + seriesActiveCondition(fmt.Sprintf("$%d", argPosition))
+	seriesArgs = append(seriesArgs, from)
+
+	series, err := r.querySearchEvents(extraColumns, "e.recurrence_rule <> ''"+seriesConditions, seriesArgs...)
+	if err != nil {
+		log.Printf("Error searching recurring events: %v", err)
+		return nil, nil, err
+	}
+
+	occurrences, err := r.expandOccurrences(series, from, to, limit+1, sortBy, after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, next := paginate(append(oneOff, occurrences...), sortBy, limit)
+	return events, next, nil
+}
+
+// earthRadiusKm is the mean radius of the Earth used for distances
+const earthRadiusKm = 6371.0
+
+// distanceExpression returns the haversine distance in kilometres, rounded to
+// metres, between an event and the point whose latitude and longitude are in the
+// given placeholders. Rounding errors can push the haversine term just past 1 for
+// nearly antipodal points, so it is capped before ASIN.
+func distanceExpression(latPosition, lngPosition int) string {
+	return fmt.Sprintf(`ROUND((%[3]g * 2 * ASIN(LEAST(1.0, SQRT(
+			POWER(SIN(RADIANS(e.latitude - $%[1]d::double precision) / 2), 2) +
+			COS(RADIANS($%[1]d::double precision)) * COS(RADIANS(e.latitude)) *
+			POWER(SIN(RADIANS(e.longitude - $%[2]d::double precision) / 2), 2)
+		))))::numeric, 3)::double precision`, latPosition, lngPosition, earthRadiusKm)
+}
+
+// boundingBoxCondition narrows a radius search to a box around the point so the
+// coordinates index can be used before distances are computed. The longitude range
+// is skipped near the poles and where the box would cross the antimeridian.
+func boundingBoxCondition(near models.Coordinates, radiusKm float64, argPosition int) (string, []interface{}) {
+	latDelta := radiusKm / (math.Pi * earthRadiusKm / 180)
+	condition := fmt.Sprintf(" AND e.latitude BETWEEN $%d AND $%d", argPosition, argPosition+1)
+	args := []interface{}{near.Latitude - latDelta, near.Latitude + latDelta}
+
+	cosLat := math.Cos(near.Latitude * math.Pi / 180)
+	if cosLat > 0.01 {
+		lngDelta := latDelta / cosLat
+		if near.Longitude-lngDelta >= -180 && near.Longitude+lngDelta <= 180 {
+			condition += fmt.Sprintf(" AND e.longitude BETWEEN $%d AND $%d", argPosition+2, argPosition+3)
+			args = append(args, near.Longitude-lngDelta, near.Longitude+lngDelta)
+		}
+	}
+
+	return condition, args
+}
+
+// searchRankExpression ranks an event against the tsquery in $1
+const searchRankExpression = "ts_rank_cd(e.search_vector, to_tsquery('english', $1))"
+
+// searchRankColumns selects the rank of an event and a snippet of its description,
+// or its title when it has none, with the matches wrapped in snippet markers. Any
+// markers in the text itself are removed first so they can't become highlights.
+const searchRankColumns = searchRankExpression + `, ts_headline('english',
+			translate(CASE WHEN e.description <> '' THEN e.description ELSE e.title END, '` + snippetStart + snippetStop + `', ''),
+			to_tsquery('english', $1),
+			'StartSel="` + snippetStart + `", StopSel="` + snippetStop + `", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "')`
+
+// Markers ts_headline puts around matches. Control characters are used since
+// event text has no use for them, and they are swapped for <mark> tags once the
+// rest of the snippet has been HTML-escaped.
+const (
+	snippetStart = "\x02"
+	snippetStop  = "\x03"
+)
+
+// prefixTSQuery turns free text into a tsquery that matches every word, treating
+// each word as a prefix so partially typed words still match. It returns an empty
+// string when the text has no words.
+func prefixTSQuery(query string) string {
+	words := strings.FieldsFunc(query, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = strings.ToLower(word) + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// highlightSnippet escapes a ts_headline snippet for HTML and marks its matches
+func highlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, snippetStart, "<mark>")
+	return strings.ReplaceAll(snippet, snippetStop, "</mark>")
+}
+
+// querySearchEvents is queryEvents with the rank, snippet and distance selected by extraColumns
+func (r *EventRepository) querySearchEvents(extraColumns, where string, args ...interface{}) ([]models.EventWithOrganizer, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+eventColumns+`, `+extraColumns+`
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		WHERE e.deleted_at IS NULL AND (`+where+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.EventWithOrganizer{}
+
+	for rows.Next() {
+		var rank float64
+		var snippet string
+		var distance *float64
+		event, err := scanEventWithOrganizer(rows, &rank, &snippet, &distance)
+		if err != nil {
+			log.Printf("Error scanning event row: %v", err)
+			return nil, err
+		}
+		event.Rank = rank
+		event.DistanceKm = distance
+		if snippet != "" {
+			event.Snippet = highlightSnippet(snippet)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// Sentinel errors returned when resolving an occurrence of an event
+var (
+	ErrOccurrenceRequired  = errors.New("occurrence is required for recurring events")
+	ErrInvalidOccurrence   = errors.New("invalid occurrence")
+	ErrOccurrenceCancelled = errors.New("occurrence has been cancelled")
+	ErrNoOccurrenceToday   = errors.New("no occurrence takes place today")
+)
+
+// occurrenceHorizon bounds how far ahead open-ended recurring events are expanded
+const occurrenceHorizon = 365 * 24 * time.Hour
+
+// eventTagsColumn selects the names of an event's tags in alphabetical order
+const eventTagsColumn = `COALESCE((SELECT array_agg(t.name ORDER BY t.name) FROM event_tags et JOIN tags t ON t.id = et.tag_id WHERE et.event_id = e.id), '{}')`
+
+// eventStatusColumns selects the lifecycle state of an event. Scheduled events count
+// as published as soon as their publish time passes, even before the lifecycle job
+// has caught up with them.
+const eventStatusColumns = `CASE WHEN e.status = 'scheduled' AND e.publish_at <= NOW() THEN 'published' ELSE e.status END,
+			   e.publish_at, e.cancelled_at, e.cancellation_reason`
+
+// listedStatusCondition matches events in a state that may appear in listings and search
+const listedStatusCondition = `(e.status IN ('published', 'completed') OR (e.status = 'scheduled' AND e.publish_at <= NOW()))`
+
+// eventColumns lists the columns selected for an event joined with its organizer
+const eventColumns = `e.id, e.title, e.description, e.date, e.end_date, e.timezone, e.location, e.latitude, e.longitude, e.recurrence_rule, e.capacity, e.max_guests, e.rsvp_opens_at, e.rsvp_closes_at, e.requires_approval, e.visibility,
+			   ` + eventStatusColumns + `, ` + eventTagsColumn + `, e.cover_image_key, e.venue_id, e.room_id, e.user_id, e.version, e.created_at, e.updated_at,
+			   u.first_name, u.last_name`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// querier runs queries either directly on the database or within a transaction
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// scanEventWithOrganizer scans a row selected with eventColumns, followed by any extra columns
+func scanEventWithOrganizer(row rowScanner, extra ...interface{}) (models.EventWithOrganizer, error) {
+	var event models.EventWithOrganizer
+	dest := []interface{}{
+		&event.ID,
+		&event.Title,
+		&event.Description,
+		&event.Date,
+		&event.EndDate,
+		&event.TimeZone,
+		&event.Location,
+		&event.Latitude,
+		&event.Longitude,
+		&event.RecurrenceRule,
+		&event.Capacity,
+		&event.MaxGuests,
+		&event.RSVPOpensAt,
+		&event.RSVPClosesAt,
+		&event.RequiresApproval,
+		&event.Visibility,
+		&event.Status,
+		&event.PublishAt,
+		&event.CancelledAt,
+		&event.CancellationReason,
+		pq.Array(&event.Tags),
+		&event.CoverImageKey,
+		&event.VenueID,
+		&event.RoomID,
+		&event.UserID,
+		&event.Version,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+		&event.OrganizerFirstName,
+		&event.OrganizerLastName,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	event.Date, event.EndDate = inTimeZone(event.TimeZone, event.Date, event.EndDate)
+	return event, err
+}
+
+// inTimeZone converts event times to the event's IANA time zone so recurrence
+// expansion and JSON output use the organizer's wall-clock time
+func inTimeZone(zone string, date time.Time, endDate *time.Time) (time.Time, *time.Time) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+	if endDate != nil {
+		end := endDate.In(loc)
+		endDate = &end
+	}
+	return date.In(loc), endDate
+}
+
+// queryEvents runs a query for events joined with their organizer using the given WHERE clause
+func (r *EventRepository) queryEvents(where string, args ...interface{}) ([]models.EventWithOrganizer, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+eventColumns+`
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		WHERE e.deleted_at IS NULL AND (`+where+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.EventWithOrganizer{}
+
+	for rows.Next() {
+		event, err := scanEventWithOrganizer(rows)
+		if err != nil {
+			log.Printf("Error scanning event row: %v", err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// seriesActiveCondition matches recurring events that may have an occurrence at or after from
+func seriesActiveCondition(from string) string {
+	return fmt.Sprintf(`(e.recurrence_end IS NULL OR e.recurrence_end >= %[1]s OR EXISTS (
+			SELECT 1 FROM event_occurrence_exceptions x
+			WHERE x.event_id = e.id AND x.new_date >= %[1]s
+		))`, from)
+}
+
+// tagCondition returns a condition matching events tagged with any of the tags,
+// or with all of them when matchAll is set, using placeholders from argPosition
+func tagCondition(tags []string, matchAll bool, argPosition int) (string, []interface{}) {
+	if matchAll {
+		return fmt.Sprintf(` AND (
+			SELECT COUNT(*) FROM event_tags et JOIN tags t ON t.id = et.tag_id
+			WHERE et.event_id = e.id AND t.name = ANY($%d)
+		) = $%d`, argPosition, argPosition+1), []interface{}{pq.Array(tags), len(tags)}
+	}
+
+	return fmt.Sprintf(` AND EXISTS (
+		SELECT 1 FROM event_tags et JOIN tags t ON t.id = et.tag_id
+		WHERE et.event_id = e.id AND t.name = ANY($%d)
+	)`, argPosition), []interface{}{pq.Array(tags)}
+}
+
+// recurrenceEnd returns the stored upper bound of a recurring event, or nil when it never ends
+func recurrenceEnd(event models.EventRequest) (*time.Time, error) {
+	if event.RecurrenceRule == "" {
+		return nil, nil
+	}
+
+	parsed, err := recurrence.Parse(event.RecurrenceRule)
+	if err != nil {
+		return nil, err
+	}
+
+	date, _ := inTimeZone(event.TimeZone, event.Date, nil)
+	end, ok := parsed.End(date)
+	if !ok {
+		return nil, nil
+	}
+	return &end, nil
+}
+
+// GetOccurrenceExceptions gets the occurrence exceptions for the given events keyed by event ID and original occurrence time
+func (r *EventRepository) GetOccurrenceExceptions(eventIDs []int) (map[int]map[int64]models.OccurrenceException, error) {
+	return getOccurrenceExceptions(r.DB, eventIDs)
+}
+
+func getOccurrenceExceptions(q querier, eventIDs []int) (map[int]map[int64]models.OccurrenceException, error) {
+	exceptions := map[int]map[int64]models.OccurrenceException{}
+	if len(eventIDs) == 0 {
+		return exceptions, nil
+	}
+
+	rows, err := q.Query(`
+		SELECT id, event_id, occurrence_date, cancelled, new_date, created_at, updated_at
+		FROM event_occurrence_exceptions
+		WHERE event_id = ANY($1)
+	`, pq.Array(eventIDs))
+	if err != nil {
+		log.Printf("Error getting occurrence exceptions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var exception models.OccurrenceException
+		if err := rows.Scan(
+			&exception.ID,
+			&exception.EventID,
+			&exception.OccurrenceDate,
+			&exception.Cancelled,
+			&exception.NewDate,
+			&exception.CreatedAt,
+			&exception.UpdatedAt,
+		); err != nil {
+			log.Printf("Error scanning occurrence exception row: %v", err)
+			return nil, err
+		}
+
+		if exceptions[exception.EventID] == nil {
+			exceptions[exception.EventID] = map[int64]models.OccurrenceException{}
+		}
+		exceptions[exception.EventID][exception.OccurrenceDate.Unix()] = exception
+	}
+
+	return exceptions, rows.Err()
+}
+
+// SetOccurrenceException cancels or reschedules a single occurrence of a recurring
+// event and bumps the event's version. An occurrence moved to newDate must find
+// its venue free then unless allowConflicts is set, or a *VenueConflictError is
+// returned.
+func (r *EventRepository) SetOccurrenceException(eventID int, occurrence time.Time, cancelled bool, newDate *time.Time, allowConflicts bool) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting occurrence exception transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var event models.EventRequest
+	err = tx.QueryRow(`
+		SELECT date, end_date, timezone, venue_id, room_id FROM events WHERE id = $1 FOR UPDATE
+	`, eventID).Scan(&event.Date, &event.EndDate, &event.TimeZone, &event.VenueID, &event.RoomID)
+	if err != nil {
+		log.Printf("Error locking event for occurrence exception: %v", err)
+		return err
+	}
+
+	// The moved occurrence is booked on its own, since the rest of the series
+	// keeps its times
+	if newDate != nil && !cancelled {
+		if event.EndDate != nil {
+			end := newDate.Add(event.EndDate.Sub(event.Date))
+			event.EndDate = &end
+		}
+		event.Date = *newDate
+		event.AllowConflicts = allowConflicts
+		if err := bookVenue(tx, event, eventID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE id = $1)
+		INSERT INTO event_occurrence_exceptions (event_id, occurrence_date, cancelled, new_date)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id, occurrence_date)
+		DO UPDATE SET cancelled = EXCLUDED.cancelled, new_date = EXCLUDED.new_date, updated_at = NOW()
+	`, eventID, occurrence, cancelled, newDate)
+	if err != nil {
+		log.Printf("Error setting occurrence exception: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing occurrence exception: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteOccurrenceException restores a cancelled or rescheduled occurrence and
+// bumps the event's version
+func (r *EventRepository) DeleteOccurrenceException(eventID int, occurrence time.Time) error {
+	_, err := r.DB.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE id = $1)
+		DELETE FROM event_occurrence_exceptions WHERE event_id = $1 AND occurrence_date = $2
+	`, eventID, occurrence)
+	if err != nil {
+		log.Printf("Error deleting occurrence exception: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetOccurrences lists the occurrences of a recurring event within [from, to], including cancelled ones
+func (r *EventRepository) GetOccurrences(event *models.EventWithOrganizer, from, to time.Time) ([]models.Occurrence, error) {
+	rule, err := recurrence.Parse(event.RecurrenceRule)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := r.GetOccurrenceExceptions([]int{event.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildOccurrences(event, rule, exceptions[event.ID], from, to, 0), nil
+}
+
+// TodaysOccurrence returns the occurrence of a recurring event that takes place
+// on now's date in the event's time zone. When several do, the first one that
+// hasn't ended yet is returned. Cancelled occurrences are skipped, and
+// ErrNoOccurrenceToday is returned if none is left.
+func (r *EventRepository) TodaysOccurrence(event *models.EventWithOrganizer, now time.Time) (time.Time, error) {
+	now = now.In(event.Date.Location())
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	occurrences, err := r.GetOccurrences(event, dayStart, dayEnd)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var today []models.Occurrence
+	for _, occurrence := range occurrences {
+		if !occurrence.Cancelled {
+			today = append(today, occurrence)
+		}
+	}
+	if len(today) == 0 {
+		return time.Time{}, ErrNoOccurrenceToday
+	}
+
+	for _, occurrence := range today {
+		end := occurrence.Date
+		if occurrence.EndDate != nil {
+			end = *occurrence.EndDate
+		}
+		if end.After(now) {
+			return occurrence.OccurrenceDate, nil
+		}
+	}
+	return today[len(today)-1].OccurrenceDate, nil
+}
+
+// ResolveOccurrence checks that occurrence identifies a valid occurrence of the event and
+// returns its actual start time. One-off events accept a nil occurrence.
+func (r *EventRepository) ResolveOccurrence(event *models.EventWithOrganizer, occurrence *time.Time) (time.Time, error) {
+	if event.RecurrenceRule == "" {
+		if occurrence != nil && !occurrence.Equal(event.Date) {
+			return time.Time{}, ErrInvalidOccurrence
+		}
+		return event.Date, nil
+	}
+
+	if occurrence == nil {
+		return time.Time{}, ErrOccurrenceRequired
+	}
+
+	rule, err := recurrence.Parse(event.RecurrenceRule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !rule.Includes(event.Date, *occurrence) {
+		return time.Time{}, ErrInvalidOccurrence
+	}
+
+	var exception models.OccurrenceException
+	err = r.DB.QueryRow(`
+		SELECT cancelled, new_date FROM event_occurrence_exceptions
+		WHERE event_id = $1 AND occurrence_date = $2
+	`, event.ID, *occurrence).Scan(&exception.Cancelled, &exception.NewDate)
+	if err == sql.ErrNoRows {
+		return *occurrence, nil
+	}
+	if err != nil {
+		log.Printf("Error getting occurrence exception: %v", err)
+		return time.Time{}, err
+	}
+
+	if exception.Cancelled {
+		return time.Time{}, ErrOccurrenceCancelled
+	}
+	if exception.NewDate != nil {
+		return *exception.NewDate, nil
+	}
+	return *occurrence, nil
+}
+
+// expandOccurrences turns recurring events into one entry per live occurrence within
+// [from, to], taking at most about limit occurrences of each series. When after is
+// set, only occurrences that come after it in a listing sorted by sortBy are kept.
+func (r *EventRepository) expandOccurrences(series []models.EventWithOrganizer, from, to time.Time, limit int, sortBy string, after *Cursor) ([]models.EventWithOrganizer, error) {
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	eventIDs := make([]int, len(series))
+	for i, event := range series {
+		eventIDs[i] = event.ID
+	}
+
+	exceptions, err := r.GetOccurrenceExceptions(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var expanded []models.EventWithOrganizer
+	for _, event := range series {
+		rule, err := recurrence.Parse(event.RecurrenceRule)
+		if err != nil {
+			log.Printf("Skipping event %d with invalid recurrence rule: %v", event.ID, err)
+			continue
+		}
+
+		// Include occurrences that started before from but are still running
+		seriesFrom := from.Add(-event.Duration())
+		seriesLimit := limit
+		if after != nil {
+			// Every occurrence shares the series' sort value, so the whole series
+			// comes before or after the cursor unless the values are equal, in
+			// which case occurrences before the cursor's date can be skipped
+			key := eventCursor(&event, sortBy)
+			if key.Value != nil && after.Value != nil && *key.Value < *after.Value {
+				continue
+			}
+			if (key.Value == nil || after.Value == nil || *key.Value == *after.Value) && after.Date.After(seriesFrom) {
+				seriesFrom = after.Date
+				// Leave room for the occurrence at the cursor itself
+				seriesLimit++
+			}
+		}
+
+		for _, occurrence := range buildOccurrences(&event, rule, exceptions[event.ID], seriesFrom, to, seriesLimit) {
+			if occurrence.Cancelled {
+				continue
+			}
+
+			occurrenceDate := occurrence.OccurrenceDate
+			instance := event
+			instance.Date = occurrence.Date
+			instance.EndDate = occurrence.EndDate
+			instance.OccurrenceDate = &occurrenceDate
+			instance.Rescheduled = occurrence.Rescheduled
+			if after != nil && compareCursors(eventCursor(&instance, sortBy), after) <= 0 {
+				continue
+			}
+			expanded = append(expanded, instance)
+		}
+	}
+
+	return expanded, nil
+}
+
+// buildOccurrences applies exceptions to the occurrences of a rule. An occurrence is
+// included when its actual start, after any reschedule, falls within [from, to].
+func buildOccurrences(event *models.EventWithOrganizer, rule *recurrence.Rule, exceptions map[int64]models.OccurrenceException, from, to time.Time, limit int) []models.Occurrence {
+	occurrences := []models.Occurrence{}
+	for _, date := range rule.Between(event.Date, from, to, limit) {
+		occurrence := models.Occurrence{OccurrenceDate: date, Date: date}
+		if exception, ok := exceptions[date.Unix()]; ok {
+			occurrence.Cancelled = exception.Cancelled
+			if exception.NewDate != nil {
+				if exception.NewDate.Before(from) || exception.NewDate.After(to) {
+					continue
+				}
+				occurrence.Date = exception.NewDate.In(event.Date.Location())
+				occurrence.Rescheduled = true
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	// Occurrences rescheduled into the range from outside of it
+	for _, exception := range exceptions {
+		if exception.NewDate == nil || exception.Cancelled {
+			continue
+		}
+		if !exception.OccurrenceDate.Before(from) && !exception.OccurrenceDate.After(to) {
+			continue
+		}
+		if exception.NewDate.Before(from) || exception.NewDate.After(to) {
+			continue
+		}
+		occurrences = append(occurrences, models.Occurrence{
+			OccurrenceDate: exception.OccurrenceDate.In(event.Date.Location()),
+			Date:           exception.NewDate.In(event.Date.Location()),
+			Rescheduled:    true,
+		})
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Date.Before(occurrences[j].Date)
+	})
+
+	if event.EndDate != nil {
+		for i := range occurrences {
+			end := occurrences[i].Date.Add(event.Duration())
+			occurrences[i].EndDate = &end
+		}
+	}
+	return occurrences
+}
+
+// GetVenueBookings lists the bookings of a venue that overlap [from, to), with
+// recurring events expanded into their occurrences. When roomID is set only that
+// room's bookings and bookings of the whole venue are included. Cancelled events
+// and the event given by excludeEventID are left out.
+func (r *EventRepository) GetVenueBookings(venueID int, roomID *int, from, to time.Time, excludeEventID int) ([]models.VenueBooking, error) {
+	return getVenueBookings(r.DB, venueID, roomID, from, to, excludeEventID)
+}
+
+func getVenueBookings(q querier, venueID int, roomID *int, from, to time.Time, excludeEventID int) ([]models.VenueBooking, error) {
+	rows, err := q.Query(`
+		SELECT `+eventColumns+`, COALESCE(vr.name, '')
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		LEFT JOIN venue_rooms vr ON vr.id = e.room_id
+		WHERE e.venue_id = $1 AND e.id <> $2 AND e.status <> 'cancelled' AND e.deleted_at IS NULL
+			AND ($3::INTEGER IS NULL OR e.room_id IS NULL OR e.room_id = $3)
+			AND e.date < $5
+			AND (
+				(e.recurrence_rule = '' AND COALESCE(e.end_date, e.date) > $4)
+				OR (e.recurrence_rule <> '' AND `+seriesActiveCondition("($4::TIMESTAMPTZ - (COALESCE(e.end_date, e.date) - e.date))")+`)
+			)
+		ORDER BY e.date, e.id
+	`, venueID, excludeEventID, roomID, from, to)
+	if err != nil {
+		log.Printf("Error getting venue bookings: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []models.VenueBooking{}
+	var series []models.EventWithOrganizer
+	seriesRooms := map[int]string{}
+	for rows.Next() {
+		var roomName string
+		event, err := scanEventWithOrganizer(rows, &roomName)
+		if err != nil {
+			log.Printf("Error scanning venue booking row: %v", err)
+			return nil, err
+		}
+		if event.RecurrenceRule != "" {
+			series = append(series, event)
+			seriesRooms[event.ID] = roomName
+			continue
+		}
+		bookings = append(bookings, venueBooking(&event, roomName, event.Date, nil))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(series) > 0 {
+		eventIDs := make([]int, len(series))
+		for i, event := range series {
+			eventIDs[i] = event.ID
+		}
+
+		exceptions, err := getOccurrenceExceptions(q, eventIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range series {
+			rule, err := recurrence.Parse(event.RecurrenceRule)
+			if err != nil {
+				log.Printf("Skipping event %d with invalid recurrence rule: %v", event.ID, err)
+				continue
+			}
+
+			// Include occurrences that started before from but are still running
+			for _, occurrence := range buildOccurrences(&event, rule, exceptions[event.ID], from.Add(-event.Duration()), to, 0) {
+				if occurrence.Cancelled || !occurrence.Date.Before(to) {
+					continue
+				}
+				occurrenceDate := occurrence.OccurrenceDate
+				booking := venueBooking(&event, seriesRooms[event.ID], occurrence.Date, &occurrenceDate)
+				if booking.End.After(from) {
+					bookings = append(bookings, booking)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].Start.Before(bookings[j].Start)
+	})
+	return bookings, nil
+}
+
+// FindVenueConflicts lists the existing bookings that overlap the times the event
+// in the request would hold its venue or room. Recurring events are checked up to
+// occurrenceHorizon past their start, with the cancelled and rescheduled
+// occurrences of the event being updated taken into account. excludeEventID is
+// the event being updated, or 0 for a new event.
+func (r *EventRepository) FindVenueConflicts(event models.EventRequest, excludeEventID int) ([]models.VenueBooking, error) {
+	return findVenueConflicts(r.DB, event, excludeEventID)
+}
+
+func findVenueConflicts(q querier, event models.EventRequest, excludeEventID int) ([]models.VenueBooking, error) {
+	if event.VenueID == nil {
+		return nil, nil
+	}
+
+	start, end := inTimeZone(event.TimeZone, event.Date, event.EndDate)
+	var duration time.Duration
+	if end != nil {
+		duration = end.Sub(start)
+	}
+
+	starts := []time.Time{start}
+	if event.RecurrenceRule != "" {
+		rule, err := recurrence.Parse(event.RecurrenceRule)
+		if err != nil {
+			return nil, err
+		}
+		last := start.Add(occurrenceHorizon)
+		if seriesEnd, ok := rule.End(start); ok && seriesEnd.Before(last) {
+			last = seriesEnd
+		}
+
+		exceptions, err := getOccurrenceExceptions(q, []int{excludeEventID})
+		if err != nil {
+			return nil, err
+		}
+		series := &models.EventWithOrganizer{ID: excludeEventID, Date: start, EndDate: end, RecurrenceRule: event.RecurrenceRule}
+		starts = nil
+		for _, occurrence := range buildOccurrences(series, rule, exceptions[excludeEventID], start, last, 0) {
+			if !occurrence.Cancelled {
+				starts = append(starts, occurrence.Date)
+			}
+		}
+		if len(starts) == 0 {
+			return nil, nil
+		}
+	}
+
+	// The range is widened by a microsecond, the resolution of stored times, so
+	// bookings without a length that start right at its edges are found too
+	from := starts[0].Add(-time.Microsecond)
+	to := starts[len(starts)-1].Add(duration + time.Microsecond)
+	bookings, err := getVenueBookings(q, *event.VenueID, event.RoomID, from, to, excludeEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []models.VenueBooking{}
+	for _, booking := range bookings {
+		// Occurrences are sorted and share a length, so the ones that can overlap
+		// the booking start with the first one that hasn't ended before it
+		i := sort.Search(len(starts), func(i int) bool {
+			return !starts[i].Add(duration).Before(booking.Start)
+		})
+		for ; i < len(starts) && !starts[i].After(booking.End); i++ {
+			if bookingsOverlap(starts[i], starts[i].Add(duration), booking.Start, booking.End) {
+				conflicts = append(conflicts, booking)
+				break
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// bookingsOverlap reports whether the bookings [aStart, aEnd) and [bStart, bEnd)
+// overlap. Bookings without a length still overlap the bookings running when they
+// start, and any other booking starting at the same time.
+func bookingsOverlap(aStart, aEnd, bStart, bEnd time.Time) bool {
+	if aStart.Equal(bStart) {
+		return true
+	}
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// VenueConflictError is returned when the venue an event is written with was
+// booked by another event since the request checked it
+type VenueConflictError struct {
+	Conflicts []models.VenueBooking
+}
+
+func (e *VenueConflictError) Error() string {
+	return fmt.Sprintf("venue has %d conflicting bookings", len(e.Conflicts))
+}
+
+// bookVenue checks again, within the transaction writing the event, that the
+// venue it books is free unless the request allows conflicts. The venue row is
+// locked first so concurrent bookings of the same venue are checked one at a time.
+func bookVenue(tx *sql.Tx, event models.EventRequest, excludeEventID int) error {
+	if event.VenueID == nil {
+		return nil
+	}
+
+	if _, err := tx.Exec("SELECT id FROM venues WHERE id = $1 FOR UPDATE", *event.VenueID); err != nil {
+		log.Printf("Error locking venue: %v", err)
+		return err
+	}
+
+	conflicts, err := findVenueConflicts(tx, event, excludeEventID)
+	if err != nil {
+		log.Printf("Error finding venue conflicts: %v", err)
+		return err
+	}
+	if len(conflicts) > 0 && !event.AllowConflicts {
+		return &VenueConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// venueBooking describes the booking an event holds starting at start
+func venueBooking(event *models.EventWithOrganizer, roomName string, start time.Time, occurrence *time.Time) models.VenueBooking {
+	return models.VenueBooking{
+		EventID:        event.ID,
+		Title:          event.Title,
+		RoomID:         event.RoomID,
+		RoomName:       roomName,
+		Start:          start,
+		End:            start.Add(event.Duration()),
+		OccurrenceDate: occurrence,
+		Visibility:     event.Visibility,
+		Status:         event.Status,
+		UserID:         event.UserID,
+	}
 }
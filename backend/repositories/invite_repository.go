@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// InviteRepository handles database operations for event invite tokens
+type InviteRepository struct {
+	DB *sql.DB
+}
+
+func NewInviteRepository(db *sql.DB) *InviteRepository {
+	return &InviteRepository{DB: db}
+}
+
+// CreateInvite stores a new invite for an event
+func (r *InviteRepository) CreateInvite(eventID, createdBy int, nonce string) (*models.EventInvite, error) {
+	invite := models.EventInvite{
+		EventID:   eventID,
+		Nonce:     nonce,
+		CreatedBy: createdBy,
+	}
+	err := r.DB.QueryRow(`
+		INSERT INTO event_invite_tokens (event_id, nonce, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, eventID, nonce, createdBy).Scan(&invite.ID, &invite.CreatedAt)
+
+	if err != nil {
+		log.Printf("Error creating invite: %v", err)
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// GetInvites gets all invites for an event, including revoked ones
+func (r *InviteRepository) GetInvites(eventID int) ([]models.EventInvite, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, event_id, nonce, created_by, created_at, revoked_at
+		FROM event_invite_tokens
+		WHERE event_id = $1
+		ORDER BY created_at DESC
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting invites: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []models.EventInvite{}
+	for rows.Next() {
+		var invite models.EventInvite
+		if err := rows.Scan(
+			&invite.ID,
+			&invite.EventID,
+			&invite.Nonce,
+			&invite.CreatedBy,
+			&invite.CreatedAt,
+			&invite.RevokedAt,
+		); err != nil {
+			log.Printf("Error scanning invite row: %v", err)
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// IsInviteActive reports whether an invite with the given nonce exists for the event and has not been revoked
+func (r *InviteRepository) IsInviteActive(eventID int, nonce string) (bool, error) {
+	var active bool
+	err := r.DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM event_invite_tokens
+			WHERE event_id = $1 AND nonce = $2 AND revoked_at IS NULL
+		)
+	`, eventID, nonce).Scan(&active)
+
+	if err != nil {
+		log.Printf("Error checking invite: %v", err)
+		return false, err
+	}
+
+	return active, nil
+}
+
+// RevokeInvite revokes an invite so its token no longer grants access
+func (r *InviteRepository) RevokeInvite(eventID, inviteID int) error {
+	result, err := r.DB.Exec(`
+		UPDATE event_invite_tokens SET revoked_at = NOW()
+		WHERE id = $1 AND event_id = $2 AND revoked_at IS NULL
+	`, inviteID, eventID)
+	if err != nil {
+		log.Printf("Error revoking invite: %v", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// MemberRepository handles database operations for event members
+type MemberRepository struct {
+	DB *sql.DB
+}
+
+func NewMemberRepository(db *sql.DB) *MemberRepository {
+	return &MemberRepository{DB: db}
+}
+
+// AddMember adds a user to an event with the given role, or changes their role if they are already a member.
+// The event's version is bumped since the role is part of what members see of it.
+func (r *MemberRepository) AddMember(eventID, userID int, role string, invitedBy int) error {
+	_, err := r.DB.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE id = $1)
+		INSERT INTO event_members (event_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id, user_id)
+		DO UPDATE SET role = $3, updated_at = NOW()
+	`, eventID, userID, role, invitedBy)
+
+	if err != nil {
+		log.Printf("Error adding event member: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetMembers gets all members of an event
+func (r *MemberRepository) GetMembers(eventID int) ([]models.EventMember, error) {
+	rows, err := r.DB.Query(`
+		SELECT m.event_id, m.user_id, m.role, u.first_name, u.last_name, u.email, m.invited_by, m.created_at, m.updated_at
+		FROM event_members m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.event_id = $1
+		ORDER BY m.created_at
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting event members: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.EventMember{}
+	for rows.Next() {
+		var member models.EventMember
+		var invitedBy sql.NullInt64
+		if err := rows.Scan(
+			&member.EventID,
+			&member.UserID,
+			&member.Role,
+			&member.FirstName,
+			&member.LastName,
+			&member.Email,
+			&invitedBy,
+			&member.CreatedAt,
+			&member.UpdatedAt,
+		); err != nil {
+			log.Printf("Error scanning event member row: %v", err)
+			return nil, err
+		}
+		if invitedBy.Valid {
+			id := int(invitedBy.Int64)
+			member.InvitedBy = &id
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetMemberRole gets a user's role on an event, or an empty string if they are not a member
+func (r *MemberRepository) GetMemberRole(eventID, userID int) (string, error) {
+	var role string
+	err := r.DB.QueryRow(
+		"SELECT role FROM event_members WHERE event_id = $1 AND user_id = $2",
+		eventID, userID,
+	).Scan(&role)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		log.Printf("Error getting event member role: %v", err)
+		return "", err
+	}
+
+	return role, nil
+}
+
+// RemoveMember removes a user from an event's members and bumps the event's version
+func (r *MemberRepository) RemoveMember(eventID, userID int) error {
+	result, err := r.DB.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE id = $1)
+		DELETE FROM event_members WHERE event_id = $1 AND user_id = $2
+	`, eventID, userID)
+	if err != nil {
+		log.Printf("Error removing event member: %v", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
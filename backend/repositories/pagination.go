@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed or belongs to a different listing
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page sizes for list endpoints
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Cursor marks the last item of a page. Listings are ordered by Value when their
+// sort uses one, then by Date, ID and Occurrence, and the next page starts strictly
+// after the cursor, so items inserted while paging never shift later pages.
+type Cursor struct {
+	Sort       string     `json:"s"`
+	Value      *float64   `json:"v,omitempty"`
+	Date       time.Time  `json:"d"`
+	ID         int        `json:"i"`
+	Occurrence *time.Time `json:"o,omitempty"`
+}
+
+// EncodeCursor returns the opaque form of a cursor handed to clients, or an empty
+// string for a nil cursor
+func EncodeCursor(cursor *Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by EncodeCursor
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// compareCursors orders two positions in a listing, returning a negative number
+// when a comes first, zero when they are the same position and a positive number otherwise
+func compareCursors(a, b *Cursor) int {
+	if a.Value != nil && b.Value != nil && *a.Value != *b.Value {
+		if *a.Value < *b.Value {
+			return -1
+		}
+		return 1
+	}
+	if c := a.Date.Compare(b.Date); c != 0 {
+		return c
+	}
+	if a.ID != b.ID {
+		if a.ID < b.ID {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case a.Occurrence == nil && b.Occurrence == nil:
+		return 0
+	case a.Occurrence == nil:
+		return -1
+	case b.Occurrence == nil:
+		return 1
+	}
+	return a.Occurrence.Compare(*b.Occurrence)
+}
+
+// eventCursor returns the position of an event in a listing sorted by sortBy.
+// Relevance is negated so every sort is ascending.
+func eventCursor(event *models.EventWithOrganizer, sortBy string) *Cursor {
+	cursor := &Cursor{
+		Sort:       sortBy,
+		Date:       event.Date,
+		ID:         event.ID,
+		Occurrence: event.OccurrenceDate,
+	}
+	switch sortBy {
+	case models.SortByRelevance:
+		value := -event.Rank
+		cursor.Value = &value
+	case models.SortByDistance:
+		if event.DistanceKm != nil {
+			value := *event.DistanceKm
+			cursor.Value = &value
+		}
+	}
+	return cursor
+}
+
+// eventKeysetCondition returns a condition matching events after the cursor in a
+// listing ordered by valueExpression, when set, then date and ID
+func eventKeysetCondition(cursor *Cursor, valueExpression string, argPosition int) (string, []interface{}) {
+	if valueExpression != "" && cursor.Value != nil {
+		return fmt.Sprintf(" AND (%s, e.date, e.id) > ($%d::double precision, $%d::timestamptz, $%d::integer)", valueExpression, argPosition, argPosition+1, argPosition+2),
+			[]interface{}{*cursor.Value, cursor.Date, cursor.ID}
+	}
+	return fmt.Sprintf(" AND (e.date, e.id) > ($%d::timestamptz, $%d::integer)", argPosition, argPosition+1),
+		[]interface{}{cursor.Date, cursor.ID}
+}
+
+// paginate sorts events by their position in the listing and cuts them down to a
+// page of limit events, returning the cursor for the next page when there is one
+func paginate(events []models.EventWithOrganizer, sortBy string, limit int) ([]models.EventWithOrganizer, *Cursor) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return compareCursors(eventCursor(&events[i], sortBy), eventCursor(&events[j], sortBy)) < 0
+	})
+
+	if len(events) <= limit {
+		return events, nil
+	}
+	events = events[:limit]
+	return events, eventCursor(&events[limit-1], sortBy)
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// QuestionRepository handles database operations for the questions an event asks
+// attendees, either when they RSVP or in its post-event survey. Answers given
+// when attendees RSVP are kept by the AnswerRepository.
+type QuestionRepository struct {
+	DB    *sql.DB
+	table string // event_questions or survey_questions
+}
+
+func NewQuestionRepository(db *sql.DB) *QuestionRepository {
+	return &QuestionRepository{DB: db, table: "event_questions"}
+}
+
+// NewSurveyQuestionRepository returns a QuestionRepository for the questions of
+// post-event surveys
+func NewSurveyQuestionRepository(db *sql.DB) *QuestionRepository {
+	return &QuestionRepository{DB: db, table: "survey_questions"}
+}
+
+// CreateQuestion adds a question to an event, after its other questions unless
+// a position is given
+func (r *QuestionRepository) CreateQuestion(eventID int, req models.QuestionRequest) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`
+		INSERT INTO `+r.table+` (event_id, label, type, options, required, position)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, (SELECT COALESCE(MAX(position) + 1, 0) FROM `+r.table+` WHERE event_id = $1)))
+		RETURNING id
+	`, eventID, req.Label, req.Type, pq.Array(req.Options), req.Required, req.Position).Scan(&id)
+
+	if err != nil {
+		log.Printf("Error creating question: %v", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetQuestions gets an event's questions in the order they are asked
+func (r *QuestionRepository) GetQuestions(eventID int) ([]models.EventQuestion, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, event_id, label, type, options, required, position, created_at, updated_at
+		FROM `+r.table+`
+		WHERE event_id = $1
+		ORDER BY position, id
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting questions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []models.EventQuestion{}
+	for rows.Next() {
+		question, err := scanQuestion(rows)
+		if err != nil {
+			log.Printf("Error scanning question row: %v", err)
+			return nil, err
+		}
+		questions = append(questions, question)
+	}
+
+	return questions, rows.Err()
+}
+
+// GetQuestionByID gets a question of an event
+func (r *QuestionRepository) GetQuestionByID(eventID, questionID int) (*models.EventQuestion, error) {
+	question, err := scanQuestion(r.DB.QueryRow(`
+		SELECT id, event_id, label, type, options, required, position, created_at, updated_at
+		FROM `+r.table+`
+		WHERE id = $1 AND event_id = $2
+	`, questionID, eventID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting question: %v", err)
+		}
+		return nil, err
+	}
+
+	return &question, nil
+}
+
+// UpdateQuestion replaces the details of a question. Answers already given keep
+// the options that were chosen.
+func (r *QuestionRepository) UpdateQuestion(questionID int, req models.QuestionRequest) error {
+	_, err := r.DB.Exec(`
+		UPDATE `+r.table+`
+		SET label = $1, type = $2, options = $3, required = $4, position = COALESCE($5, position), updated_at = NOW()
+		WHERE id = $6
+	`, req.Label, req.Type, pq.Array(req.Options), req.Required, req.Position, questionID)
+	if err != nil {
+		log.Printf("Error updating question: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteQuestion deletes a question along with its answers
+func (r *QuestionRepository) DeleteQuestion(questionID int) error {
+	_, err := r.DB.Exec("DELETE FROM "+r.table+" WHERE id = $1", questionID)
+	if err != nil {
+		log.Printf("Error deleting question: %v", err)
+		return err
+	}
+	return nil
+}
+
+// scanQuestion scans a row of event_questions or survey_questions
+func scanQuestion(row rowScanner) (models.EventQuestion, error) {
+	var question models.EventQuestion
+	err := row.Scan(
+		&question.ID,
+		&question.EventID,
+		&question.Label,
+		&question.Type,
+		pq.Array(&question.Options),
+		&question.Required,
+		&question.Position,
+		&question.CreatedAt,
+		&question.UpdatedAt,
+	)
+	if question.Options == nil {
+		question.Options = []string{}
+	}
+	return question, err
+}
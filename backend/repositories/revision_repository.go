@@ -0,0 +1,336 @@
+package repositories
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// revisionSort identifies cursors for revision listings, which are ordered newest first
+const revisionSort = "revisions"
+
+// revisionFields lists the fields of an event snapshot in the order their
+// changes are reported
+var revisionFields = []string{
+	"title", "description", "date", "end_date", "timezone", "location", "latitude", "longitude",
+	"recurrence_rule", "capacity", "max_guests", "rsvp_opens_at", "rsvp_closes_at",
+	"requires_approval", "visibility", "venue_id", "room_id", "tags",
+}
+
+// RevisionRepository handles database operations for the change history of
+// events. Revisions are written by EventRepository as events are created and
+// updated, and are never changed afterwards.
+type RevisionRepository struct {
+	DB *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) *RevisionRepository {
+	return &RevisionRepository{DB: db}
+}
+
+// GetRevision gets a revision of an event by its number
+func (r *RevisionRepository) GetRevision(eventID, number int) (*models.EventRevision, error) {
+	revision, err := scanRevision(r.DB.QueryRow(`
+		SELECT v.id, v.event_id, v.number, v.user_id, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+			   v.changes, v.restored_from, v.snapshot, v.created_at
+		FROM event_revisions v
+		LEFT JOIN users u ON v.user_id = u.id
+		WHERE v.event_id = $1 AND v.number = $2
+	`, eventID, number))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting revision: %v", err)
+		}
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// GetRevisions gets a page of an event's revisions, newest first
+func (r *RevisionRepository) GetRevisions(eventID, limit int, after *Cursor) ([]models.EventRevision, *Cursor, error) {
+	if after != nil && after.Sort != revisionSort {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	var afterNumber *int
+	if after != nil {
+		afterNumber = &after.ID
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT v.id, v.event_id, v.number, v.user_id, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+			   v.changes, v.restored_from, v.snapshot, v.created_at
+		FROM event_revisions v
+		LEFT JOIN users u ON v.user_id = u.id
+		WHERE v.event_id = $1 AND ($2::integer IS NULL OR v.number < $2::integer)
+		ORDER BY v.number DESC
+		LIMIT $3
+	`, eventID, afterNumber, limit+1)
+	if err != nil {
+		log.Printf("Error getting revisions: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.EventRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			log.Printf("Error scanning revision row: %v", err)
+			return nil, nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating revision rows: %v", err)
+		return nil, nil, err
+	}
+
+	if len(revisions) <= limit {
+		return revisions, nil, nil
+	}
+	revisions = revisions[:limit]
+	last := revisions[limit-1]
+	return revisions, &Cursor{Sort: revisionSort, Date: last.CreatedAt, ID: last.Number}, nil
+}
+
+// scanRevision scans a row of event_revisions joined with its author
+func scanRevision(row rowScanner) (models.EventRevision, error) {
+	var revision models.EventRevision
+	var changes, snapshot []byte
+	err := row.Scan(
+		&revision.ID,
+		&revision.EventID,
+		&revision.Number,
+		&revision.UserID,
+		&revision.FirstName,
+		&revision.LastName,
+		&changes,
+		&revision.RestoredFrom,
+		&snapshot,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return revision, err
+	}
+
+	if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+		return revision, err
+	}
+	err = json.Unmarshal(snapshot, &revision.Snapshot)
+	return revision, err
+}
+
+// newSnapshot returns the tracked details of an event request. Times are kept in
+// UTC at the database's microsecond precision and tags sorted so equal details
+// always compare equal.
+func newSnapshot(event models.EventRequest) models.EventSnapshot {
+	tags := append([]string{}, event.Tags...)
+	sort.Strings(tags)
+
+	return models.EventSnapshot{
+		Title:            event.Title,
+		Description:      event.Description,
+		Date:             event.Date.UTC().Truncate(time.Microsecond),
+		EndDate:          utcTime(event.EndDate),
+		TimeZone:         event.TimeZone,
+		Location:         event.Location,
+		Latitude:         event.Latitude,
+		Longitude:        event.Longitude,
+		RecurrenceRule:   event.RecurrenceRule,
+		Capacity:         event.Capacity,
+		MaxGuests:        event.MaxGuests,
+		RSVPOpensAt:      utcTime(event.RSVPOpensAt),
+		RSVPClosesAt:     utcTime(event.RSVPClosesAt),
+		RequiresApproval: event.RequiresApproval,
+		Visibility:       event.Visibility,
+		VenueID:          event.VenueID,
+		RoomID:           event.RoomID,
+		Tags:             tags,
+	}
+}
+
+// utcTime returns a copy of an optional time in UTC, to the microsecond
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC().Truncate(time.Microsecond)
+	return &utc
+}
+
+// lockedEvent is the state of an event locked for an update
+type lockedEvent struct {
+	snapshot  models.EventSnapshot
+	ownerID   int
+	version   int
+	createdAt time.Time
+}
+
+// lockEvent locks an event for the rest of the transaction and returns its
+// current details, owner, version and when it was created
+func lockEvent(tx *sql.Tx, eventID int) (lockedEvent, error) {
+	var event models.EventWithOrganizer
+	err := tx.QueryRow(`
+		SELECT e.title, e.description, e.date, e.end_date, e.timezone, e.location, e.latitude, e.longitude,
+			   e.recurrence_rule, e.capacity, e.max_guests, e.rsvp_opens_at, e.rsvp_closes_at,
+			   e.requires_approval, e.visibility, e.venue_id, e.room_id, `+eventTagsColumn+`, e.user_id, e.version, e.created_at
+		FROM events e
+		WHERE e.id = $1 AND e.deleted_at IS NULL
+		FOR UPDATE
+	`, eventID).Scan(
+		&event.Title,
+		&event.Description,
+		&event.Date,
+		&event.EndDate,
+		&event.TimeZone,
+		&event.Location,
+		&event.Latitude,
+		&event.Longitude,
+		&event.RecurrenceRule,
+		&event.Capacity,
+		&event.MaxGuests,
+		&event.RSVPOpensAt,
+		&event.RSVPClosesAt,
+		&event.RequiresApproval,
+		&event.Visibility,
+		&event.VenueID,
+		&event.RoomID,
+		pq.Array(&event.Tags),
+		&event.UserID,
+		&event.Version,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error locking event: %v", err)
+		}
+		return lockedEvent{}, err
+	}
+
+	snapshot := newSnapshot(models.EventRequest{
+		Title:            event.Title,
+		Description:      event.Description,
+		Date:             event.Date,
+		EndDate:          event.EndDate,
+		TimeZone:         event.TimeZone,
+		Location:         event.Location,
+		Latitude:         event.Latitude,
+		Longitude:        event.Longitude,
+		RecurrenceRule:   event.RecurrenceRule,
+		Capacity:         event.Capacity,
+		MaxGuests:        event.MaxGuests,
+		RSVPOpensAt:      event.RSVPOpensAt,
+		RSVPClosesAt:     event.RSVPClosesAt,
+		RequiresApproval: event.RequiresApproval,
+		Visibility:       event.Visibility,
+		VenueID:          event.VenueID,
+		RoomID:           event.RoomID,
+		Tags:             event.Tags,
+	})
+	return lockedEvent{
+		snapshot:  snapshot,
+		ownerID:   event.UserID,
+		version:   event.Version,
+		createdAt: event.CreatedAt,
+	}, nil
+}
+
+// diffSnapshots returns the fields that differ between two snapshots of an event
+func diffSnapshots(before, after models.EventSnapshot) ([]models.FieldChange, error) {
+	from, err := snapshotFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := snapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.FieldChange{}
+	for _, field := range revisionFields {
+		if !bytes.Equal(from[field], to[field]) {
+			changes = append(changes, models.FieldChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+	return changes, nil
+}
+
+// snapshotFields returns the JSON value of each field of a snapshot
+func snapshotFields(snapshot models.EventSnapshot) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// insertRevision appends a revision with the event's details after a change and
+// returns its number. createdAt defaults to now when nil.
+func insertRevision(tx *sql.Tx, eventID int, userID int, changes []models.FieldChange, snapshot models.EventSnapshot, restoredFrom *int, createdAt *time.Time) (int, error) {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return 0, err
+	}
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	var number int
+	err = tx.QueryRow(`
+		INSERT INTO event_revisions (event_id, number, user_id, changes, snapshot, restored_from, created_at)
+		SELECT $1, COALESCE(MAX(number), 0) + 1, $2, $3, $4, $5, COALESCE($6, NOW())
+		FROM event_revisions WHERE event_id = $1
+		RETURNING number
+	`, eventID, userID, changesJSON, snapshotJSON, restoredFrom, createdAt).Scan(&number)
+	if err != nil {
+		log.Printf("Error recording event revision: %v", err)
+		return 0, err
+	}
+	return number, nil
+}
+
+// recordUpdate appends a revision for the changes an update makes to an event
+// locked with lockEvent, and returns its number, or 0 when nothing changed.
+// Events created before revisions were kept get a first revision with their
+// details before the update.
+func recordUpdate(tx *sql.Tx, eventID, userID int, locked lockedEvent, event models.EventRequest, restoredFrom *int) (int, error) {
+	before := locked.snapshot
+	if event.Tags == nil {
+		event.Tags = before.Tags
+	}
+	after := newSnapshot(event)
+
+	changes, err := diffSnapshots(before, after)
+	if err != nil {
+		log.Printf("Error comparing event revisions: %v", err)
+		return 0, err
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM event_revisions WHERE event_id = $1)", eventID).Scan(&exists); err != nil {
+		log.Printf("Error checking event revisions: %v", err)
+		return 0, err
+	}
+	if !exists {
+		if _, err := insertRevision(tx, eventID, locked.ownerID, []models.FieldChange{}, before, nil, &locked.createdAt); err != nil {
+			return 0, err
+		}
+	}
+
+	return insertRevision(tx, eventID, userID, changes, after, restoredFrom, nil)
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// RSVPOverrideRepository handles database operations for the users organizers
+// allow to RSVP outside an event's RSVP window
+type RSVPOverrideRepository struct {
+	DB *sql.DB
+}
+
+func NewRSVPOverrideRepository(db *sql.DB) *RSVPOverrideRepository {
+	return &RSVPOverrideRepository{DB: db}
+}
+
+// AddOverride lets a user RSVP to an event outside its RSVP window
+func (r *RSVPOverrideRepository) AddOverride(eventID, userID, grantedBy int) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO rsvp_overrides (event_id, user_id, granted_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id, user_id) DO NOTHING
+	`, eventID, userID, grantedBy)
+
+	if err != nil {
+		log.Printf("Error adding RSVP override: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetOverrides gets the users allowed to RSVP to an event outside its RSVP window
+func (r *RSVPOverrideRepository) GetOverrides(eventID int) ([]models.RSVPOverride, error) {
+	rows, err := r.DB.Query(`
+		SELECT o.event_id, o.user_id, u.first_name, u.last_name, u.email, o.granted_by, o.created_at
+		FROM rsvp_overrides o
+		JOIN users u ON o.user_id = u.id
+		WHERE o.event_id = $1
+		ORDER BY o.created_at
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting RSVP overrides: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := []models.RSVPOverride{}
+	for rows.Next() {
+		var override models.RSVPOverride
+		var grantedBy sql.NullInt64
+		if err := rows.Scan(
+			&override.EventID,
+			&override.UserID,
+			&override.FirstName,
+			&override.LastName,
+			&override.Email,
+			&grantedBy,
+			&override.CreatedAt,
+		); err != nil {
+			log.Printf("Error scanning RSVP override row: %v", err)
+			return nil, err
+		}
+		if grantedBy.Valid {
+			id := int(grantedBy.Int64)
+			override.GrantedBy = &id
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// HasOverride reports whether the user may RSVP to the event outside its RSVP window
+func (r *RSVPOverrideRepository) HasOverride(eventID, userID int) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM rsvp_overrides
+			WHERE event_id = $1 AND user_id = $2
+		)
+	`, eventID, userID).Scan(&exists)
+
+	if err != nil {
+		log.Printf("Error checking RSVP override: %v", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// RemoveOverride holds a user to the event's RSVP window again
+func (r *RSVPOverrideRepository) RemoveOverride(eventID, userID int) error {
+	result, err := r.DB.Exec(
+		"DELETE FROM rsvp_overrides WHERE event_id = $1 AND user_id = $2",
+		eventID, userID,
+	)
+	if err != nil {
+		log.Printf("Error removing RSVP override: %v", err)
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
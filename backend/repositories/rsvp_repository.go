@@ -2,11 +2,28 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
 )
 
+// Errors returned when checking in an attendee
+var (
+	ErrNotGoing         = errors.New("RSVP is not going")
+	ErrAlreadyCheckedIn = errors.New("attendee already checked in")
+	ErrWrongOccurrence  = errors.New("RSVP is for a different occurrence")
+)
+
+// ErrNotEnoughSeats is returned when an attendee who is going adds more guests
+// than there are seats left
+var ErrNotEnoughSeats = errors.New("not enough seats left for guests")
+
+// ErrRSVPDeclined is returned when an attendee whose request was declined asks to go again
+var ErrRSVPDeclined = errors.New("RSVP was declined")
+
 // RSVPRepository handles database operations for RSVPs
 type RSVPRepository struct {
 	DB *sql.DB
@@ -16,57 +33,308 @@ func NewRSVPRepository(db *sql.DB) *RSVPRepository {
 	return &RSVPRepository{DB: db}
 }
 
-// CreateOrUpdateRSVP creates or updates an RSVP
-func (r *RSVPRepository) CreateOrUpdateRSVP(eventID, userID int, status string) error {
-	// Check if RSVP already exists
-	var exists bool
-	err := r.DB.QueryRow(`
-		SELECT EXISTS(
-			SELECT 1 FROM rsvps 
-			WHERE event_id = $1 AND user_id = $2
-		)
-	`, eventID, userID).Scan(&exists)
+// CreateOrUpdateRSVP creates or updates an RSVP. occurrence identifies the
+// occurrence of a recurring event and is nil for one-off events.
+//
+// Capacity is enforced in a transaction and counts each attendee together with
+// their guests: a "going" RSVP that doesn't fit is stored as "waitlisted", and
+// when a "going" RSVP is withdrawn or brings fewer guests the next waitlisted
+// users are promoted. Attendees already going who add more guests than there
+// are seats left get ErrNotEnoughSeats. It returns the stored status and the IDs
+// of promoted users.
+//
+// For events requiring approval, "going" RSVPs are stored as "pending" until an
+// organizer reviews them, and attendees who were declined get ErrRSVPDeclined
+// for any change until an organizer approves them.
+//
+// The guests are kept unless req.Guests is set, and the answers to the event's
+// registration questions replace any stored with the RSVP unless they are nil.
+func (r *RSVPRepository) CreateOrUpdateRSVP(eventID, userID int, occurrence *time.Time, req models.RSVPRequest) (string, []int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting RSVP transaction: %v", err)
+		return "", nil, err
+	}
+	defer tx.Rollback()
 
+	capacity, err := lockEventCapacity(tx, eventID)
 	if err != nil {
+		return "", nil, err
+	}
+
+	var requiresApproval bool
+	err = tx.QueryRow("SELECT requires_approval FROM events WHERE id = $1", eventID).Scan(&requiresApproval)
+	if err != nil {
+		log.Printf("Error checking if event requires approval: %v", err)
+		return "", nil, err
+	}
+
+	// Check if RSVP already exists
+	var previousStatus string
+	var previousGuests int
+	var guestNames []string
+	err = tx.QueryRow(`
+		SELECT status, guests, guest_names FROM rsvps 
+		WHERE event_id = $1 AND user_id = $2 AND occurrence_date IS NOT DISTINCT FROM $3
+	`, eventID, userID, occurrence).Scan(&previousStatus, &previousGuests, pq.Array(&guestNames))
+	exists := err == nil
+
+	if err != nil && err != sql.ErrNoRows {
 		log.Printf("Error checking if RSVP exists: %v", err)
-		return err
+		return "", nil, err
+	}
+
+	status := req.Status
+	guests := previousGuests
+	if req.Guests != nil {
+		guests, guestNames = *req.Guests, req.GuestNames
+	}
+	if guestNames == nil {
+		guestNames = []string{}
+	}
+
+	// A declined RSVP stays declined until an organizer reviews it again, so it
+	// can only be withdrawn
+	if previousStatus == "declined" {
+		return "", nil, ErrRSVPDeclined
+	}
+
+	// Attendees who were approved keep their place, everyone else waits for review
+	if status == "going" && requiresApproval && previousStatus != "going" && previousStatus != "waitlisted" {
+		status = "pending"
 	}
 
+	// Place the user on the waitlist if the event is full, or if others are
+	// already waiting for the seats that are left
+	if status == "going" && capacity.Valid && (previousStatus != "going" || guests > previousGuests) {
+		headcount, err := countHeadcount(tx, eventID, occurrence)
+		if err != nil {
+			return "", nil, err
+		}
+		if previousStatus == "going" {
+			headcount -= 1 + previousGuests
+		}
+		full := headcount+1+guests > int(capacity.Int64)
+		if !full {
+			if full, err = hasWaitlistAhead(tx, eventID, userID, occurrence); err != nil {
+				return "", nil, err
+			}
+		}
+		if full {
+			if previousStatus == "going" {
+				return "", nil, ErrNotEnoughSeats
+			}
+			status = "waitlisted"
+		}
+	}
+
+	var rsvpID int
 	if exists {
-		// Update existing RSVP
-		_, err = r.DB.Exec(`
+		// Update existing RSVP, keeping the user's place in the waitlist
+		err = tx.QueryRow(`
 			UPDATE rsvps 
-			SET status = $1, updated_at = NOW() 
-			WHERE event_id = $2 AND user_id = $3
-		`, status, eventID, userID)
+			SET status = $1::varchar,
+				guests = $5,
+				guest_names = $6,
+				waitlisted_at = CASE
+					WHEN $1::varchar <> 'waitlisted' THEN NULL
+					ELSE COALESCE(waitlisted_at, NOW())
+				END,
+				checked_in_at = CASE WHEN $1::varchar = 'going' THEN checked_in_at END,
+				updated_at = NOW() 
+			WHERE event_id = $2 AND user_id = $3 AND occurrence_date IS NOT DISTINCT FROM $4
+			RETURNING id
+		`, status, eventID, userID, occurrence, guests, pq.Array(guestNames)).Scan(&rsvpID)
 	} else {
 		// Create new RSVP
-		_, err = r.DB.Exec(`
-			INSERT INTO rsvps (event_id, user_id, status) 
-			VALUES ($1, $2, $3)
-		`, eventID, userID, status)
+		err = tx.QueryRow(`
+			INSERT INTO rsvps (event_id, user_id, occurrence_date, status, guests, guest_names, waitlisted_at) 
+			VALUES ($1, $2, $3, $4::varchar, $5, $6, CASE WHEN $4::varchar = 'waitlisted' THEN NOW() END)
+			RETURNING id
+		`, eventID, userID, occurrence, status, guests, pq.Array(guestNames)).Scan(&rsvpID)
 	}
 
 	if err != nil {
 		log.Printf("Error creating/updating RSVP: %v", err)
-		return err
+		return "", nil, err
 	}
 
-	return nil
+	if req.Answers != nil {
+		if err := setRSVPAnswers(tx, rsvpID, req.Answers); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var promoted []int
+	if previousStatus == "going" && (status != "going" || guests < previousGuests) {
+		if status != "going" {
+			if err := leaveEventSessions(tx, eventID, userID, occurrence); err != nil {
+				return "", nil, err
+			}
+		}
+		promoted, err = promoteWaitlisted(tx, eventID, occurrence, capacity)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing RSVP: %v", err)
+		return "", nil, err
+	}
+
+	return status, promoted, nil
+}
+
+// lockEventCapacity locks the event row for the rest of the transaction, serializing
+// RSVP changes for the event, and returns its capacity
+func lockEventCapacity(tx *sql.Tx, eventID int) (sql.NullInt64, error) {
+	var capacity sql.NullInt64
+	err := tx.QueryRow("SELECT capacity FROM events WHERE id = $1 FOR UPDATE", eventID).Scan(&capacity)
+	if err != nil {
+		log.Printf("Error locking event for RSVP: %v", err)
+	}
+	return capacity, err
 }
 
-// GetRSVPByEventAndUser gets an RSVP by event ID and user ID
-func (r *RSVPRepository) GetRSVPByEventAndUser(eventID, userID int) (*models.RSVP, error) {
+// countHeadcount counts the attendees going to an event occurrence together
+// with their guests
+func countHeadcount(tx *sql.Tx, eventID int, occurrence *time.Time) (int, error) {
+	var headcount int
+	err := tx.QueryRow(`
+		SELECT COALESCE(SUM(1 + guests), 0) FROM rsvps
+		WHERE event_id = $1 AND status = 'going' AND occurrence_date IS NOT DISTINCT FROM $2
+	`, eventID, occurrence).Scan(&headcount)
+	if err != nil {
+		log.Printf("Error getting headcount: %v", err)
+	}
+	return headcount, err
+}
+
+// hasWaitlistAhead reports whether anyone is waiting for a seat at an event
+// occurrence ahead of a user. Users who aren't waitlisted themselves are behind
+// everyone on the waitlist.
+func hasWaitlistAhead(tx *sql.Tx, eventID, userID int, occurrence *time.Time) (bool, error) {
+	var ahead bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM rsvps w
+			LEFT JOIN rsvps me ON me.event_id = w.event_id AND me.user_id = $2
+				AND me.occurrence_date IS NOT DISTINCT FROM $3 AND me.status = 'waitlisted'
+			WHERE w.event_id = $1 AND w.status = 'waitlisted' AND w.occurrence_date IS NOT DISTINCT FROM $3
+				AND w.user_id <> $2 AND (me.id IS NULL OR (w.waitlisted_at, w.id) < (me.waitlisted_at, me.id))
+		)
+	`, eventID, userID, occurrence).Scan(&ahead)
+	if err != nil {
+		log.Printf("Error checking the waitlist: %v", err)
+	}
+	return ahead, err
+}
+
+// promoteWaitlisted moves waitlisted users to "going", in the order they joined
+// the waitlist, while they and their guests fit. It returns the IDs of promoted users.
+func promoteWaitlisted(tx *sql.Tx, eventID int, occurrence *time.Time, capacity sql.NullInt64) ([]int, error) {
+	headcount, err := countHeadcount(tx, eventID, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	var promoted []int
+	for {
+		var rsvpID, userID, guests int
+		err := tx.QueryRow(`
+			SELECT id, user_id, guests FROM rsvps
+			WHERE event_id = $1 AND status = 'waitlisted' AND occurrence_date IS NOT DISTINCT FROM $2
+			ORDER BY waitlisted_at, id
+			LIMIT 1
+		`, eventID, occurrence).Scan(&rsvpID, &userID, &guests)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			log.Printf("Error getting next waitlisted RSVP: %v", err)
+			return nil, err
+		}
+
+		// The waitlist is first come, first served, so a party too big for the
+		// seats left holds up those behind it
+		if capacity.Valid && headcount+1+guests > int(capacity.Int64) {
+			break
+		}
+
+		_, err = tx.Exec(`
+			UPDATE rsvps SET status = 'going', waitlisted_at = NULL, updated_at = NOW()
+			WHERE id = $1
+		`, rsvpID)
+		if err != nil {
+			log.Printf("Error promoting waitlisted RSVP: %v", err)
+			return nil, err
+		}
+
+		promoted = append(promoted, userID)
+		headcount += 1 + guests
+	}
+
+	return promoted, nil
+}
+
+// promoteAllWaitlisted promotes waitlisted users on every occurrence of an event
+// that has a waitlist, for when seats were added to all of them at once
+func promoteAllWaitlisted(tx *sql.Tx, eventID int, capacity sql.NullInt64) ([]models.WaitlistPromotion, error) {
+	rows, err := tx.Query(`
+		SELECT DISTINCT occurrence_date FROM rsvps
+		WHERE event_id = $1 AND status = 'waitlisted'
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting waitlisted occurrences: %v", err)
+		return nil, err
+	}
+
+	var occurrences []*time.Time
+	for rows.Next() {
+		var occurrence *time.Time
+		if err := rows.Scan(&occurrence); err != nil {
+			rows.Close()
+			log.Printf("Error scanning waitlisted occurrence: %v", err)
+			return nil, err
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating waitlisted occurrences: %v", err)
+		return nil, err
+	}
+
+	var promotions []models.WaitlistPromotion
+	for _, occurrence := range occurrences {
+		promoted, err := promoteWaitlisted(tx, eventID, occurrence, capacity)
+		if err != nil {
+			return nil, err
+		}
+		if len(promoted) > 0 {
+			promotions = append(promotions, models.WaitlistPromotion{Occurrence: occurrence, UserIDs: promoted})
+		}
+	}
+	return promotions, nil
+}
+
+// GetRSVPByEventAndUser gets an RSVP by event ID, user ID and occurrence
+func (r *RSVPRepository) GetRSVPByEventAndUser(eventID, userID int, occurrence *time.Time) (*models.RSVP, error) {
 	var rsvp models.RSVP
 	err := r.DB.QueryRow(`
-		SELECT id, event_id, user_id, status, created_at, updated_at
+		SELECT id, event_id, user_id, status, guests, guest_names, occurrence_date, checked_in_at, created_at, updated_at
 		FROM rsvps
-		WHERE event_id = $1 AND user_id = $2
-	`, eventID, userID).Scan(
+		WHERE event_id = $1 AND user_id = $2 AND occurrence_date IS NOT DISTINCT FROM $3
+	`, eventID, userID, occurrence).Scan(
 		&rsvp.ID,
 		&rsvp.EventID,
 		&rsvp.UserID,
 		&rsvp.Status,
+		&rsvp.Guests,
+		pq.Array(&rsvp.GuestNames),
+		&rsvp.OccurrenceDate,
+		&rsvp.CheckedInAt,
 		&rsvp.CreatedAt,
 		&rsvp.UpdatedAt,
 	)
@@ -83,41 +351,104 @@ func (r *RSVPRepository) GetRSVPByEventAndUser(eventID, userID int) (*models.RSV
 	return &rsvp, nil
 }
 
-// GetRSVPs gets all RSVPs for an event
-func (r *RSVPRepository) GetRSVPs(eventID int) ([]models.RSVPWithUser, error) {
+// HasRSVP reports whether the user has an RSVP for any occurrence of the event
+func (r *RSVPRepository) HasRSVP(eventID, userID int) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM rsvps
+			WHERE event_id = $1 AND user_id = $2
+		)
+	`, eventID, userID).Scan(&exists)
+
+	if err != nil {
+		log.Printf("Error checking if RSVP exists: %v", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// IsGoing reports whether the user is going to any occurrence of the event
+func (r *RSVPRepository) IsGoing(eventID, userID int) (bool, error) {
+	var going bool
+	err := r.DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM rsvps
+			WHERE event_id = $1 AND user_id = $2 AND status = 'going'
+		)
+	`, eventID, userID).Scan(&going)
+
+	if err != nil {
+		log.Printf("Error checking if user is going: %v", err)
+		return false, err
+	}
+
+	return going, nil
+}
+
+// rsvpWithUserColumns lists the columns selected for an RSVP joined with its user
+const rsvpWithUserColumns = `r.id, r.event_id, r.user_id, r.status, r.guests, r.guest_names, r.occurrence_date, r.checked_in_at, r.created_at, r.updated_at,
+			   u.first_name, u.last_name, u.email`
+
+// scanRSVPWithUser scans a row selected with rsvpWithUserColumns
+func scanRSVPWithUser(row rowScanner) (models.RSVPWithUser, error) {
+	var rsvp models.RSVPWithUser
+	err := row.Scan(
+		&rsvp.ID,
+		&rsvp.EventID,
+		&rsvp.UserID,
+		&rsvp.Status,
+		&rsvp.Guests,
+		pq.Array(&rsvp.GuestNames),
+		&rsvp.OccurrenceDate,
+		&rsvp.CheckedInAt,
+		&rsvp.CreatedAt,
+		&rsvp.UpdatedAt,
+		&rsvp.FirstName,
+		&rsvp.LastName,
+		&rsvp.Email,
+	)
+	return rsvp, err
+}
+
+// GetRSVPs gets a page of RSVPs for an event, newest first, limited to a single occurrence
+// when occurrence is not nil. It returns the cursor for the next page when there is one.
+func (r *RSVPRepository) GetRSVPs(eventID int, occurrence *time.Time, limit int, after *Cursor) ([]models.RSVPWithUser, *Cursor, error) {
+	if after != nil && after.Sort != rsvpSort {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	// Newest first; the cursor keeps the position of the last RSVP seen so new
+	// RSVPs never shift later pages
+	var afterDate *time.Time
+	var afterID int
+	if after != nil {
+		afterDate, afterID = &after.Date, after.ID
+	}
+
 	rows, err := r.DB.Query(`
-		SELECT r.id, r.event_id, r.user_id, r.status, r.created_at, r.updated_at,
-			   u.first_name, u.last_name, u.email
+		SELECT `+rsvpWithUserColumns+`
 		FROM rsvps r
 		JOIN users u ON r.user_id = u.id
-		WHERE r.event_id = $1
-		ORDER BY r.created_at DESC
-	`, eventID)
+		WHERE r.event_id = $1 AND ($2::timestamptz IS NULL OR r.occurrence_date = $2)
+			AND ($3::timestamptz IS NULL OR (r.created_at, r.id) < ($3::timestamptz, $4::integer))
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $5
+	`, eventID, occurrence, afterDate, afterID, limit+1)
 
 	if err != nil {
 		log.Printf("Error getting RSVPs: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var rsvps []models.RSVPWithUser
+	rsvps := []models.RSVPWithUser{}
 	for rows.Next() {
-		var rsvp models.RSVPWithUser
-		err := rows.Scan(
-			&rsvp.ID,
-			&rsvp.EventID,
-			&rsvp.UserID,
-			&rsvp.Status,
-			&rsvp.CreatedAt,
-			&rsvp.UpdatedAt,
-			&rsvp.FirstName,
-			&rsvp.LastName,
-			&rsvp.Email,
-		)
-
+		rsvp, err := scanRSVPWithUser(rows)
 		if err != nil {
 			log.Printf("Error scanning RSVP row: %v", err)
-			return nil, err
+			return nil, nil, err
 		}
 
 		rsvps = append(rsvps, rsvp)
@@ -125,22 +456,107 @@ func (r *RSVPRepository) GetRSVPs(eventID int) ([]models.RSVPWithUser, error) {
 
 	if err = rows.Err(); err != nil {
 		log.Printf("Error iterating RSVP rows: %v", err)
+		return nil, nil, err
+	}
+
+	if len(rsvps) <= limit {
+		return rsvps, nil, nil
+	}
+	rsvps = rsvps[:limit]
+	last := rsvps[limit-1]
+	return rsvps, &Cursor{Sort: rsvpSort, Date: last.CreatedAt, ID: last.ID}, nil
+}
+
+// GetRSVPsByStatus gets the RSVPs for every occurrence of an event with one of the
+// given statuses, oldest first
+func (r *RSVPRepository) GetRSVPsByStatus(eventID int, statuses []string) ([]models.RSVPWithUser, error) {
+	return r.queryRSVPsWithUser(`
+		WHERE r.event_id = $1 AND r.status = ANY($2)
+		ORDER BY r.created_at, r.id
+	`, eventID, pq.Array(statuses))
+}
+
+// GetUpcomingRSVPsByStatus gets the RSVPs with one of the given statuses to an
+// event, or to the occurrences of a recurring event that haven't started yet,
+// ordered by occurrence
+func (r *RSVPRepository) GetUpcomingRSVPsByStatus(eventID int, statuses []string) ([]models.RSVPWithUser, error) {
+	return r.queryRSVPsWithUser(`
+		WHERE r.event_id = $1 AND r.status = ANY($2)
+			AND (r.occurrence_date IS NULL OR r.occurrence_date > NOW())
+		ORDER BY r.occurrence_date, r.created_at, r.id
+	`, eventID, pq.Array(statuses))
+}
+
+// queryRSVPsWithUser runs a query for RSVPs joined with their user using the
+// given WHERE and ORDER BY clauses
+func (r *RSVPRepository) queryRSVPsWithUser(where string, args ...interface{}) ([]models.RSVPWithUser, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+rsvpWithUserColumns+`
+		FROM rsvps r
+		JOIN users u ON r.user_id = u.id
+	`+where, args...)
+	if err != nil {
+		log.Printf("Error getting RSVPs by status: %v", err)
 		return nil, err
 	}
+	defer rows.Close()
+
+	rsvps := []models.RSVPWithUser{}
+	for rows.Next() {
+		rsvp, err := scanRSVPWithUser(rows)
+		if err != nil {
+			log.Printf("Error scanning RSVP row: %v", err)
+			return nil, err
+		}
+		rsvps = append(rsvps, rsvp)
+	}
+
+	return rsvps, rows.Err()
+}
+
+// GetAllRSVPs gets every RSVP for an event, oldest first, limited to a single
+// occurrence when occurrence is not nil
+func (r *RSVPRepository) GetAllRSVPs(eventID int, occurrence *time.Time) ([]models.RSVPWithUser, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+rsvpWithUserColumns+`
+		FROM rsvps r
+		JOIN users u ON r.user_id = u.id
+		WHERE r.event_id = $1 AND ($2::timestamptz IS NULL OR r.occurrence_date = $2)
+		ORDER BY r.created_at, r.id
+	`, eventID, occurrence)
+	if err != nil {
+		log.Printf("Error getting all RSVPs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rsvps := []models.RSVPWithUser{}
+	for rows.Next() {
+		rsvp, err := scanRSVPWithUser(rows)
+		if err != nil {
+			log.Printf("Error scanning RSVP row: %v", err)
+			return nil, err
+		}
+		rsvps = append(rsvps, rsvp)
+	}
 
-	return rsvps, nil
+	return rsvps, rows.Err()
 }
 
-// GetRSVPCount gets the count of RSVPs by status for an event
-func (r *RSVPRepository) GetRSVPCount(eventID int) (models.RSVPCount, error) {
+// rsvpSort identifies cursors for RSVP listings, which are ordered by creation time
+const rsvpSort = "created"
+
+// GetRSVPCount gets the count of RSVPs by status for an event occurrence
+func (r *RSVPRepository) GetRSVPCount(eventID int, occurrence *time.Time) (models.RSVPCount, error) {
 	var count models.RSVPCount
 	count.EventID = eventID
+	count.OccurrenceDate = occurrence
 
-	// Get going count
+	// Get going count along with the guests they bring
 	err := r.DB.QueryRow(`
-		SELECT COUNT(*) FROM rsvps
-		WHERE event_id = $1 AND status = 'going'
-	`, eventID).Scan(&count.Going)
+		SELECT COUNT(*), COALESCE(SUM(guests), 0) FROM rsvps
+		WHERE event_id = $1 AND status = 'going' AND occurrence_date IS NOT DISTINCT FROM $2
+	`, eventID, occurrence).Scan(&count.Going, &count.Guests)
 
 	if err != nil {
 		log.Printf("Error getting going count: %v", err)
@@ -150,8 +566,8 @@ func (r *RSVPRepository) GetRSVPCount(eventID int) (models.RSVPCount, error) {
 	// Get maybe count
 	err = r.DB.QueryRow(`
 		SELECT COUNT(*) FROM rsvps
-		WHERE event_id = $1 AND status = 'maybe'
-	`, eventID).Scan(&count.Maybe)
+		WHERE event_id = $1 AND status = 'maybe' AND occurrence_date IS NOT DISTINCT FROM $2
+	`, eventID, occurrence).Scan(&count.Maybe)
 
 	if err != nil {
 		log.Printf("Error getting maybe count: %v", err)
@@ -161,28 +577,256 @@ func (r *RSVPRepository) GetRSVPCount(eventID int) (models.RSVPCount, error) {
 	// Get not going count
 	err = r.DB.QueryRow(`
 		SELECT COUNT(*) FROM rsvps
-		WHERE event_id = $1 AND status = 'not_going'
-	`, eventID).Scan(&count.NotGoing)
+		WHERE event_id = $1 AND status = 'not_going' AND occurrence_date IS NOT DISTINCT FROM $2
+	`, eventID, occurrence).Scan(&count.NotGoing)
 
 	if err != nil {
 		log.Printf("Error getting not going count: %v", err)
 		return count, err
 	}
 
+	// Get pending count
+	err = r.DB.QueryRow(`
+		SELECT COUNT(*) FROM rsvps
+		WHERE event_id = $1 AND status = 'pending' AND occurrence_date IS NOT DISTINCT FROM $2
+	`, eventID, occurrence).Scan(&count.Pending)
+
+	if err != nil {
+		log.Printf("Error getting pending count: %v", err)
+		return count, err
+	}
+
+	// Get waitlisted count
+	err = r.DB.QueryRow(`
+		SELECT COUNT(*) FROM rsvps
+		WHERE event_id = $1 AND status = 'waitlisted' AND occurrence_date IS NOT DISTINCT FROM $2
+	`, eventID, occurrence).Scan(&count.Waitlisted)
+
+	if err != nil {
+		log.Printf("Error getting waitlisted count: %v", err)
+		return count, err
+	}
+
+	// Get checked-in count
+	err = r.DB.QueryRow(`
+		SELECT COUNT(*) FROM rsvps
+		WHERE event_id = $1 AND status = 'going' AND checked_in_at IS NOT NULL AND occurrence_date IS NOT DISTINCT FROM $2
+	`, eventID, occurrence).Scan(&count.CheckedIn)
+
+	if err != nil {
+		log.Printf("Error getting checked-in count: %v", err)
+		return count, err
+	}
+
+	// Get remaining seats for events with a capacity
+	err = r.DB.QueryRow("SELECT capacity FROM events WHERE id = $1", eventID).Scan(&count.Capacity)
+	if err != nil {
+		log.Printf("Error getting event capacity: %v", err)
+		return count, err
+	}
+
+	count.Headcount = count.Going + count.Guests
+	if count.Capacity != nil {
+		remaining := *count.Capacity - count.Headcount
+		if remaining < 0 {
+			remaining = 0
+		}
+		count.RemainingSeats = &remaining
+	}
+
 	return count, nil
 }
 
-// DeleteRSVP deletes an RSVP
-func (r *RSVPRepository) DeleteRSVP(eventID, userID int) error {
-	_, err := r.DB.Exec(`
-		DELETE FROM rsvps
-		WHERE event_id = $1 AND user_id = $2
-	`, eventID, userID)
+// ReviewRSVPs approves or declines the event's pending RSVPs with the given IDs,
+// oldest first. Approved attendees are going if they fit, together with their
+// guests, and nobody is on the waitlist ahead of them. Otherwise they join the
+// waitlist. Declined RSVPs can also be approved later,
+// and any other RSVPs are skipped. It returns the RSVPs that were reviewed with
+// their new status.
+func (r *RSVPRepository) ReviewRSVPs(eventID int, rsvpIDs []int, approve bool) ([]models.RSVP, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting RSVP review transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	capacity, err := lockEventCapacity(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, event_id, user_id, guests, occurrence_date
+		FROM rsvps
+		WHERE event_id = $1 AND id = ANY($2) AND (status = 'pending' OR ($3 AND status = 'declined'))
+		ORDER BY created_at, id
+		FOR UPDATE
+	`, eventID, pq.Array(rsvpIDs), approve)
+	if err != nil {
+		log.Printf("Error getting pending RSVPs: %v", err)
+		return nil, err
+	}
+
+	reviewed := []models.RSVP{}
+	for rows.Next() {
+		var rsvp models.RSVP
+		if err := rows.Scan(&rsvp.ID, &rsvp.EventID, &rsvp.UserID, &rsvp.Guests, &rsvp.OccurrenceDate); err != nil {
+			rows.Close()
+			log.Printf("Error scanning pending RSVP row: %v", err)
+			return nil, err
+		}
+		reviewed = append(reviewed, rsvp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating pending RSVP rows: %v", err)
+		return nil, err
+	}
+
+	for i := range reviewed {
+		rsvp := &reviewed[i]
+		rsvp.Status = "declined"
+		if approve {
+			rsvp.Status = "going"
+			if capacity.Valid {
+				headcount, err := countHeadcount(tx, eventID, rsvp.OccurrenceDate)
+				if err != nil {
+					return nil, err
+				}
+				full := headcount+1+rsvp.Guests > int(capacity.Int64)
+				if !full {
+					if full, err = hasWaitlistAhead(tx, eventID, rsvp.UserID, rsvp.OccurrenceDate); err != nil {
+						return nil, err
+					}
+				}
+				if full {
+					rsvp.Status = "waitlisted"
+				}
+			}
+		}
+
+		err = tx.QueryRow(`
+			UPDATE rsvps
+			SET status = $1::varchar,
+				waitlisted_at = CASE WHEN $1::varchar = 'waitlisted' THEN COALESCE(waitlisted_at, NOW()) END,
+				updated_at = NOW()
+			WHERE id = $2
+			RETURNING created_at, updated_at
+		`, rsvp.Status, rsvp.ID).Scan(&rsvp.CreatedAt, &rsvp.UpdatedAt)
+		if err != nil {
+			log.Printf("Error reviewing RSVP: %v", err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing RSVP review: %v", err)
+		return nil, err
+	}
+
+	return reviewed, nil
+}
+
+// CheckIn records that the attendee with a "going" RSVP to an occurrence has
+// arrived, where occurrence is nil for one-off events. It returns, along with the
+// RSVP, ErrWrongOccurrence if the RSVP is for another occurrence, ErrNotGoing if
+// it is not "going", and ErrAlreadyCheckedIn if the attendee was already checked
+// in. sql.ErrNoRows is returned if the event has no such RSVP.
+func (r *RSVPRepository) CheckIn(eventID, rsvpID int, occurrence *time.Time) (*models.RSVPWithUser, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting check-in transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rsvp, err := scanRSVPWithUser(tx.QueryRow(`
+		SELECT `+rsvpWithUserColumns+`
+		FROM rsvps r
+		JOIN users u ON r.user_id = u.id
+		WHERE r.id = $1 AND r.event_id = $2
+		FOR UPDATE OF r
+	`, rsvpID, eventID))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting RSVP for check-in: %v", err)
+		}
+		return nil, err
+	}
+
+	if (rsvp.OccurrenceDate == nil) != (occurrence == nil) ||
+		(occurrence != nil && !rsvp.OccurrenceDate.Equal(*occurrence)) {
+		return &rsvp, ErrWrongOccurrence
+	}
+	if rsvp.Status != "going" {
+		return &rsvp, ErrNotGoing
+	}
+	if rsvp.CheckedInAt != nil {
+		return &rsvp, ErrAlreadyCheckedIn
+	}
+
+	err = tx.QueryRow(`
+		UPDATE rsvps SET checked_in_at = NOW() WHERE id = $1
+		RETURNING checked_in_at
+	`, rsvpID).Scan(&rsvp.CheckedInAt)
+	if err != nil {
+		log.Printf("Error checking in RSVP: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing check-in: %v", err)
+		return nil, err
+	}
 
+	return &rsvp, nil
+}
+
+// DeleteRSVP deletes an RSVP and, when promote is set, promotes waitlisted users
+// into a freed seat. It returns the IDs of promoted users.
+func (r *RSVPRepository) DeleteRSVP(eventID, userID int, occurrence *time.Time, promote bool) ([]int, error) {
+	tx, err := r.DB.Begin()
 	if err != nil {
+		log.Printf("Error starting RSVP transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	capacity, err := lockEventCapacity(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var previousStatus string
+	err = tx.QueryRow(`
+		DELETE FROM rsvps
+		WHERE event_id = $1 AND user_id = $2 AND occurrence_date IS NOT DISTINCT FROM $3
+		RETURNING status
+	`, eventID, userID, occurrence).Scan(&previousStatus)
+
+	if err != nil && err != sql.ErrNoRows {
 		log.Printf("Error deleting RSVP: %v", err)
-		return err
+		return nil, err
+	}
+
+	var promoted []int
+	if previousStatus == "going" {
+		if err := leaveEventSessions(tx, eventID, userID, occurrence); err != nil {
+			return nil, err
+		}
+		if promote {
+			promoted, err = promoteWaitlisted(tx, eventID, occurrence, capacity)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing RSVP deletion: %v", err)
+		return nil, err
 	}
 
-	return nil
+	return promoted, nil
 }
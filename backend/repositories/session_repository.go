@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// ErrSessionFull is returned when a session has no seats left
+var ErrSessionFull = errors.New("session is full")
+
+// sessionColumns selects a session along with its attendee count and whether the
+// user given as $2 is attending
+const sessionColumns = `s.id, s.event_id, s.title, s.description, s.speaker, s.start_time, s.end_time,
+	s.room, s.track, s.capacity, s.created_at, s.updated_at,
+	(SELECT COUNT(*) FROM session_rsvps sr WHERE sr.session_id = s.id),
+	EXISTS(SELECT 1 FROM session_rsvps sr WHERE sr.session_id = s.id AND sr.user_id = $2)`
+
+// SessionRepository handles database operations for event sessions and the
+// attendees who join them
+type SessionRepository struct {
+	DB *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{DB: db}
+}
+
+// CreateSession adds a session to an event
+func (r *SessionRepository) CreateSession(eventID int, req models.SessionRequest) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`
+		INSERT INTO event_sessions (event_id, title, description, speaker, start_time, end_time, room, track, capacity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, eventID, req.Title, req.Description, req.Speaker, req.StartTime, req.EndTime, req.Room, req.Track, req.Capacity).Scan(&id)
+
+	if err != nil {
+		log.Printf("Error creating session: %v", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetSessions gets the sessions of an event in agenda order. It is limited to a
+// track when track is not empty, and to the sessions userID is attending when
+// mine is true.
+func (r *SessionRepository) GetSessions(eventID, userID int, track string, mine bool) ([]models.EventSession, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+sessionColumns+`
+		FROM event_sessions s
+		WHERE s.event_id = $1
+			AND ($3 = '' OR s.track = $3)
+			AND (NOT $4 OR EXISTS(SELECT 1 FROM session_rsvps sr WHERE sr.session_id = s.id AND sr.user_id = $2))
+		ORDER BY s.start_time, s.room, s.id
+	`, eventID, userID, track, mine)
+	if err != nil {
+		log.Printf("Error getting sessions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.EventSession{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			log.Printf("Error scanning session row: %v", err)
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetTracks gets the names of the tracks used by an event's sessions
+func (r *SessionRepository) GetTracks(eventID int) ([]string, error) {
+	rows, err := r.DB.Query(`
+		SELECT DISTINCT track FROM event_sessions
+		WHERE event_id = $1 AND track <> ''
+		ORDER BY track
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting tracks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tracks := []string{}
+	for rows.Next() {
+		var track string
+		if err := rows.Scan(&track); err != nil {
+			log.Printf("Error scanning track row: %v", err)
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+
+	return tracks, rows.Err()
+}
+
+// GetSessionByID gets a session of an event as seen by userID
+func (r *SessionRepository) GetSessionByID(eventID, sessionID, userID int) (*models.EventSession, error) {
+	session, err := scanSession(r.DB.QueryRow(`
+		SELECT `+sessionColumns+`
+		FROM event_sessions s
+		WHERE s.id = $1 AND s.event_id = $3
+	`, sessionID, userID, eventID))
+
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting session: %v", err)
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// UpdateSession replaces the details of a session
+func (r *SessionRepository) UpdateSession(sessionID int, req models.SessionRequest) error {
+	_, err := r.DB.Exec(`
+		UPDATE event_sessions
+		SET title = $1, description = $2, speaker = $3, start_time = $4, end_time = $5,
+			room = $6, track = $7, capacity = $8, updated_at = NOW()
+		WHERE id = $9
+	`, req.Title, req.Description, req.Speaker, req.StartTime, req.EndTime, req.Room, req.Track, req.Capacity, sessionID)
+
+	if err != nil {
+		log.Printf("Error updating session: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSession deletes a session along with its attendee list
+func (r *SessionRepository) DeleteSession(sessionID int) error {
+	_, err := r.DB.Exec("DELETE FROM event_sessions WHERE id = $1", sessionID)
+	if err != nil {
+		log.Printf("Error deleting session: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// JoinSession adds a user to a session's attendees. Capacity is enforced in a
+// transaction and ErrSessionFull is returned when there are no seats left.
+// Joining a session twice has no effect.
+func (r *SessionRepository) JoinSession(sessionID, userID int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting session RSVP transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the session so concurrent joins cannot overfill it
+	var capacity sql.NullInt64
+	err = tx.QueryRow("SELECT capacity FROM event_sessions WHERE id = $1 FOR UPDATE", sessionID).Scan(&capacity)
+	if err != nil {
+		log.Printf("Error locking session: %v", err)
+		return err
+	}
+
+	var attending bool
+	var count int
+	err = tx.QueryRow(`
+		SELECT COALESCE(BOOL_OR(user_id = $2), false), COUNT(*)
+		FROM session_rsvps WHERE session_id = $1
+	`, sessionID, userID).Scan(&attending, &count)
+	if err != nil {
+		log.Printf("Error counting session attendees: %v", err)
+		return err
+	}
+
+	if attending {
+		return nil
+	}
+	if capacity.Valid && count >= int(capacity.Int64) {
+		return ErrSessionFull
+	}
+
+	_, err = tx.Exec("INSERT INTO session_rsvps (session_id, user_id) VALUES ($1, $2)", sessionID, userID)
+	if err != nil {
+		log.Printf("Error joining session: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing session RSVP: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// LeaveSession removes a user from a session's attendees
+func (r *SessionRepository) LeaveSession(sessionID, userID int) error {
+	_, err := r.DB.Exec("DELETE FROM session_rsvps WHERE session_id = $1 AND user_id = $2", sessionID, userID)
+	if err != nil {
+		log.Printf("Error leaving session: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// leaveEventSessions removes a user from the sessions of an occurrence of an
+// event, used when they are no longer going to it. occurrence is nil for one-off
+// events, whose sessions are all left. Otherwise only the sessions starting
+// during the occurrence are left, since attendees may still go to others.
+func leaveEventSessions(tx *sql.Tx, eventID, userID int, occurrence *time.Time) error {
+	_, err := tx.Exec(`
+		DELETE FROM session_rsvps
+		WHERE user_id = $2 AND session_id IN (
+			SELECT s.id FROM event_sessions s
+			JOIN events e ON e.id = s.event_id
+			WHERE s.event_id = $1 AND ($3::timestamptz IS NULL OR (
+				s.start_time >= $3 AND s.start_time < $3 + COALESCE(e.end_date - e.date, INTERVAL '1 day')
+			))
+		)
+	`, eventID, userID, occurrence)
+	if err != nil {
+		log.Printf("Error leaving event sessions: %v", err)
+	}
+	return err
+}
+
+// scanSession scans a row selected with sessionColumns
+func scanSession(row rowScanner) (models.EventSession, error) {
+	var session models.EventSession
+	err := row.Scan(
+		&session.ID,
+		&session.EventID,
+		&session.Title,
+		&session.Description,
+		&session.Speaker,
+		&session.StartTime,
+		&session.EndTime,
+		&session.Room,
+		&session.Track,
+		&session.Capacity,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+		&session.AttendeeCount,
+		&session.Attending,
+	)
+	if err == nil && session.Capacity != nil {
+		remaining := *session.Capacity - session.AttendeeCount
+		if remaining < 0 {
+			remaining = 0
+		}
+		session.RemainingSeats = &remaining
+	}
+	return session, err
+}
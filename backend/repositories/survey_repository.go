@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// SurveyRepository handles database operations for post-event surveys and the
+// feedback attendees give in them
+type SurveyRepository struct {
+	DB *sql.DB
+}
+
+func NewSurveyRepository(db *sql.DB) *SurveyRepository {
+	return &SurveyRepository{DB: db}
+}
+
+// SaveResponse stores an attendee's feedback on an event, replacing the feedback
+// they gave before
+func (r *SurveyRepository) SaveResponse(eventID, userID int, req models.SurveyResponseRequest) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO survey_responses (event_id, user_id, rating, comment)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id, user_id)
+		DO UPDATE SET rating = $3, comment = $4, updated_at = NOW()
+		RETURNING id
+	`, eventID, userID, req.Rating, req.Comment).Scan(&id)
+	if err != nil {
+		log.Printf("Error saving survey response: %v", err)
+		return 0, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM survey_answers WHERE response_id = $1", id); err != nil {
+		log.Printf("Error clearing survey answers: %v", err)
+		return 0, err
+	}
+	for _, answer := range req.Answers {
+		choices := answer.Choices
+		if choices == nil {
+			choices = []string{}
+		}
+		_, err := tx.Exec(`
+			INSERT INTO survey_answers (response_id, question_id, text, choices)
+			VALUES ($1, $2, $3, $4)
+		`, id, answer.QuestionID, answer.Text, pq.Array(choices))
+		if err != nil {
+			log.Printf("Error saving survey answer: %v", err)
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+// GetResponse gets an attendee's feedback on an event with their answers
+func (r *SurveyRepository) GetResponse(eventID, userID int) (*models.SurveyResponse, error) {
+	var response models.SurveyResponse
+	err := r.DB.QueryRow(`
+		SELECT id, event_id, user_id, rating, comment, created_at, updated_at
+		FROM survey_responses
+		WHERE event_id = $1 AND user_id = $2
+	`, eventID, userID).Scan(
+		&response.ID,
+		&response.EventID,
+		&response.UserID,
+		&response.Rating,
+		&response.Comment,
+		&response.CreatedAt,
+		&response.UpdatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting survey response: %v", err)
+		}
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT a.question_id, a.text, a.choices
+		FROM survey_answers a
+		JOIN survey_questions q ON q.id = a.question_id
+		WHERE a.response_id = $1
+		ORDER BY q.position, q.id
+	`, response.ID)
+	if err != nil {
+		log.Printf("Error getting survey answers: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	response.Answers = []models.RSVPAnswer{}
+	for rows.Next() {
+		answer, err := scanAnswer(rows)
+		if err != nil {
+			log.Printf("Error scanning survey answer row: %v", err)
+			return nil, err
+		}
+		response.Answers = append(response.Answers, answer)
+	}
+
+	return &response, rows.Err()
+}
+
+// GetResults aggregates the responses to an event's survey: the average rating
+// and how many times each rating was given, the comments, newest first, and the
+// answers to each of the questions
+func (r *SurveyRepository) GetResults(eventID int, questions []models.EventQuestion) (*models.SurveyResults, error) {
+	results := &models.SurveyResults{
+		EventID:   eventID,
+		Ratings:   map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+		Comments:  []models.SurveyComment{},
+		Questions: []models.SurveyQuestionResults{},
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT rating, comment, created_at
+		FROM survey_responses
+		WHERE event_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting survey responses: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	total := 0
+	for rows.Next() {
+		var comment models.SurveyComment
+		if err := rows.Scan(&comment.Rating, &comment.Comment, &comment.CreatedAt); err != nil {
+			log.Printf("Error scanning survey response row: %v", err)
+			return nil, err
+		}
+		results.ResponseCount++
+		results.Ratings[comment.Rating]++
+		total += comment.Rating
+		if comment.Comment != "" {
+			results.Comments = append(results.Comments, comment)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating survey response rows: %v", err)
+		return nil, err
+	}
+	if results.ResponseCount > 0 {
+		average := float64(total) / float64(results.ResponseCount)
+		results.AverageRating = &average
+	}
+
+	byQuestion := map[int]*models.SurveyQuestionResults{}
+	for _, question := range questions {
+		results.Questions = append(results.Questions, models.SurveyQuestionResults{Question: question})
+	}
+	for i := range results.Questions {
+		question := &results.Questions[i]
+		if question.Question.Type == models.QuestionTypeText {
+			question.Texts = []string{}
+		} else {
+			question.Choices = map[string]int{}
+			for _, option := range question.Question.Options {
+				question.Choices[option] = 0
+			}
+		}
+		byQuestion[question.Question.ID] = question
+	}
+
+	answerRows, err := r.DB.Query(`
+		SELECT a.question_id, a.text, a.choices
+		FROM survey_answers a
+		JOIN survey_responses s ON s.id = a.response_id
+		WHERE s.event_id = $1
+		ORDER BY s.created_at DESC, s.id DESC
+	`, eventID)
+	if err != nil {
+		log.Printf("Error getting survey answers: %v", err)
+		return nil, err
+	}
+	defer answerRows.Close()
+
+	for answerRows.Next() {
+		answer, err := scanAnswer(answerRows)
+		if err != nil {
+			log.Printf("Error scanning survey answer row: %v", err)
+			return nil, err
+		}
+		question, ok := byQuestion[answer.QuestionID]
+		if !ok {
+			continue
+		}
+		question.AnswerCount++
+		if question.Question.Type == models.QuestionTypeText {
+			question.Texts = append(question.Texts, answer.Text)
+			continue
+		}
+		// Options removed since the answer was given are still counted
+		for _, choice := range answer.Choices {
+			question.Choices[choice]++
+		}
+	}
+
+	return results, answerRows.Err()
+}
+
+// ClaimDueSurveys gets the events whose surveys are due to be sent, with their
+// organizer's details, and marks them as sent so each survey goes out once
+func (r *SurveyRepository) ClaimDueSurveys() ([]models.Event, error) {
+	rows, err := r.DB.Query(`
+		UPDATE events e SET surveys_due = false
+		FROM users u
+		WHERE e.surveys_due AND e.deleted_at IS NULL AND u.id = e.user_id
+		RETURNING e.id, e.title, e.date, e.end_date, e.timezone, e.location, e.user_id, u.email, u.first_name, u.last_name
+	`)
+	if err != nil {
+		log.Printf("Error claiming due surveys: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(
+			&event.ID,
+			&event.Title,
+			&event.Date,
+			&event.EndDate,
+			&event.TimeZone,
+			&event.Location,
+			&event.UserID,
+			&event.OrganizerEmail,
+			&event.OrganizerFirstName,
+			&event.OrganizerLastName,
+		); err != nil {
+			log.Printf("Error scanning due survey row: %v", err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetOrganizerRating gets the average survey rating across all the events a user
+// organized and the number of ratings it is based on. The average is nil when
+// there are no ratings.
+func (r *SurveyRepository) GetOrganizerRating(userID int) (*float64, int, error) {
+	var average sql.NullFloat64
+	var count int
+	err := r.DB.QueryRow(`
+		SELECT AVG(s.rating)::double precision, COUNT(s.id)
+		FROM survey_responses s
+		JOIN events e ON e.id = s.event_id
+		WHERE e.user_id = $1 AND e.deleted_at IS NULL
+	`, userID).Scan(&average, &count)
+	if err != nil {
+		log.Printf("Error getting organizer rating: %v", err)
+		return nil, 0, err
+	}
+
+	if !average.Valid {
+		return nil, 0, nil
+	}
+	return &average.Float64, count, nil
+}
+
+// scanAnswer scans the question ID, text and choices of an answer
+func scanAnswer(row rowScanner) (models.RSVPAnswer, error) {
+	var answer models.RSVPAnswer
+	err := row.Scan(&answer.QuestionID, &answer.Text, pq.Array(&answer.Choices))
+	if len(answer.Choices) == 0 {
+		answer.Choices = nil
+	}
+	return answer, err
+}
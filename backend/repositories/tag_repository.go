@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// TagRepository handles database operations for tags
+type TagRepository struct {
+	DB *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{DB: db}
+}
+
+// GetTags gets the tags used by listed public events along with how many events use each,
+// most used first
+func (r *TagRepository) GetTags() ([]models.Tag, error) {
+	rows, err := r.DB.Query(`
+		SELECT t.id, t.name, COUNT(e.id) AS event_count
+		FROM tags t
+		JOIN event_tags et ON et.tag_id = t.id
+		JOIN events e ON e.id = et.event_id AND e.visibility = 'public' AND e.deleted_at IS NULL AND ` + listedStatusCondition + `
+		GROUP BY t.id, t.name
+		ORDER BY event_count DESC, t.name
+	`)
+	if err != nil {
+		log.Printf("Error getting tags: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.EventCount); err != nil {
+			log.Printf("Error scanning tag row: %v", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
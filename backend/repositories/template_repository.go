@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// ErrTemplateNameTaken is returned when a user already has a template with the same name
+var ErrTemplateNameTaken = errors.New("template name already in use")
+
+// TemplateRepository handles database operations for event templates
+type TemplateRepository struct {
+	DB *sql.DB
+}
+
+func NewTemplateRepository(db *sql.DB) *TemplateRepository {
+	return &TemplateRepository{DB: db}
+}
+
+// CreateTemplate stores a new template for a user
+func (r *TemplateRepository) CreateTemplate(userID int, name string, fields json.RawMessage) (*models.EventTemplate, error) {
+	template := models.EventTemplate{
+		UserID: userID,
+		Name:   name,
+		Fields: fields,
+	}
+	err := r.DB.QueryRow(`
+		INSERT INTO event_templates (user_id, name, fields)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, userID, name, []byte(fields)).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTemplateNameTaken
+		}
+		log.Printf("Error creating template: %v", err)
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// GetTemplates gets a user's templates in alphabetical order
+func (r *TemplateRepository) GetTemplates(userID int) ([]models.EventTemplate, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, user_id, name, fields, created_at, updated_at
+		FROM event_templates
+		WHERE user_id = $1
+		ORDER BY name
+	`, userID)
+	if err != nil {
+		log.Printf("Error getting templates: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.EventTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			log.Printf("Error scanning template row: %v", err)
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+// GetTemplateByID gets a single template
+func (r *TemplateRepository) GetTemplateByID(id int) (*models.EventTemplate, error) {
+	template, err := scanTemplate(r.DB.QueryRow(`
+		SELECT id, user_id, name, fields, created_at, updated_at
+		FROM event_templates
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting template: %v", err)
+		}
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// UpdateTemplate renames a template and replaces its fields
+func (r *TemplateRepository) UpdateTemplate(id int, name string, fields json.RawMessage) error {
+	_, err := r.DB.Exec(`
+		UPDATE event_templates SET name = $1, fields = $2, updated_at = NOW()
+		WHERE id = $3
+	`, name, []byte(fields), id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrTemplateNameTaken
+		}
+		log.Printf("Error updating template: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteTemplate deletes a template
+func (r *TemplateRepository) DeleteTemplate(id int) error {
+	_, err := r.DB.Exec("DELETE FROM event_templates WHERE id = $1", id)
+	if err != nil {
+		log.Printf("Error deleting template: %v", err)
+		return err
+	}
+	return nil
+}
+
+// scanTemplate scans a row of event_templates
+func scanTemplate(row rowScanner) (models.EventTemplate, error) {
+	var template models.EventTemplate
+	var fields []byte
+	err := row.Scan(
+		&template.ID,
+		&template.UserID,
+		&template.Name,
+		&fields,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	template.Fields = fields
+	return template, err
+}
+
+// isUniqueViolation reports whether err was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/lib/pq"
+)
+
+// ErrRoomNameTaken is returned when a venue already has a room with the same name
+var ErrRoomNameTaken = errors.New("room name already in use")
+
+// VenueRepository handles database operations for venues and their rooms
+type VenueRepository struct {
+	DB *sql.DB
+}
+
+func NewVenueRepository(db *sql.DB) *VenueRepository {
+	return &VenueRepository{DB: db}
+}
+
+// CreateVenue adds a venue managed by the given user
+func (r *VenueRepository) CreateVenue(req models.VenueRequest, userID int) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`
+		INSERT INTO venues (name, address, capacity, user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, req.Name, req.Address, req.Capacity, userID).Scan(&id)
+
+	if err != nil {
+		log.Printf("Error creating venue: %v", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetVenues gets the venues whose name or address contains query, or all venues
+// when it is empty, in alphabetical order along with their rooms
+func (r *VenueRepository) GetVenues(query string) ([]models.Venue, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, name, address, capacity, user_id, created_at, updated_at
+		FROM venues
+		WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR address ILIKE '%' || $1 || '%'
+		ORDER BY name, id
+	`, query)
+	if err != nil {
+		log.Printf("Error getting venues: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	venues := []models.Venue{}
+	for rows.Next() {
+		venue, err := scanVenue(rows)
+		if err != nil {
+			log.Printf("Error scanning venue row: %v", err)
+			return nil, err
+		}
+		venues = append(venues, venue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	venueIDs := make([]int, len(venues))
+	for i, venue := range venues {
+		venueIDs[i] = venue.ID
+	}
+
+	rooms, err := r.getRooms(venueIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range venues {
+		if venueRooms, ok := rooms[venues[i].ID]; ok {
+			venues[i].Rooms = venueRooms
+		}
+	}
+
+	return venues, nil
+}
+
+// GetVenueByID gets a single venue along with its rooms
+func (r *VenueRepository) GetVenueByID(id int) (*models.Venue, error) {
+	venue, err := scanVenue(r.DB.QueryRow(`
+		SELECT id, name, address, capacity, user_id, created_at, updated_at
+		FROM venues
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting venue: %v", err)
+		}
+		return nil, err
+	}
+
+	rooms, err := r.getRooms([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if venueRooms, ok := rooms[id]; ok {
+		venue.Rooms = venueRooms
+	}
+
+	return &venue, nil
+}
+
+// UpdateVenue replaces the details of a venue and bumps the version of the events
+// booked there, since they show the venue
+func (r *VenueRepository) UpdateVenue(id int, req models.VenueRequest) error {
+	_, err := r.DB.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE venue_id = $4)
+		UPDATE venues SET name = $1, address = $2, capacity = $3, updated_at = NOW()
+		WHERE id = $4
+	`, req.Name, req.Address, req.Capacity, id)
+	if err != nil {
+		log.Printf("Error updating venue: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteVenue deletes a venue and its rooms. Events booked there keep their
+// location but no longer hold the venue, and their version is bumped.
+func (r *VenueRepository) DeleteVenue(id int) error {
+	_, err := r.DB.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE venue_id = $1)
+		DELETE FROM venues WHERE id = $1
+	`, id)
+	if err != nil {
+		log.Printf("Error deleting venue: %v", err)
+		return err
+	}
+	return nil
+}
+
+// CreateRoom adds a room to a venue
+func (r *VenueRepository) CreateRoom(venueID int, req models.VenueRoomRequest) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`
+		INSERT INTO venue_rooms (venue_id, name, capacity)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, venueID, req.Name, req.Capacity).Scan(&id)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrRoomNameTaken
+		}
+		log.Printf("Error creating room: %v", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateRoom replaces the details of a room and bumps the version of the events
+// booked in it
+func (r *VenueRepository) UpdateRoom(roomID int, req models.VenueRoomRequest) error {
+	_, err := r.DB.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE room_id = $3)
+		UPDATE venue_rooms SET name = $1, capacity = $2, updated_at = NOW()
+		WHERE id = $3
+	`, req.Name, req.Capacity, roomID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrRoomNameTaken
+		}
+		log.Printf("Error updating room: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteRoom deletes a room. Events booked in it no longer hold the venue, and
+// their version is bumped.
+func (r *VenueRepository) DeleteRoom(roomID int) error {
+	_, err := r.DB.Exec(`
+		WITH bumped AS (UPDATE events SET version = version + 1 WHERE room_id = $1)
+		DELETE FROM venue_rooms WHERE id = $1
+	`, roomID)
+	if err != nil {
+		log.Printf("Error deleting room: %v", err)
+		return err
+	}
+	return nil
+}
+
+// getRooms gets the rooms of the given venues in alphabetical order, keyed by venue ID
+func (r *VenueRepository) getRooms(venueIDs []int) (map[int][]models.VenueRoom, error) {
+	rooms := map[int][]models.VenueRoom{}
+	if len(venueIDs) == 0 {
+		return rooms, nil
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT id, venue_id, name, capacity, created_at, updated_at
+		FROM venue_rooms
+		WHERE venue_id = ANY($1)
+		ORDER BY name, id
+	`, pq.Array(venueIDs))
+	if err != nil {
+		log.Printf("Error getting rooms: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var room models.VenueRoom
+		if err := rows.Scan(
+			&room.ID,
+			&room.VenueID,
+			&room.Name,
+			&room.Capacity,
+			&room.CreatedAt,
+			&room.UpdatedAt,
+		); err != nil {
+			log.Printf("Error scanning room row: %v", err)
+			return nil, err
+		}
+		rooms[room.VenueID] = append(rooms[room.VenueID], room)
+	}
+
+	return rooms, rows.Err()
+}
+
+// scanVenue scans a row of venues, leaving its rooms empty
+func scanVenue(row rowScanner) (models.Venue, error) {
+	venue := models.Venue{Rooms: []models.VenueRoom{}}
+	err := row.Scan(
+		&venue.ID,
+		&venue.Name,
+		&venue.Address,
+		&venue.Capacity,
+		&venue.UserID,
+		&venue.CreatedAt,
+		&venue.UpdatedAt,
+	)
+	return venue, err
+}
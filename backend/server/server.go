@@ -23,23 +23,64 @@ type Server struct {
 
 // ServiceContainer holds all services
 type ServiceContainer struct {
-	EmailService *services.EmailService
+	EmailService     *services.EmailService
+	TokenService     *services.TokenService
+	AccessService    *services.AccessService
+	LifecycleService *services.LifecycleService
+	SurveyService    *services.SurveyService
+	TrashService     *services.TrashService
+	WaitlistService  *services.WaitlistService
+	MediaService     *services.MediaService
+	Storage          services.Storage
 }
 
 // RepositoryContainer holds all repositories
 type RepositoryContainer struct {
-	UserRepo     *repositories.UserRepository
-	EventRepo    *repositories.EventRepository
-	RSVPRepo     *repositories.RSVPRepository
-	CalendarRepo *repositories.CalendarRepository
+	UserRepo           *repositories.UserRepository
+	EventRepo          *repositories.EventRepository
+	RSVPRepo           *repositories.RSVPRepository
+	CalendarRepo       *repositories.CalendarRepository
+	InviteRepo         *repositories.InviteRepository
+	MemberRepo         *repositories.MemberRepository
+	TagRepo            *repositories.TagRepository
+	TemplateRepo       *repositories.TemplateRepository
+	AttachmentRepo     *repositories.AttachmentRepository
+	SessionRepo        *repositories.SessionRepository
+	VenueRepo          *repositories.VenueRepository
+	QuestionRepo       *repositories.QuestionRepository
+	AnswerRepo         *repositories.AnswerRepository
+	OverrideRepo       *repositories.RSVPOverrideRepository
+	CommentRepo        *repositories.CommentRepository
+	AnnouncementRepo   *repositories.AnnouncementRepository
+	SurveyRepo         *repositories.SurveyRepository
+	SurveyQuestionRepo *repositories.QuestionRepository
+	RevisionRepo       *repositories.RevisionRepository
 }
 
 // HandlerContainer holds all handlers
 type HandlerContainer struct {
-	UserHandler     *controllers.UserHandler
-	EventHandler    *controllers.EventHandler
-	RSVPHandler     *controllers.RSVPHandler
-	CalendarHandler *controllers.CalendarHandler
+	UserHandler           *controllers.UserHandler
+	EventHandler          *controllers.EventHandler
+	RSVPHandler           *controllers.RSVPHandler
+	CheckInHandler        *controllers.CheckInHandler
+	CalendarHandler       *controllers.CalendarHandler
+	InviteHandler         *controllers.InviteHandler
+	MemberHandler         *controllers.MemberHandler
+	TagHandler            *controllers.TagHandler
+	LifecycleHandler      *controllers.LifecycleHandler
+	TemplateHandler       *controllers.TemplateHandler
+	MediaHandler          *controllers.MediaHandler
+	SessionHandler        *controllers.SessionHandler
+	VenueHandler          *controllers.VenueHandler
+	QuestionHandler       *controllers.QuestionHandler
+	OverrideHandler       *controllers.RSVPOverrideHandler
+	CommentHandler        *controllers.CommentHandler
+	AnnouncementHandler   *controllers.AnnouncementHandler
+	SurveyHandler         *controllers.SurveyHandler
+	SurveyQuestionHandler *controllers.QuestionHandler
+	OrganizerHandler      *controllers.OrganizerHandler
+	RevisionHandler       *controllers.RevisionHandler
+	TrashHandler          *controllers.TrashHandler
 }
 
 // NewServer creates a new server instance
@@ -67,11 +108,27 @@ func NewServer(database *sql.DB) (*Server, error) {
 func (s *Server) initServicesAndRepositories() error {
 	// Initialize services
 	emailService := services.NewEmailService()
+	tokenService := services.NewTokenService()
 
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(s.Database)
 	eventRepo := repositories.NewEventRepository(s.Database)
 	rsvpRepo := repositories.NewRSVPRepository(s.Database)
+	inviteRepo := repositories.NewInviteRepository(s.Database)
+	memberRepo := repositories.NewMemberRepository(s.Database)
+	tagRepo := repositories.NewTagRepository(s.Database)
+	templateRepo := repositories.NewTemplateRepository(s.Database)
+	attachmentRepo := repositories.NewAttachmentRepository(s.Database)
+	sessionRepo := repositories.NewSessionRepository(s.Database)
+	venueRepo := repositories.NewVenueRepository(s.Database)
+	questionRepo := repositories.NewQuestionRepository(s.Database)
+	answerRepo := repositories.NewAnswerRepository(s.Database)
+	rsvpOverrideRepo := repositories.NewRSVPOverrideRepository(s.Database)
+	commentRepo := repositories.NewCommentRepository(s.Database)
+	announcementRepo := repositories.NewAnnouncementRepository(s.Database)
+	surveyRepo := repositories.NewSurveyRepository(s.Database)
+	surveyQuestionRepo := repositories.NewSurveyQuestionRepository(s.Database)
+	revisionRepo := repositories.NewRevisionRepository(s.Database)
 
 	// Initialize Google Calendar repository
 	calendarRepo, err := repositories.NewCalendarRepository()
@@ -79,15 +136,46 @@ func (s *Server) initServicesAndRepositories() error {
 		return fmt.Errorf("failed to initialize calendar repository: %v", err)
 	}
 
+	// Initialize media storage
+	storage, err := services.NewStorage()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %v", err)
+	}
+
+	mediaService := services.NewMediaService(storage)
+
 	s.Services = &ServiceContainer{
-		EmailService: emailService,
+		EmailService:     emailService,
+		TokenService:     tokenService,
+		AccessService:    services.NewAccessService(rsvpRepo, inviteRepo, memberRepo, tokenService),
+		LifecycleService: services.NewLifecycleService(eventRepo),
+		SurveyService:    services.NewSurveyService(surveyRepo, rsvpRepo, emailService),
+		TrashService:     services.NewTrashService(eventRepo, mediaService),
+		WaitlistService:  services.NewWaitlistService(rsvpRepo, userRepo, tokenService, emailService),
+		MediaService:     mediaService,
+		Storage:          storage,
 	}
 
 	s.Repositories = &RepositoryContainer{
-		UserRepo:     userRepo,
-		EventRepo:    eventRepo,
-		RSVPRepo:     rsvpRepo,
-		CalendarRepo: calendarRepo,
+		UserRepo:           userRepo,
+		EventRepo:          eventRepo,
+		RSVPRepo:           rsvpRepo,
+		CalendarRepo:       calendarRepo,
+		InviteRepo:         inviteRepo,
+		MemberRepo:         memberRepo,
+		TagRepo:            tagRepo,
+		TemplateRepo:       templateRepo,
+		AttachmentRepo:     attachmentRepo,
+		SessionRepo:        sessionRepo,
+		VenueRepo:          venueRepo,
+		QuestionRepo:       questionRepo,
+		AnswerRepo:         answerRepo,
+		OverrideRepo:       rsvpOverrideRepo,
+		CommentRepo:        commentRepo,
+		AnnouncementRepo:   announcementRepo,
+		SurveyRepo:         surveyRepo,
+		SurveyQuestionRepo: surveyQuestionRepo,
+		RevisionRepo:       revisionRepo,
 	}
 
 	return nil
@@ -96,10 +184,28 @@ func (s *Server) initServicesAndRepositories() error {
 // initHandlers initializes all handlers
 func (s *Server) initHandlers() {
 	s.Handlers = &HandlerContainer{
-		UserHandler:     controllers.NewUserHandler(s.Repositories.UserRepo),
-		EventHandler:    controllers.NewEventHandler(s.Repositories.EventRepo),
-		RSVPHandler:     controllers.NewRSVPHandler(s.Repositories.RSVPRepo, s.Repositories.EventRepo, s.Repositories.UserRepo, s.Services.EmailService),
-		CalendarHandler: controllers.NewCalendarHandler(s.Repositories.CalendarRepo, s.Repositories.EventRepo),
+		UserHandler:           controllers.NewUserHandler(s.Repositories.UserRepo),
+		EventHandler:          controllers.NewEventHandler(s.Repositories.EventRepo, s.Repositories.AttachmentRepo, s.Repositories.VenueRepo, s.Services.AccessService, s.Services.MediaService, s.Services.WaitlistService),
+		RSVPHandler:           controllers.NewRSVPHandler(s.Repositories.RSVPRepo, s.Repositories.EventRepo, s.Repositories.UserRepo, s.Repositories.QuestionRepo, s.Repositories.AnswerRepo, s.Repositories.OverrideRepo, s.Services.EmailService, s.Services.TokenService, s.Services.AccessService, s.Services.WaitlistService),
+		CheckInHandler:        controllers.NewCheckInHandler(s.Repositories.RSVPRepo, s.Repositories.EventRepo, s.Services.TokenService, s.Services.AccessService),
+		CalendarHandler:       controllers.NewCalendarHandler(s.Repositories.CalendarRepo, s.Repositories.EventRepo, s.Services.AccessService),
+		InviteHandler:         controllers.NewInviteHandler(s.Repositories.InviteRepo, s.Repositories.EventRepo, s.Services.TokenService, s.Services.AccessService),
+		MemberHandler:         controllers.NewMemberHandler(s.Repositories.MemberRepo, s.Repositories.EventRepo, s.Repositories.UserRepo, s.Services.EmailService, s.Services.AccessService),
+		TagHandler:            controllers.NewTagHandler(s.Repositories.TagRepo),
+		TemplateHandler:       controllers.NewTemplateHandler(s.Repositories.TemplateRepo, s.Repositories.EventRepo, s.Repositories.VenueRepo),
+		LifecycleHandler:      controllers.NewLifecycleHandler(s.Repositories.EventRepo, s.Repositories.RSVPRepo, s.Repositories.UserRepo, s.Services.EmailService, s.Services.AccessService),
+		SessionHandler:        controllers.NewSessionHandler(s.Repositories.SessionRepo, s.Repositories.RSVPRepo, s.Repositories.EventRepo, s.Services.AccessService),
+		VenueHandler:          controllers.NewVenueHandler(s.Repositories.VenueRepo, s.Repositories.EventRepo),
+		QuestionHandler:       controllers.NewQuestionHandler(s.Repositories.QuestionRepo, s.Repositories.EventRepo, s.Services.AccessService),
+		OverrideHandler:       controllers.NewRSVPOverrideHandler(s.Repositories.OverrideRepo, s.Repositories.EventRepo, s.Repositories.UserRepo, s.Services.AccessService),
+		CommentHandler:        controllers.NewCommentHandler(s.Repositories.CommentRepo, s.Repositories.EventRepo, s.Repositories.UserRepo, s.Services.EmailService, s.Services.AccessService),
+		AnnouncementHandler:   controllers.NewAnnouncementHandler(s.Repositories.AnnouncementRepo, s.Repositories.RSVPRepo, s.Repositories.EventRepo, s.Repositories.UserRepo, s.Services.EmailService, s.Services.AccessService),
+		SurveyHandler:         controllers.NewSurveyHandler(s.Repositories.SurveyRepo, s.Repositories.SurveyQuestionRepo, s.Repositories.RSVPRepo, s.Repositories.EventRepo, s.Services.AccessService),
+		SurveyQuestionHandler: controllers.NewQuestionHandler(s.Repositories.SurveyQuestionRepo, s.Repositories.EventRepo, s.Services.AccessService),
+		OrganizerHandler:      controllers.NewOrganizerHandler(s.Repositories.UserRepo, s.Repositories.SurveyRepo),
+		RevisionHandler:       controllers.NewRevisionHandler(s.Repositories.RevisionRepo, s.Repositories.EventRepo, s.Repositories.VenueRepo, s.Services.AccessService, s.Services.WaitlistService),
+		TrashHandler:          controllers.NewTrashHandler(s.Repositories.EventRepo, s.Services.MediaService, s.Services.TrashService),
+		MediaHandler:          controllers.NewMediaHandler(s.Repositories.EventRepo, s.Repositories.AttachmentRepo, s.Services.MediaService, s.Services.AccessService),
 	}
 }
 
@@ -116,9 +222,88 @@ func (s *Server) setupRoutes() {
 	// Event routes
 	s.Mux.Handle("/api/events", corsMiddleware(http.HandlerFunc(s.Handlers.EventHandler.CreateEvent)))
 	s.Mux.Handle("/api/events/user", corsMiddleware(http.HandlerFunc(s.Handlers.EventHandler.GetUserEvents)))
+	s.Mux.Handle("/api/events/trash", corsMiddleware(http.HandlerFunc(s.Handlers.TrashHandler.GetTrash)))
 	s.Mux.Handle("/api/events/upcoming", corsMiddleware(http.HandlerFunc(s.Handlers.EventHandler.GetUpcomingEvents)))
 	s.Mux.Handle("/api/events/search", corsMiddleware(http.HandlerFunc(s.Handlers.EventHandler.SearchEvents)))
 
+	// Tag routes
+	s.Mux.Handle("/api/tags", corsMiddleware(http.HandlerFunc(s.Handlers.TagHandler.GetTags)))
+	s.Mux.Handle("/api/organizers/", corsMiddleware(http.HandlerFunc(s.Handlers.OrganizerHandler.GetOrganizer)))
+
+	// Template routes
+	s.Mux.Handle("/api/templates", corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s.Handlers.TemplateHandler.GetTemplates(w, r)
+		case http.MethodPost:
+			s.Handlers.TemplateHandler.CreateTemplate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	s.Mux.Handle("/api/templates/", corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/events") {
+			s.Handlers.TemplateHandler.CreateEventFromTemplate(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.Handlers.TemplateHandler.GetTemplate(w, r)
+		case http.MethodPut:
+			s.Handlers.TemplateHandler.UpdateTemplate(w, r)
+		case http.MethodDelete:
+			s.Handlers.TemplateHandler.DeleteTemplate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Venue routes
+	s.Mux.Handle("/api/venues", corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s.Handlers.VenueHandler.GetVenues(w, r)
+		case http.MethodPost:
+			s.Handlers.VenueHandler.CreateVenue(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	s.Mux.Handle("/api/venues/", corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/availability"):
+			s.Handlers.VenueHandler.GetAvailability(w, r)
+		case strings.HasSuffix(path, "/rooms"):
+			s.Handlers.VenueHandler.CreateRoom(w, r)
+		case strings.Contains(path, "/rooms/"):
+			switch r.Method {
+			case http.MethodPut:
+				s.Handlers.VenueHandler.UpdateRoom(w, r)
+			case http.MethodDelete:
+				s.Handlers.VenueHandler.DeleteRoom(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		default:
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.VenueHandler.GetVenue(w, r)
+			case http.MethodPut:
+				s.Handlers.VenueHandler.UpdateVenue(w, r)
+			case http.MethodDelete:
+				s.Handlers.VenueHandler.DeleteVenue(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}
+	})))
+
+	// Serve uploaded media when it is stored on the local disk
+	if local, ok := s.Services.Storage.(*services.LocalStorage); ok {
+		s.Mux.Handle("/media/", http.StripPrefix("/media/", local.Handler()))
+	}
+
 	// Google Calendar endpoints
 	s.Mux.Handle("/api/calendar/authorize", corsMiddleware(http.HandlerFunc(s.Handlers.CalendarHandler.AuthorizeCalendar)))
 	s.Mux.Handle("/api/calendar/callback", corsMiddleware(http.HandlerFunc(s.Handlers.CalendarHandler.CalendarCallback)))
@@ -128,7 +313,34 @@ func (s *Server) setupRoutes() {
 	// Dynamic event routes
 	s.Mux.Handle("/api/events/", corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
-		if strings.HasSuffix(path, "/rsvp") {
+		if strings.HasSuffix(path, "/sessions") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.SessionHandler.GetAgenda(w, r)
+			case http.MethodPost:
+				s.Handlers.SessionHandler.CreateSession(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/sessions/") && strings.HasSuffix(path, "/rsvp") {
+			switch r.Method {
+			case http.MethodPost:
+				s.Handlers.SessionHandler.JoinSession(w, r)
+			case http.MethodDelete:
+				s.Handlers.SessionHandler.LeaveSession(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/sessions/") {
+			switch r.Method {
+			case http.MethodPut:
+				s.Handlers.SessionHandler.UpdateSession(w, r)
+			case http.MethodDelete:
+				s.Handlers.SessionHandler.DeleteSession(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.HasSuffix(path, "/rsvp") {
 			switch r.Method {
 			case http.MethodGet:
 				s.Handlers.RSVPHandler.GetRSVP(w, r)
@@ -139,10 +351,170 @@ func (s *Server) setupRoutes() {
 			default:
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			}
+		} else if strings.HasSuffix(path, "/rsvp-overrides") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.OverrideHandler.GetOverrides(w, r)
+			case http.MethodPost:
+				s.Handlers.OverrideHandler.AddOverride(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/rsvp-overrides/") {
+			s.Handlers.OverrideHandler.RemoveOverride(w, r)
 		} else if strings.HasSuffix(path, "/rsvp/count") {
 			s.Handlers.RSVPHandler.GetRSVPCount(w, r)
 		} else if strings.HasSuffix(path, "/rsvps") {
 			s.Handlers.RSVPHandler.GetRSVPs(w, r)
+		} else if strings.HasSuffix(path, "/rsvps/export") {
+			s.Handlers.RSVPHandler.ExportRSVPs(w, r)
+		} else if strings.Contains(path, "/rsvps/") && strings.HasSuffix(path, "/approve") {
+			s.Handlers.RSVPHandler.ApproveRSVPs(w, r)
+		} else if strings.Contains(path, "/rsvps/") && strings.HasSuffix(path, "/decline") {
+			s.Handlers.RSVPHandler.DeclineRSVPs(w, r)
+		} else if strings.HasSuffix(path, "/survey") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.SurveyHandler.GetSurvey(w, r)
+			case http.MethodPost, http.MethodPut:
+				s.Handlers.SurveyHandler.SubmitResponse(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.HasSuffix(path, "/survey/results") {
+			s.Handlers.SurveyHandler.GetResults(w, r)
+		} else if strings.HasSuffix(path, "/survey/questions") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.SurveyQuestionHandler.GetQuestions(w, r)
+			case http.MethodPost:
+				s.Handlers.SurveyQuestionHandler.CreateQuestion(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/survey/questions/") {
+			switch r.Method {
+			case http.MethodPut:
+				s.Handlers.SurveyQuestionHandler.UpdateQuestion(w, r)
+			case http.MethodDelete:
+				s.Handlers.SurveyQuestionHandler.DeleteQuestion(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.HasSuffix(path, "/questions") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.QuestionHandler.GetQuestions(w, r)
+			case http.MethodPost:
+				s.Handlers.QuestionHandler.CreateQuestion(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/questions/") {
+			switch r.Method {
+			case http.MethodPut:
+				s.Handlers.QuestionHandler.UpdateQuestion(w, r)
+			case http.MethodDelete:
+				s.Handlers.QuestionHandler.DeleteQuestion(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.HasSuffix(path, "/comments") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.CommentHandler.GetComments(w, r)
+			case http.MethodPost:
+				s.Handlers.CommentHandler.CreateComment(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/comments/") && strings.HasSuffix(path, "/pin") {
+			switch r.Method {
+			case http.MethodPost:
+				s.Handlers.CommentHandler.PinComment(w, r)
+			case http.MethodDelete:
+				s.Handlers.CommentHandler.UnpinComment(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/comments/") {
+			switch r.Method {
+			case http.MethodPut:
+				s.Handlers.CommentHandler.UpdateComment(w, r)
+			case http.MethodDelete:
+				s.Handlers.CommentHandler.DeleteComment(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.HasSuffix(path, "/announcements") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.AnnouncementHandler.GetAnnouncements(w, r)
+			case http.MethodPost:
+				s.Handlers.AnnouncementHandler.CreateAnnouncement(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.HasSuffix(path, "/revisions") {
+			s.Handlers.RevisionHandler.GetRevisions(w, r)
+		} else if strings.Contains(path, "/revisions/") && strings.HasSuffix(path, "/restore") {
+			s.Handlers.RevisionHandler.RestoreRevision(w, r)
+		} else if strings.HasSuffix(path, "/ticket") {
+			s.Handlers.CheckInHandler.GetTicket(w, r)
+		} else if strings.HasSuffix(path, "/check-in") {
+			s.Handlers.CheckInHandler.CheckIn(w, r)
+		} else if strings.HasSuffix(path, "/members") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.MemberHandler.GetMembers(w, r)
+			case http.MethodPost:
+				s.Handlers.MemberHandler.AddMember(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/members/") {
+			s.Handlers.MemberHandler.RemoveMember(w, r)
+		} else if strings.HasSuffix(path, "/invites") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.InviteHandler.GetInvites(w, r)
+			case http.MethodPost:
+				s.Handlers.InviteHandler.CreateInvite(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/invites/") {
+			s.Handlers.InviteHandler.RevokeInvite(w, r)
+		} else if strings.HasSuffix(path, "/occurrences") {
+			s.Handlers.EventHandler.GetOccurrences(w, r)
+		} else if strings.Contains(path, "/occurrences/") {
+			s.Handlers.EventHandler.UpdateOccurrence(w, r)
+		} else if strings.HasSuffix(path, "/publish") || strings.HasSuffix(path, "/unpublish") || strings.HasSuffix(path, "/cancel") {
+			s.Handlers.LifecycleHandler.UpdateEventStatus(w, r)
+		} else if strings.HasSuffix(path, "/cover") {
+			switch r.Method {
+			case http.MethodPut, http.MethodPost:
+				s.Handlers.MediaHandler.UploadCoverImage(w, r)
+			case http.MethodDelete:
+				s.Handlers.MediaHandler.DeleteCoverImage(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.HasSuffix(path, "/attachments") {
+			switch r.Method {
+			case http.MethodGet:
+				s.Handlers.MediaHandler.GetAttachments(w, r)
+			case http.MethodPost:
+				s.Handlers.MediaHandler.UploadAttachment(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		} else if strings.Contains(path, "/attachments/") {
+			s.Handlers.MediaHandler.DeleteAttachment(w, r)
+		} else if strings.HasSuffix(path, "/restore") {
+			s.Handlers.TrashHandler.RestoreEvent(w, r)
+		} else if strings.HasSuffix(path, "/duplicate") {
+			s.Handlers.EventHandler.DuplicateEvent(w, r)
 		} else {
 			switch r.Method {
 			case http.MethodGet:
@@ -161,6 +533,16 @@ func (s *Server) setupRoutes() {
 // Start starts the HTTP server
 func (s *Server) Start(addr string) error {
 	fmt.Printf("Server starting on %s\n", addr)
+
+	// Publish scheduled events and complete ended ones in the background
+	s.Services.LifecycleService.Start(services.LifecycleInterval)
+
+	// Send post-event surveys once events are completed
+	s.Services.SurveyService.Start(services.SurveyInterval)
+
+	// Purge events that have been in the trash past the retention period
+	s.Services.TrashService.Start(services.TrashInterval)
+
 	return http.ListenAndServe(addr, corsMiddleware(s.Mux))
 }
 
@@ -185,7 +567,8 @@ func corsMiddleware(next http.Handler) http.Handler {
 
 		w.Header().Set("Access-Control-Allow-Origin", origin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Invite-Token, If-Match, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Retry-After")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		// Handle preflight requests
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"log"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// Permission is an action a member may take on an event
+type Permission string
+
+// Permissions checked by the handlers
+const (
+	PermissionEditEvent       Permission = "edit_event"
+	PermissionDeleteEvent     Permission = "delete_event"
+	PermissionDuplicateEvent  Permission = "duplicate_event"
+	PermissionViewAttendees   Permission = "view_attendees"
+	PermissionCheckIn         Permission = "check_in"
+	PermissionManageInvites   Permission = "manage_invites"
+	PermissionManageMembers   Permission = "manage_members"
+	PermissionReviewRSVPs     Permission = "review_rsvps"
+	PermissionModerate        Permission = "moderate"
+	PermissionAnnounce        Permission = "announce"
+	PermissionRestoreRevision Permission = "restore_revision"
+)
+
+// rolePermissions lists what each event role may do
+var rolePermissions = map[string][]Permission{
+	models.RoleOwner: {
+		PermissionEditEvent,
+		PermissionDeleteEvent,
+		PermissionDuplicateEvent,
+		PermissionViewAttendees,
+		PermissionCheckIn,
+		PermissionManageInvites,
+		PermissionManageMembers,
+		PermissionReviewRSVPs,
+		PermissionModerate,
+		PermissionAnnounce,
+		PermissionRestoreRevision,
+	},
+	models.RoleCoOrganizer: {
+		PermissionEditEvent,
+		PermissionViewAttendees,
+		PermissionCheckIn,
+		PermissionManageInvites,
+		PermissionManageMembers,
+		PermissionReviewRSVPs,
+		PermissionModerate,
+		PermissionAnnounce,
+	},
+	models.RoleCheckInStaff: {
+		PermissionViewAttendees,
+		PermissionCheckIn,
+	},
+	models.RoleViewer: {
+		PermissionViewAttendees,
+	},
+}
+
+// roleRanks orders the event roles, so members managing the team can only hand
+// out and take away roles below their own
+var roleRanks = map[string]int{
+	models.RoleOwner:        3,
+	models.RoleCoOrganizer:  2,
+	models.RoleCheckInStaff: 1,
+	models.RoleViewer:       1,
+}
+
+// IsValidMemberRole reports whether a role can be granted to an event member.
+// The owner role belongs to the event's creator and cannot be granted.
+func IsValidMemberRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok && role != models.RoleOwner
+}
+
+// AccessService decides who may see and manage an event
+type AccessService struct {
+	RSVPRepo     *repositories.RSVPRepository
+	InviteRepo   *repositories.InviteRepository
+	MemberRepo   *repositories.MemberRepository
+	TokenService *TokenService
+}
+
+func NewAccessService(
+	rsvpRepo *repositories.RSVPRepository,
+	inviteRepo *repositories.InviteRepository,
+	memberRepo *repositories.MemberRepository,
+	tokenService *TokenService,
+) *AccessService {
+	return &AccessService{
+		RSVPRepo:     rsvpRepo,
+		InviteRepo:   inviteRepo,
+		MemberRepo:   memberRepo,
+		TokenService: tokenService,
+	}
+}
+
+// Role returns the user's role on an event, or an empty string if they have none
+func (s *AccessService) Role(event *models.EventWithOrganizer, userID int) (string, error) {
+	if userID == 0 {
+		return "", nil
+	}
+	if event.UserID == userID {
+		return models.RoleOwner, nil
+	}
+	return s.MemberRepo.GetMemberRole(event.ID, userID)
+}
+
+// Can reports whether a user holds a permission on an event
+func (s *AccessService) Can(event *models.EventWithOrganizer, userID int, permission Permission) (bool, error) {
+	role, err := s.Role(event, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CanManageRole reports whether a user may grant a role on an event, or change
+// or remove the role of a member who holds it. Only roles below the user's own
+// can be managed, so co-organizers can't add or remove other co-organizers.
+// An empty role, for users who aren't members yet, can always be managed.
+func (s *AccessService) CanManageRole(event *models.EventWithOrganizer, userID int, role string) (bool, error) {
+	ownRole, err := s.Role(event, userID)
+	if err != nil {
+		return false, err
+	}
+	return roleRanks[role] < roleRanks[ownRole], nil
+}
+
+// CanViewEvent reports whether a user may see an event. userID is 0 for anonymous
+// requests and inviteToken is empty when no invite link was used.
+//
+// Public and unlisted events are visible to anyone who knows their ID; unlisted
+// events are only left out of listings and search. Private events are visible to
+// their members, to users who already RSVP'd, and to holders of an active invite token.
+// Drafts and events waiting to be published are only visible to their members.
+func (s *AccessService) CanViewEvent(event *models.EventWithOrganizer, userID int, inviteToken string) (bool, error) {
+	if event.IsUnpublished() {
+		role, err := s.Role(event, userID)
+		if err != nil {
+			return false, err
+		}
+		return role != "", nil
+	}
+
+	if event.Visibility != "private" {
+		return true, nil
+	}
+
+	if userID != 0 {
+		role, err := s.Role(event, userID)
+		if err != nil {
+			return false, err
+		}
+		if role != "" {
+			return true, nil
+		}
+
+		hasRSVP, err := s.RSVPRepo.HasRSVP(event.ID, userID)
+		if err != nil {
+			return false, err
+		}
+		if hasRSVP {
+			return true, nil
+		}
+	}
+
+	if inviteToken == "" {
+		return false, nil
+	}
+
+	eventID, nonce, err := s.TokenService.VerifyInviteToken(inviteToken)
+	if err != nil || eventID != event.ID {
+		log.Printf("Rejected invite token for event %d: %v", event.ID, err)
+		return false, nil
+	}
+
+	return s.InviteRepo.IsInviteActive(event.ID, nonce)
+}
@@ -1,14 +1,31 @@
 package services
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"log"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"os"
+	"time"
 
 	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/qrcode"
 )
 
+// TicketQRScale is the size in pixels of each module of a ticket's QR code
+const TicketQRScale = 8
+
+// emailAttachment is a file attached to an email
+type emailAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
 // EmailService handles sending emails
 type EmailService struct {
 	smtpHost     string
@@ -45,6 +62,10 @@ func (s *EmailService) SendRSVPNotificationToOrganizer(event *models.Event, user
 		displayStatus = "Maybe"
 	case "not_going":
 		displayStatus = "Not Going"
+	case "waitlisted":
+		displayStatus = "Waitlisted"
+	case "pending":
+		displayStatus = "Awaiting Approval"
 	}
 
 	// Create email subject and body
@@ -61,14 +82,15 @@ Event Details:
 You can view all RSVPs for this event at: http://localhost:3000/event/%d
 
 Thank you for using Evently!
-`, user.FirstName, user.LastName, event.Title, displayStatus, event.Date.Format("Monday, January 2, 2006 at 3:04 PM"), event.Location, event.ID)
+`, user.FirstName, user.LastName, event.Title, displayStatus, formatEventTime(event), event.Location, event.ID)
 
 	// Send the email
 	return s.sendEmail(organizerEmail, subject, body)
 }
 
-// SendRSVPConfirmationToUser sends a confirmation email to the user who RSVP'd
-func (s *EmailService) SendRSVPConfirmationToUser(event *models.Event, user *models.User, rsvpStatus string) error {
+// SendRSVPConfirmationToUser sends a confirmation email to the user who RSVP'd. A
+// ticket code is included for "going" RSVPs, with its QR code attached.
+func (s *EmailService) SendRSVPConfirmationToUser(event *models.Event, user *models.User, rsvpStatus string, ticketCode string) error {
 	// Format status for display
 	displayStatus := rsvpStatus
 	switch rsvpStatus {
@@ -78,6 +100,10 @@ func (s *EmailService) SendRSVPConfirmationToUser(event *models.Event, user *mod
 		displayStatus = "Maybe"
 	case "not_going":
 		displayStatus = "Not Going"
+	case "waitlisted":
+		displayStatus = "Waitlisted"
+	case "pending":
+		displayStatus = "Awaiting Approval"
 	}
 
 	// Create email subject and body
@@ -86,7 +112,7 @@ func (s *EmailService) SendRSVPConfirmationToUser(event *models.Event, user *mod
 Hello %s,
 
 Thank you for your RSVP to "%s". Your response has been recorded as: %s.
-
+%s
 Event Details:
 - Date: %s
 - Location: %s
@@ -95,14 +121,244 @@ Event Details:
 You can view the event details at: http://localhost:3000/event/%d
 
 Thank you for using Evently!
-`, user.FirstName, event.Title, displayStatus, event.Date.Format("Monday, January 2, 2006 at 3:04 PM"), event.Location, event.OrganizerFirstName, event.OrganizerLastName, event.ID)
+`, user.FirstName, event.Title, displayStatus, ticketText(ticketCode), formatEventTime(event), event.Location, event.OrganizerFirstName, event.OrganizerLastName, event.ID)
+
+	// Send the email
+	return s.sendEmail(user.Email, subject, body, ticketAttachments(ticketCode)...)
+}
+
+// SendWaitlistPromotion lets a waitlisted user know a seat opened up and they are now
+// going, with the ticket they check in with
+func (s *EmailService) SendWaitlistPromotion(event *models.Event, user *models.User, ticketCode string) error {
+	// Create email subject and body
+	subject := fmt.Sprintf("You're off the waitlist for %s", event.Title)
+	body := fmt.Sprintf(`
+Hello %s,
+
+Good news! A spot opened up for "%s" and you have been moved from the waitlist. Your RSVP is now: Going.
+%s
+Event Details:
+- Date: %s
+- Location: %s
+- Organizer: %s %s
+
+If you can no longer attend, please update your RSVP so the next person on the waitlist can take your place: http://localhost:3000/event/%d
+
+Thank you for using Evently!
+`, user.FirstName, event.Title, ticketText(ticketCode), formatEventTime(event), event.Location, event.OrganizerFirstName, event.OrganizerLastName, event.ID)
+
+	// Send the email
+	return s.sendEmail(user.Email, subject, body, ticketAttachments(ticketCode)...)
+}
+
+// SendRSVPDecision lets an attendee know whether an organizer approved their
+// request to attend. Approved attendees are either going, with the ticket they
+// check in with, or waitlisted if the event is full. The organizer's message is
+// included when given.
+func (s *EmailService) SendRSVPDecision(event *models.Event, user *models.User, rsvpStatus, message, ticketCode string) error {
+	var subject, decision string
+	switch rsvpStatus {
+	case "going":
+		subject = fmt.Sprintf("You're going to %s", event.Title)
+		decision = "Your request to attend has been approved. Your RSVP is now: Going."
+	case "waitlisted":
+		subject = fmt.Sprintf("You're on the waitlist for %s", event.Title)
+		decision = "Your request to attend has been approved, but the event is full. You have been added to the waitlist and will be notified if a spot opens up."
+	default:
+		subject = fmt.Sprintf("Your request to attend %s", event.Title)
+		decision = "Unfortunately, your request to attend has been declined."
+	}
+
+	if message != "" {
+		decision += fmt.Sprintf("\n\nMessage from the organizer:\n%s", message)
+	}
+
+	body := fmt.Sprintf(`
+Hello %s,
+
+%s
+%s
+Event Details:
+- Date: %s
+- Location: %s
+- Organizer: %s %s
+
+View the event: http://localhost:3000/event/%d
+
+Thank you for using Evently!
+`, user.FirstName, decision, ticketText(ticketCode), formatEventTime(event), event.Location, event.OrganizerFirstName, event.OrganizerLastName, event.ID)
+
+	// Send the email
+	return s.sendEmail(user.Email, subject, body, ticketAttachments(ticketCode)...)
+}
+
+// SendNewCommentToOrganizer lets the event organizer know someone started a new
+// thread in the event's discussion
+func (s *EmailService) SendNewCommentToOrganizer(event *models.Event, commenter *models.User, comment string) error {
+	organizerEmail := event.OrganizerEmail
+	if organizerEmail == "" {
+		return fmt.Errorf("organizer email not found")
+	}
+
+	subject := fmt.Sprintf("New comment on %s", event.Title)
+	body := fmt.Sprintf(`
+Hello,
+
+%s %s commented on your event "%s":
+
+%s
+
+Reply to the discussion at: http://localhost:3000/event/%d
+
+Thank you for using Evently!
+`, commenter.FirstName, commenter.LastName, event.Title, comment, event.ID)
+
+	// Send the email
+	return s.sendEmail(organizerEmail, subject, body)
+}
+
+// SendAnnouncement emails an attendee a message from the organizer of an event
+// they RSVP'd to
+func (s *EmailService) SendAnnouncement(event *models.Event, user *models.User, subject, message string) error {
+	body := fmt.Sprintf(`
+Hello %s,
+
+%s %s sent an announcement about "%s":
+
+%s
+
+Event Details:
+- Date: %s
+- Location: %s
+
+View the event: http://localhost:3000/event/%d
+
+Thank you for using Evently!
+`, user.FirstName, event.OrganizerFirstName, event.OrganizerLastName, event.Title, message, formatEventTime(event), event.Location, event.ID)
+
+	// Send the email
+	return s.sendEmail(user.Email, fmt.Sprintf("%s: %s", event.Title, subject), body)
+}
+
+// SendSurveyInvitation asks an attendee for feedback on an event they went to
+func (s *EmailService) SendSurveyInvitation(event *models.Event, user *models.User) error {
+	subject := fmt.Sprintf("How was %s?", event.Title)
+	body := fmt.Sprintf(`
+Hello %s,
+
+Thank you for attending "%s" on %s.
+
+%s %s would love to hear what you thought. Rate the event and share your feedback at: http://localhost:3000/event/%d
+
+Thank you for using Evently!
+`, user.FirstName, event.Title, formatEventTime(event), event.OrganizerFirstName, event.OrganizerLastName, event.ID)
+
+	// Send the email
+	return s.sendEmail(user.Email, subject, body)
+}
+
+// SendMemberInvitation lets a user know they were added to the team running an event
+func (s *EmailService) SendMemberInvitation(event *models.Event, member *models.User, inviter *models.User, role string) error {
+	roleNames := map[string]string{
+		models.RoleCoOrganizer:  "a co-organizer",
+		models.RoleCheckInStaff: "check-in staff",
+		models.RoleViewer:       "a viewer",
+	}
+
+	// Create email subject and body
+	subject := fmt.Sprintf("You've been added to %s", event.Title)
+	body := fmt.Sprintf(`
+Hello %s,
+
+%s %s has added you to "%s" as %s.
+
+Event Details:
+- Date: %s
+- Location: %s
+
+View the event: http://localhost:3000/event/%d
+
+Thank you for using Evently!
+`, member.FirstName, inviter.FirstName, inviter.LastName, event.Title, roleNames[role], formatEventTime(event), event.Location, event.ID)
+
+	// Send the email
+	return s.sendEmail(member.Email, subject, body)
+}
+
+// SendEventCancellation lets an attendee know an event they RSVP'd to has been cancelled
+func (s *EmailService) SendEventCancellation(event *models.Event, user *models.User, reason string) error {
+	reasonText := ""
+	if reason != "" {
+		reasonText = fmt.Sprintf("\nMessage from the organizer: %s\n", reason)
+	}
+
+	// Create email subject and body
+	subject := fmt.Sprintf("Cancelled: %s", event.Title)
+	body := fmt.Sprintf(`
+Hello %s,
+
+Unfortunately "%s" has been cancelled by its organizer.
+%s
+Event Details:
+- Date: %s
+- Location: %s
+- Organizer: %s %s
+
+You can view the event at: http://localhost:3000/event/%d
+
+Thank you for using Evently!
+`, user.FirstName, event.Title, reasonText, formatEventTime(event), event.Location, event.OrganizerFirstName, event.OrganizerLastName, event.ID)
 
 	// Send the email
 	return s.sendEmail(user.Email, subject, body)
 }
 
-// sendEmail is a helper function to send an email
-func (s *EmailService) sendEmail(to, subject, body string) error {
+// formatEventTime formats the start and end of an event in the event's time zone,
+// e.g. "Monday, January 2, 2006 at 3:04 PM - 5:00 PM EAT (Africa/Nairobi)"
+func formatEventTime(event *models.Event) string {
+	loc, err := time.LoadLocation(event.TimeZone)
+	if err != nil || event.TimeZone == "" {
+		loc = time.UTC
+	}
+
+	start := event.Date.In(loc)
+	formatted := start.Format("Monday, January 2, 2006 at 3:04 PM")
+	if event.EndDate != nil {
+		end := event.EndDate.In(loc)
+		if end.YearDay() == start.YearDay() && end.Year() == start.Year() {
+			formatted += end.Format(" - 3:04 PM")
+		} else {
+			formatted += end.Format(" - Monday, January 2, 2006 at 3:04 PM")
+		}
+	}
+
+	return fmt.Sprintf("%s %s (%s)", formatted, start.Format("MST"), loc.String())
+}
+
+// ticketText returns the lines describing a ticket in an email, if there is one
+func ticketText(ticketCode string) string {
+	if ticketCode == "" {
+		return ""
+	}
+	return fmt.Sprintf("\nYour ticket code is %s. Show the attached QR code when you arrive to check in.\n", ticketCode)
+}
+
+// ticketAttachments returns the QR code image of a ticket to attach to an email
+func ticketAttachments(ticketCode string) []emailAttachment {
+	if ticketCode == "" {
+		return nil
+	}
+
+	qr, err := qrcode.PNG(ticketCode, TicketQRScale)
+	if err != nil {
+		log.Printf("Error rendering ticket QR code: %v", err)
+		return nil
+	}
+	return []emailAttachment{{FileName: "ticket.png", ContentType: "image/png", Data: qr}}
+}
+
+// sendEmail is a helper function to send an email with optional attachments
+func (s *EmailService) sendEmail(to, subject, body string, attachments ...emailAttachment) error {
 	// Check if email service is configured
 	if s.smtpHost == "" || s.smtpPort == "" || s.smtpUsername == "" || s.smtpPassword == "" || s.fromEmail == "" {
 		log.Println("Email service not configured, skipping email send")
@@ -112,14 +368,28 @@ func (s *EmailService) sendEmail(to, subject, body string) error {
 	// Set up authentication information
 	auth := smtp.PlainAuth("", s.smtpUsername, s.smtpPassword, s.smtpHost)
 
+	// Encode the subject so line breaks or non-ASCII characters in it, such as
+	// from event titles, can't end the header
+	subject = mime.QEncoding.Encode("utf-8", subject)
+
 	// Compose the message
-	msg := []byte(fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"MIME-Version: 1.0\r\n"+
-		"Content-Type: text/plain; charset=utf-8\r\n"+
-		"\r\n"+
-		"%s", s.fromEmail, to, subject, body))
+	var msg []byte
+	if len(attachments) == 0 {
+		msg = []byte(fmt.Sprintf("From: %s\r\n"+
+			"To: %s\r\n"+
+			"Subject: %s\r\n"+
+			"MIME-Version: 1.0\r\n"+
+			"Content-Type: text/plain; charset=utf-8\r\n"+
+			"\r\n"+
+			"%s", s.fromEmail, to, subject, body))
+	} else {
+		var err error
+		msg, err = s.composeMultipart(to, subject, body, attachments)
+		if err != nil {
+			log.Printf("Error composing email: %v", err)
+			return err
+		}
+	}
 
 	// Send the email
 	err := smtp.SendMail(s.smtpHost+":"+s.smtpPort, auth, s.fromEmail, []string{to}, msg)
@@ -131,3 +401,49 @@ func (s *EmailService) sendEmail(to, subject, body string) error {
 	log.Printf("Email sent successfully to %s", to)
 	return nil
 }
+
+// composeMultipart builds a multipart/mixed message with a plain text body
+// followed by the attachments
+func (s *EmailService) composeMultipart(to, subject, body string, attachments []emailAttachment) ([]byte, error) {
+	var parts bytes.Buffer
+	writer := multipart.NewWriter(&parts)
+
+	text, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=utf-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	text.Write([]byte(body))
+
+	for _, attachment := range attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.FileName)},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Wrap the encoded data at 76 characters per line
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	header := fmt.Sprintf("From: %s\r\n"+
+		"To: %s\r\n"+
+		"Subject: %s\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: multipart/mixed; boundary=%s\r\n"+
+		"\r\n", s.fromEmail, to, subject, writer.Boundary())
+	return append([]byte(header), parts.Bytes()...), nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// LifecycleInterval is how often the lifecycle job checks for events to publish or complete
+const LifecycleInterval = time.Minute
+
+// LifecycleService moves events through the lifecycle states that depend on time:
+// scheduled events are published once their publish time passes and published
+// events are completed once they have ended
+type LifecycleService struct {
+	EventRepo *repositories.EventRepository
+}
+
+func NewLifecycleService(eventRepo *repositories.EventRepository) *LifecycleService {
+	return &LifecycleService{EventRepo: eventRepo}
+}
+
+// Start runs the lifecycle job in the background every interval
+func (s *LifecycleService) Start(interval time.Duration) {
+	go func() {
+		s.Run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.Run()
+		}
+	}()
+}
+
+// Run publishes scheduled events that are due and completes events that have ended
+func (s *LifecycleService) Run() {
+	published, err := s.EventRepo.PublishScheduledEvents()
+	if err != nil {
+		log.Printf("Error publishing scheduled events: %v", err)
+	} else if published > 0 {
+		log.Printf("Published %d scheduled events", published)
+	}
+
+	completed, err := s.EventRepo.CompleteEndedEvents()
+	if err != nil {
+		log.Printf("Error completing ended events: %v", err)
+	} else if completed > 0 {
+		log.Printf("Marked %d ended events as completed", completed)
+	}
+}
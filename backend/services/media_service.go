@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register decoders for cover images
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/johneliud/evently/backend/models"
+)
+
+// Upload limits
+const (
+	MaxCoverImageSize    = 5 << 20  // 5 MB
+	MaxAttachmentSize    = 20 << 20 // 20 MB
+	maxImagePixels       = 40000000 // refuse images that would take too much memory to decode
+	thumbnailJPEGQuality = 85
+)
+
+// Sentinel errors returned for uploads that are not accepted
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrImageTooLarge        = errors.New("image dimensions are too large")
+)
+
+// thumbnailWidths are the widths cover images are resized to, keyed by size name
+var thumbnailWidths = map[string]int{
+	"small":  320,
+	"medium": 800,
+}
+
+// coverImageTypes maps the image types accepted as covers to their file extension
+var coverImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// attachmentTypes maps the file extensions accepted as attachments to the content
+// type they are served with and the type http.DetectContentType sniffs for them
+var attachmentTypes = map[string]struct {
+	contentType string
+	sniffed     string
+}{
+	".pdf":  {"application/pdf", "application/pdf"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/zip"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/zip"},
+	".txt":  {"text/plain; charset=utf-8", "text/plain; charset=utf-8"},
+	".jpg":  {"image/jpeg", "image/jpeg"},
+	".jpeg": {"image/jpeg", "image/jpeg"},
+	".png":  {"image/png", "image/png"},
+}
+
+// MediaService validates uploaded event media, generates thumbnails and stores files
+type MediaService struct {
+	Storage Storage
+}
+
+func NewMediaService(storage Storage) *MediaService {
+	return &MediaService{Storage: storage}
+}
+
+// SaveCoverImage validates an uploaded cover image and stores it along with its
+// thumbnails. It returns the key of the original image.
+func (s *MediaService) SaveCoverImage(eventID int, data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := coverImageTypes[contentType]
+	if !ok {
+		return "", ErrUnsupportedMediaType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrUnsupportedMediaType
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return "", ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrUnsupportedMediaType
+	}
+
+	prefix, err := newMediaPrefix(eventID, "cover")
+	if err != nil {
+		return "", err
+	}
+
+	key := prefix + "/original" + ext
+	if err := s.Storage.Put(key, data, contentType); err != nil {
+		return "", err
+	}
+
+	for name, width := range thumbnailWidths {
+		var thumbnail bytes.Buffer
+		if err := jpeg.Encode(&thumbnail, flatten(resizeImage(img, width)), &jpeg.Options{Quality: thumbnailJPEGQuality}); err != nil {
+			s.DeleteCoverImage(key)
+			return "", err
+		}
+		if err := s.Storage.Put(thumbnailKey(key, name), thumbnail.Bytes(), "image/jpeg"); err != nil {
+			s.DeleteCoverImage(key)
+			return "", err
+		}
+	}
+
+	return key, nil
+}
+
+// DeleteCoverImage removes a cover image and its thumbnails from storage
+func (s *MediaService) DeleteCoverImage(key string) {
+	keys := []string{key}
+	for name := range thumbnailWidths {
+		keys = append(keys, thumbnailKey(key, name))
+	}
+	for _, k := range keys {
+		if err := s.Storage.Delete(k); err != nil {
+			log.Printf("Error deleting media %s: %v", k, err)
+		}
+	}
+}
+
+// CoverImage returns the URLs of a cover image and its thumbnails, or nil when the
+// event has no cover image
+func (s *MediaService) CoverImage(key string) *models.CoverImage {
+	if key == "" {
+		return nil
+	}
+
+	cover := &models.CoverImage{
+		URL:        s.Storage.URL(key),
+		Thumbnails: map[string]string{},
+	}
+	for name := range thumbnailWidths {
+		cover.Thumbnails[name] = s.Storage.URL(thumbnailKey(key, name))
+	}
+	return cover
+}
+
+// SaveAttachment validates an uploaded attachment against its file name and stores
+// it. It returns the key and the content type the file is served with.
+func (s *MediaService) SaveAttachment(eventID int, fileName string, data []byte) (string, string, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	allowed, ok := attachmentTypes[ext]
+	if !ok || http.DetectContentType(data) != allowed.sniffed {
+		return "", "", ErrUnsupportedMediaType
+	}
+
+	prefix, err := newMediaPrefix(eventID, "attachments")
+	if err != nil {
+		return "", "", err
+	}
+
+	key := prefix + "/" + safeFileName(fileName)
+	if err := s.Storage.Put(key, data, allowed.contentType); err != nil {
+		return "", "", err
+	}
+	return key, allowed.contentType, nil
+}
+
+// DeleteAttachment removes an attachment from storage
+func (s *MediaService) DeleteAttachment(key string) {
+	if err := s.Storage.Delete(key); err != nil {
+		log.Printf("Error deleting media %s: %v", key, err)
+	}
+}
+
+// URL returns the public URL of a stored file
+func (s *MediaService) URL(key string) string {
+	return s.Storage.URL(key)
+}
+
+// newMediaPrefix returns a new unguessable folder for an event's media, so files
+// can be served publicly without exposing those of private events
+func newMediaPrefix(eventID int, kind string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("events/%d/%s/%s", eventID, kind, hex.EncodeToString(b)), nil
+}
+
+// thumbnailKey returns the key of a thumbnail stored next to the original image
+func thumbnailKey(key, size string) string {
+	return path.Dir(key) + "/" + size + ".jpg"
+}
+
+// safeFileName keeps the letters, digits, dots, dashes and underscores of an
+// uploaded file name so it can be used in a storage key and URL
+func safeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		case r == ' ':
+			return '_'
+		default:
+			return -1
+		}
+	}, name)
+	safe = strings.TrimLeft(safe, ".")
+	if safe == "" {
+		safe = "file" + strings.ToLower(filepath.Ext(name))
+	}
+	return safe
+}
+
+// resizeImage scales an image down to the given width, keeping its aspect ratio, by
+// averaging the source pixels each target pixel covers. Narrower images are returned
+// unchanged.
+func resizeImage(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= width {
+		return src
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// flatten draws an image over a white background, since JPEG has no transparency
+func flatten(src image.Image) image.Image {
+	dst := image.NewRGBA(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Over)
+	return dst
+}
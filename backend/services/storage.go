@@ -0,0 +1,29 @@
+package services
+
+import (
+	"fmt"
+	"os"
+)
+
+// Storage saves uploaded files under a key and tells clients where to download them
+type Storage interface {
+	// Put stores data under key, replacing any file already there
+	Put(key string, data []byte, contentType string) error
+	// Delete removes the file stored under key. Missing files are not an error.
+	Delete(key string) error
+	// URL returns the public URL of the file stored under key
+	URL(key string) string
+}
+
+// NewStorage returns the storage backend chosen by STORAGE_BACKEND: "local" (the
+// default) keeps files on disk and "s3" uses an S3-compatible object store
+func NewStorage() (Storage, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		return NewLocalStorage(), nil
+	case "s3":
+		return NewS3Storage()
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps uploaded files in a directory on disk. The server serves them
+// under /media/ using Handler.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocalStorage() *LocalStorage {
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	baseURL := os.Getenv("MEDIA_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:9000/media"
+	}
+	return &LocalStorage{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Put writes the file to disk, creating its directory if needed
+func (s *LocalStorage) Put(key string, data []byte, contentType string) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Delete removes the file from disk
+func (s *LocalStorage) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// URL returns the URL the file is served at
+func (s *LocalStorage) URL(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+// Handler serves stored files. Directory listings are refused so files can only be
+// fetched by their unguessable keys.
+func (s *LocalStorage) Handler() http.Handler {
+	files := http.FileServer(http.Dir(s.Dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(key))
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3Storage keeps uploaded files in a bucket of an S3-compatible object store such
+// as AWS S3 or MinIO. Requests use path-style URLs and AWS Signature Version 4.
+type S3Storage struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com or http://localhost:9002
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PublicURL       string // base URL files are downloaded from, defaults to the bucket URL
+	client          *http.Client
+}
+
+func NewS3Storage() (*S3Storage, error) {
+	s := &S3Storage{
+		Endpoint:        strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/"),
+		Region:          os.Getenv("S3_REGION"),
+		Bucket:          os.Getenv("S3_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		PublicURL:       strings.TrimSuffix(os.Getenv("S3_PUBLIC_URL"), "/"),
+		client:          &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if s.Endpoint == "" || s.Bucket == "" || s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for S3 storage")
+	}
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+	if s.PublicURL == "" {
+		s.PublicURL = s.Endpoint + "/" + s.Bucket
+	}
+	return s, nil
+}
+
+// Put uploads the file to the bucket
+func (s *S3Storage) Put(key string, data []byte, contentType string) error {
+	return s.do(http.MethodPut, key, data, contentType)
+}
+
+// Delete removes the file from the bucket
+func (s *S3Storage) Delete(key string) error {
+	return s.do(http.MethodDelete, key, nil, "")
+}
+
+// URL returns the public URL of the file
+func (s *S3Storage) URL(key string) string {
+	return s.PublicURL + "/" + escapeKey(key)
+}
+
+// do sends a signed request for an object and checks the response status
+func (s *S3Storage) do(method, key string, body []byte, contentType string) error {
+	endpoint, err := url.Parse(s.Endpoint + "/" + s.Bucket + "/" + escapeKey(key))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, endpoint, body, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 %s %s failed with status %d: %s", method, key, resp.StatusCode, message)
+	}
+	return nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to the request
+func (s *S3Storage) sign(req *http.Request, endpoint *url.URL, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Signed headers must be lowercase and sorted
+	headers := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	values := map[string]string{
+		"host":                 endpoint.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers = append([]string{"content-type"}, headers...)
+		values["content-type"] = contentType
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(values[name]) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		endpoint.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// escapeKey escapes each segment of an object key for use in a URL path
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// SurveyInterval is how often the survey job checks for completed events to survey
+const SurveyInterval = time.Minute
+
+// SurveyService emails the post-event survey to everyone who was going to an
+// event once the event is completed
+type SurveyService struct {
+	SurveyRepo   *repositories.SurveyRepository
+	RSVPRepo     *repositories.RSVPRepository
+	EmailService *EmailService
+}
+
+func NewSurveyService(surveyRepo *repositories.SurveyRepository, rsvpRepo *repositories.RSVPRepository, emailService *EmailService) *SurveyService {
+	return &SurveyService{
+		SurveyRepo:   surveyRepo,
+		RSVPRepo:     rsvpRepo,
+		EmailService: emailService,
+	}
+}
+
+// Start runs the survey job in the background every interval
+func (s *SurveyService) Start(interval time.Duration) {
+	go func() {
+		s.Run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.Run()
+		}
+	}()
+}
+
+// Run sends the surveys of the events completed since it last ran. Users with
+// RSVPs to several occurrences of a recurring event are only emailed once. A
+// series is only completed after its last occurrence, so series without an end
+// are never surveyed.
+func (s *SurveyService) Run() {
+	events, err := s.SurveyRepo.ClaimDueSurveys()
+	if err != nil {
+		log.Printf("Error getting events to survey: %v", err)
+		return
+	}
+
+	for i := range events {
+		event := &events[i]
+		rsvps, err := s.RSVPRepo.GetRSVPsByStatus(event.ID, []string{"going"})
+		if err != nil {
+			log.Printf("Error getting attendees to survey for event %d: %v", event.ID, err)
+			continue
+		}
+
+		sent := map[int]bool{}
+		for _, rsvp := range rsvps {
+			if sent[rsvp.UserID] || rsvp.Email == "" {
+				continue
+			}
+			sent[rsvp.UserID] = true
+
+			attendee := &models.User{
+				ID:        rsvp.UserID,
+				FirstName: rsvp.FirstName,
+				LastName:  rsvp.LastName,
+				Email:     rsvp.Email,
+			}
+			if err := s.EmailService.SendSurveyInvitation(event, attendee); err != nil {
+				log.Printf("Error sending survey for event %d: %v", event.ID, err)
+			}
+		}
+		log.Printf("Sent the survey for event %d to %d attendees", event.ID, len(sent))
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// TrashInterval is how often the trash job checks for deleted events to purge
+const TrashInterval = time.Hour
+
+// DefaultTrashRetentionDays is how long deleted events are kept in the trash
+// when TRASH_RETENTION_DAYS is not set
+const DefaultTrashRetentionDays = 30
+
+// TrashService permanently deletes events, with their stored files, once they
+// have been in the trash for longer than the retention period
+type TrashService struct {
+	EventRepo    *repositories.EventRepository
+	MediaService *MediaService
+	Retention    time.Duration
+}
+
+func NewTrashService(eventRepo *repositories.EventRepository, mediaService *MediaService) *TrashService {
+	days := DefaultTrashRetentionDays
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			log.Printf("Invalid TRASH_RETENTION_DAYS %q, keeping deleted events for %d days", value, days)
+		} else {
+			days = parsed
+		}
+	}
+
+	return &TrashService{
+		EventRepo:    eventRepo,
+		MediaService: mediaService,
+		Retention:    time.Duration(days) * 24 * time.Hour,
+	}
+}
+
+// PurgeAt returns when an event deleted at the given time will be purged
+func (s *TrashService) PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(s.Retention)
+}
+
+// Start runs the trash job in the background every interval
+func (s *TrashService) Start(interval time.Duration) {
+	go func() {
+		s.Run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.Run()
+		}
+	}()
+}
+
+// Run purges the events that have been in the trash for longer than the retention
+// period and removes their cover images and attachments from storage
+func (s *TrashService) Run() {
+	purged, err := s.EventRepo.PurgeDeletedEvents(time.Now().Add(-s.Retention))
+	if err != nil {
+		log.Printf("Error purging deleted events: %v", err)
+		return
+	}
+
+	for _, event := range purged {
+		if event.CoverImageKey != "" {
+			s.MediaService.DeleteCoverImage(event.CoverImageKey)
+		}
+		for _, key := range event.AttachmentKeys {
+			s.MediaService.DeleteAttachment(key)
+		}
+	}
+	if len(purged) > 0 {
+		log.Printf("Purged %d events from the trash", len(purged))
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/johneliud/evently/backend/models"
+	"github.com/johneliud/evently/backend/repositories"
+)
+
+// WaitlistService lets users know when they are moved from an event's waitlist
+// to "going", whether a seat was freed by another attendee or the organizer
+// raised the capacity
+type WaitlistService struct {
+	RSVPRepo     *repositories.RSVPRepository
+	UserRepo     *repositories.UserRepository
+	TokenService *TokenService
+	EmailService *EmailService
+}
+
+func NewWaitlistService(
+	rsvpRepo *repositories.RSVPRepository,
+	userRepo *repositories.UserRepository,
+	tokenService *TokenService,
+	emailService *EmailService,
+) *WaitlistService {
+	return &WaitlistService{
+		RSVPRepo:     rsvpRepo,
+		UserRepo:     userRepo,
+		TokenService: tokenService,
+		EmailService: emailService,
+	}
+}
+
+// NotifyPromoted emails users who were moved from the waitlist to "going" along
+// with their tickets
+func (s *WaitlistService) NotifyPromoted(event *models.Event, occurrence *time.Time, userIDs []int) {
+	for _, promotedID := range userIDs {
+		log.Printf("User %d promoted from the waitlist for event %d\n", promotedID, event.ID)
+
+		promotedUser, err := s.UserRepo.GetUserByID(promotedID)
+		if err != nil {
+			log.Printf("Warning: Could not get promoted user details: %v\n", err)
+			continue
+		}
+		if promotedUser.Email == "" {
+			continue
+		}
+
+		ticketCode := s.TicketCode(event.ID, promotedID, occurrence)
+		go func() {
+			err := s.EmailService.SendWaitlistPromotion(event, promotedUser, ticketCode)
+			if err != nil {
+				log.Printf("Error sending waitlist promotion: %v\n", err)
+			}
+		}()
+	}
+}
+
+// TicketCode returns the ticket code for a user's RSVP, or an empty string if it
+// cannot be found
+func (s *WaitlistService) TicketCode(eventID, userID int, occurrence *time.Time) string {
+	rsvp, err := s.RSVPRepo.GetRSVPByEventAndUser(eventID, userID, occurrence)
+	if err != nil || rsvp == nil {
+		log.Printf("Warning: Could not get RSVP to issue a ticket: %v\n", err)
+		return ""
+	}
+	return s.TokenService.SignTicket(eventID, rsvp.ID)
+}
@@ -9,6 +9,7 @@ import EventList from './components/EventList';
 import UpcomingEvents from './components/UpcomingEvents';
 import EventDetails from './components/EventDetails';
 import EventSearch from './components/EventSearch';
+import EventTrash from './components/EventTrash';
 import CalendarConnected from './components/CalendarConnected';
 import AuthCallback from './components/AuthCallback';
 import Footer from './components/Footer';
@@ -54,6 +55,8 @@ function App() {
         return requireAuth(<EventForm />);
       case '/my-events':
         return requireAuth(<EventList />);
+      case '/trash':
+        return requireAuth(<EventTrash />);
       case '/upcoming-events':
         return <UpcomingEvents />;
       case '/search':
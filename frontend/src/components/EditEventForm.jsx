@@ -1,9 +1,12 @@
 import { useState, useEffect } from 'react';
 import Notification from './Notification';
+import VenuePicker, { describeConflicts } from './VenuePicker';
 import config from '../config';
 
 export default function EditEventForm({ eventId, onCancel, onSuccess }) {
   const [event, setEvent] = useState(null);
+  // The version of the event being edited, so edits made meanwhile aren't overwritten
+  const [etag, setEtag] = useState(null);
   const [isLoading, setIsLoading] = useState(true);
   const [isSaving, setIsSaving] = useState(false);
   const [notification, setNotification] = useState(null);
@@ -12,7 +15,10 @@ export default function EditEventForm({ eventId, onCancel, onSuccess }) {
     const fetchEvent = async () => {
       setIsLoading(true);
       try {
-        const response = await fetch(`${config.apiBaseUrl}/api/events/${eventId}`);
+        const token = localStorage.getItem('token');
+        const response = await fetch(`${config.apiBaseUrl}/api/events/${eventId}`, {
+          headers: token ? { Authorization: `Bearer ${token}` } : {},
+        });
 
         if (!response.ok) {
           throw new Error('Failed to fetch event details');
@@ -20,6 +26,7 @@ export default function EditEventForm({ eventId, onCancel, onSuccess }) {
 
         const data = await response.json();
         setEvent(data);
+        setEtag(response.headers.get('ETag'));
       } catch (error) {
         setNotification({
           type: 'error',
@@ -43,10 +50,24 @@ export default function EditEventForm({ eventId, onCancel, onSuccess }) {
     const date = formData.get('date');
     const time = formData.get('time');
     const location = formData.get('location');
+    const duration = formData.get('duration');
+    const maxGuests = formData.get('max_guests');
+    const rsvpOpensAt = formData.get('rsvp_opens_at');
+    const rsvpClosesAt = formData.get('rsvp_closes_at');
+    const venueId = formData.get('venue_id');
+    const roomId = formData.get('room_id');
 
     // Combine date and time
     const dateTime = new Date(`${date}T${time}`);
 
+    // The event ends after its duration, so it keeps its length when it is moved
+    let endDate;
+    if (duration) {
+      endDate = new Date(
+        dateTime.getTime() + parseInt(duration, 10) * 60000
+      ).toISOString();
+    }
+
     try {
       const token = localStorage.getItem('token');
       if (!token) {
@@ -58,20 +79,48 @@ export default function EditEventForm({ eventId, onCancel, onSuccess }) {
         headers: {
           'Content-Type': 'application/json',
           Authorization: `Bearer ${token}`,
+          ...(etag ? { 'If-Match': etag } : {}),
         },
         body: JSON.stringify({
           title,
           description,
           date: dateTime.toISOString(),
+          end_date: endDate,
+          timezone: event.timezone || Intl.DateTimeFormat().resolvedOptions().timeZone,
           location,
+          latitude: event.latitude,
+          longitude: event.longitude,
+          recurrence_rule: event.recurrence_rule,
+          capacity: event.capacity,
+          max_guests: maxGuests ? parseInt(maxGuests, 10) : 0,
+          rsvp_opens_at: rsvpOpensAt ? new Date(rsvpOpensAt).toISOString() : undefined,
+          rsvp_closes_at: rsvpClosesAt ? new Date(rsvpClosesAt).toISOString() : undefined,
+          requires_approval: formData.get('requires_approval') === 'on',
+          visibility: event.visibility,
+          venue_id: venueId ? parseInt(venueId, 10) : undefined,
+          room_id: roomId ? parseInt(roomId, 10) : undefined,
+          allow_conflicts: formData.get('allow_conflicts') === 'on',
         }),
       });
 
+      if (response.status === 412) {
+        throw new Error(
+          'Someone else changed this event while you were editing it. Reload the page to see their changes before saving yours.'
+        );
+      }
+
       if (!response.ok) {
-        const data = await response.json();
+        const text = await response.text();
+        const data = text.startsWith('{') ? JSON.parse(text) : { message: text };
+        // The venue is already booked at this time
+        if (data.conflicts) {
+          throw new Error(describeConflicts(data));
+        }
         throw new Error(data.message || 'Failed to update event');
       }
 
+      setEtag(response.headers.get('ETag'));
+
       // Show success notification
       setNotification({
         type: 'success',
@@ -100,6 +149,21 @@ export default function EditEventForm({ eventId, onCancel, onSuccess }) {
     return date.toISOString().split('T')[0];
   }
 
+  // Format the event's length in minutes for the duration field
+  function formatDurationForInput(event) {
+    if (!event.end_date) return '';
+    return Math.round((new Date(event.end_date) - new Date(event.date)) / 60000);
+  }
+
+  // Format a timestamp in local time for a datetime-local field
+  function formatDateTimeForInput(dateString) {
+    if (!dateString) return '';
+    const date = new Date(dateString);
+    return new Date(date.getTime() - date.getTimezoneOffset() * 60000)
+      .toISOString()
+      .substring(0, 16);
+  }
+
   // Format time for input field
   function formatTimeForInput(dateString) {
     const date = new Date(dateString);
@@ -202,20 +266,92 @@ export default function EditEventForm({ eventId, onCancel, onSuccess }) {
           </div>
         </div>
 
+        <div>
+          <label htmlFor="duration" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+            Duration (minutes)
+          </label>
+          <input
+            type="number"
+            name="duration"
+            id="duration"
+            min="1"
+            defaultValue={formatDurationForInput(event)}
+            placeholder="Required when booking a venue"
+            className="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-primary-500 focus:ring-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+          />
+        </div>
+
+        <div>
+          <label htmlFor="max_guests" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+            Guests per RSVP
+          </label>
+          <input
+            type="number"
+            name="max_guests"
+            id="max_guests"
+            min="0"
+            max="20"
+            defaultValue={event.max_guests || ''}
+            placeholder="How many people each attendee may bring"
+            className="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-primary-500 focus:ring-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+          />
+        </div>
+
+        <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
+          <div>
+            <label htmlFor="rsvp_opens_at" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+              RSVPs open
+            </label>
+            <input
+              type="datetime-local"
+              name="rsvp_opens_at"
+              id="rsvp_opens_at"
+              defaultValue={formatDateTimeForInput(event.rsvp_opens_at)}
+              className="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-primary-500 focus:ring-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+            />
+          </div>
+          <div>
+            <label htmlFor="rsvp_closes_at" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+              RSVPs close
+            </label>
+            <input
+              type="datetime-local"
+              name="rsvp_closes_at"
+              id="rsvp_closes_at"
+              defaultValue={formatDateTimeForInput(event.rsvp_closes_at)}
+              className="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-primary-500 focus:ring-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+            />
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              Defaults to when the event starts. For repeating events, set the window of the first occurrence
+            </p>
+          </div>
+        </div>
+
+        <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300">
+          <input
+            type="checkbox"
+            name="requires_approval"
+            defaultChecked={event.requires_approval}
+          />
+          Approve each attendee before they can go
+        </label>
+
         <div>
           <label htmlFor="location" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
-            Location *
+            Location
           </label>
           <input
             type="text"
             name="location"
             id="location"
-            required
             defaultValue={event.location}
+            placeholder="Leave empty to use the venue's address"
             className="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-primary-500 focus:ring-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
           />
         </div>
 
+        <VenuePicker venueId={event.venue_id} roomId={event.room_id} />
+
         <div className="flex justify-end space-x-3">
           <button
             type="button"
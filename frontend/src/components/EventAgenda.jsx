@@ -0,0 +1,320 @@
+import { useState, useEffect } from 'react';
+import Notification from './Notification';
+import config from '../config';
+
+const emptySession = {
+  title: '',
+  speaker: '',
+  start_time: '',
+  end_time: '',
+  room: '',
+  track: '',
+  capacity: '',
+};
+
+export default function EventAgenda({
+  eventId,
+  canEdit,
+  isGoing,
+  accessHeaders,
+}) {
+  const [agenda, setAgenda] = useState({ tracks: [], sessions: [] });
+  const [track, setTrack] = useState('');
+  const [mineOnly, setMineOnly] = useState(false);
+  const [newSession, setNewSession] = useState(emptySession);
+  const [isAdding, setIsAdding] = useState(false);
+  const [notification, setNotification] = useState(null);
+
+  const isLoggedIn = !!localStorage.getItem('token');
+
+  useEffect(() => {
+    fetchAgenda();
+  }, [eventId, track, mineOnly]);
+
+  async function fetchAgenda() {
+    try {
+      const params = new URLSearchParams();
+      if (track) params.set('track', track);
+      if (mineOnly) params.set('mine', 'true');
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/sessions?${params}`,
+        { headers: accessHeaders() }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch agenda');
+      }
+
+      setAgenda(await response.json());
+    } catch (error) {
+      console.error('Error fetching agenda:', error);
+    }
+  }
+
+  async function request(path, method, body) {
+    try {
+      const headers = accessHeaders();
+      if (body) headers['Content-Type'] = 'application/json';
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/sessions${path}`,
+        {
+          method,
+          headers,
+          body: body ? JSON.stringify(body) : undefined,
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to update agenda');
+      }
+
+      fetchAgenda();
+      return true;
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while updating the agenda',
+      });
+      return false;
+    }
+  }
+
+  async function handleAddSession(e) {
+    e.preventDefault();
+    const added = await request('', 'POST', {
+      ...newSession,
+      start_time: new Date(newSession.start_time).toISOString(),
+      end_time: new Date(newSession.end_time).toISOString(),
+      capacity: newSession.capacity ? parseInt(newSession.capacity, 10) : null,
+    });
+    if (added) {
+      setNewSession(emptySession);
+      setIsAdding(false);
+    }
+  }
+
+  function formatTime(dateString) {
+    return new Date(dateString).toLocaleString(undefined, {
+      weekday: 'short',
+      hour: '2-digit',
+      minute: '2-digit',
+    });
+  }
+
+  if (!canEdit && agenda.sessions.length === 0 && !track && !mineOnly) {
+    return null;
+  }
+
+  const inputClass =
+    'px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white';
+
+  return (
+    <div className="mb-8">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      <div className="flex flex-wrap justify-between items-center gap-3 mb-2">
+        <h2 className="text-xl font-semibold text-gray-900 dark:text-white">
+          Agenda
+        </h2>
+        <div className="flex items-center gap-3 text-sm">
+          {agenda.tracks.length > 0 && (
+            <select
+              value={track}
+              onChange={(e) => setTrack(e.target.value)}
+              className={inputClass}
+            >
+              <option value="">All tracks</option>
+              {agenda.tracks.map((name) => (
+                <option key={name} value={name}>
+                  {name}
+                </option>
+              ))}
+            </select>
+          )}
+          {isLoggedIn && (
+            <label className="flex items-center gap-1 text-gray-700 dark:text-gray-300">
+              <input
+                type="checkbox"
+                checked={mineOnly}
+                onChange={(e) => setMineOnly(e.target.checked)}
+              />
+              My schedule
+            </label>
+          )}
+        </div>
+      </div>
+
+      {agenda.sessions.length === 0 ? (
+        <p className="text-gray-600 dark:text-gray-400">No sessions yet.</p>
+      ) : (
+        <ul className="divide-y divide-gray-200 dark:divide-gray-700">
+          {agenda.sessions.map((session) => (
+            <li key={session.id} className="py-3 flex justify-between gap-4">
+              <div>
+                <p className="text-sm text-gray-500 dark:text-gray-400">
+                  {formatTime(session.start_time)} -{' '}
+                  {new Date(session.end_time).toLocaleTimeString(undefined, {
+                    hour: '2-digit',
+                    minute: '2-digit',
+                  })}
+                  {session.room && ` · ${session.room}`}
+                  {session.track && ` · ${session.track}`}
+                </p>
+                <p className="font-medium text-gray-900 dark:text-white">
+                  {session.title}
+                </p>
+                {session.speaker && (
+                  <p className="text-sm text-gray-700 dark:text-gray-300">
+                    {session.speaker}
+                  </p>
+                )}
+              </div>
+              <div className="flex flex-col items-end gap-1 text-sm">
+                {session.remaining_seats !== undefined && (
+                  <span className="text-gray-500 dark:text-gray-400">
+                    {session.remaining_seats} seats left
+                  </span>
+                )}
+                {isGoing &&
+                  (session.attending ? (
+                    <button
+                      onClick={() => request(`/${session.id}/rsvp`, 'DELETE')}
+                      className="text-red-600 hover:text-red-700 dark:text-red-400"
+                    >
+                      Leave
+                    </button>
+                  ) : (
+                    <button
+                      onClick={() => request(`/${session.id}/rsvp`, 'POST')}
+                      disabled={session.remaining_seats === 0}
+                      className="text-primary-600 hover:text-primary-700 dark:text-primary-400 disabled:opacity-50"
+                    >
+                      {session.remaining_seats === 0 ? 'Full' : 'Join'}
+                    </button>
+                  ))}
+                {canEdit && (
+                  <button
+                    onClick={() => {
+                      if (window.confirm('Remove this session?')) {
+                        request(`/${session.id}`, 'DELETE');
+                      }
+                    }}
+                    className="text-red-600 hover:text-red-700 dark:text-red-400"
+                  >
+                    Remove
+                  </button>
+                )}
+              </div>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {canEdit &&
+        (isAdding ? (
+          <form
+            onSubmit={handleAddSession}
+            className="mt-4 grid grid-cols-1 sm:grid-cols-2 gap-3"
+          >
+            <input
+              type="text"
+              required
+              placeholder="Title"
+              value={newSession.title}
+              onChange={(e) =>
+                setNewSession({ ...newSession, title: e.target.value })
+              }
+              className={inputClass}
+            />
+            <input
+              type="text"
+              placeholder="Speaker"
+              value={newSession.speaker}
+              onChange={(e) =>
+                setNewSession({ ...newSession, speaker: e.target.value })
+              }
+              className={inputClass}
+            />
+            <input
+              type="datetime-local"
+              required
+              value={newSession.start_time}
+              onChange={(e) =>
+                setNewSession({ ...newSession, start_time: e.target.value })
+              }
+              className={inputClass}
+            />
+            <input
+              type="datetime-local"
+              required
+              value={newSession.end_time}
+              onChange={(e) =>
+                setNewSession({ ...newSession, end_time: e.target.value })
+              }
+              className={inputClass}
+            />
+            <input
+              type="text"
+              placeholder="Room"
+              value={newSession.room}
+              onChange={(e) =>
+                setNewSession({ ...newSession, room: e.target.value })
+              }
+              className={inputClass}
+            />
+            <input
+              type="text"
+              placeholder="Track"
+              value={newSession.track}
+              onChange={(e) =>
+                setNewSession({ ...newSession, track: e.target.value })
+              }
+              className={inputClass}
+            />
+            <input
+              type="number"
+              min="1"
+              placeholder="Capacity (optional)"
+              value={newSession.capacity}
+              onChange={(e) =>
+                setNewSession({ ...newSession, capacity: e.target.value })
+              }
+              className={inputClass}
+            />
+            <div className="flex gap-3">
+              <button
+                type="submit"
+                className="px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700"
+              >
+                Add session
+              </button>
+              <button
+                type="button"
+                onClick={() => setIsAdding(false)}
+                className="px-4 py-2 border border-gray-300 text-sm font-medium rounded-md text-gray-700 bg-white hover:bg-gray-50 dark:bg-gray-700 dark:text-gray-300 dark:border-gray-600"
+              >
+                Cancel
+              </button>
+            </div>
+          </form>
+        ) : (
+          <button
+            onClick={() => setIsAdding(true)}
+            className="mt-3 text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+          >
+            Add session
+          </button>
+        ))}
+    </div>
+  );
+}
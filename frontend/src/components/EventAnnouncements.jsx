@@ -0,0 +1,219 @@
+import { useState, useEffect } from 'react';
+import Notification from './Notification';
+import config from '../config';
+
+const inputClass =
+  'block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white';
+
+const statusLabels = {
+  going: 'Going',
+  maybe: 'Maybe',
+  waitlisted: 'Waitlisted',
+};
+
+// EventAnnouncements shows the announcements sent about an event and lets
+// organizers email new ones to attendees
+export default function EventAnnouncements({ eventId, canAnnounce, accessHeaders }) {
+  const [announcements, setAnnouncements] = useState([]);
+  const [nextCursor, setNextCursor] = useState('');
+  const [subject, setSubject] = useState('');
+  const [body, setBody] = useState('');
+  const [statuses, setStatuses] = useState(['going']);
+  const [isComposing, setIsComposing] = useState(false);
+  const [isSending, setIsSending] = useState(false);
+  const [notification, setNotification] = useState(null);
+
+  useEffect(() => {
+    fetchAnnouncements();
+  }, [eventId]);
+
+  async function fetchAnnouncements(cursor = '') {
+    try {
+      const params = new URLSearchParams();
+      if (cursor) params.set('cursor', cursor);
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/announcements?${params}`,
+        { headers: accessHeaders() }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch announcements');
+      }
+
+      const data = await response.json();
+      setAnnouncements(cursor ? [...announcements, ...data.items] : data.items);
+      setNextCursor(data.next_cursor || '');
+    } catch (error) {
+      console.error('Error fetching announcements:', error);
+    }
+  }
+
+  async function handleSend(e) {
+    e.preventDefault();
+    setIsSending(true);
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/announcements`,
+        {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            ...accessHeaders(),
+          },
+          body: JSON.stringify({ subject, body, statuses }),
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to send announcement');
+      }
+
+      const data = await response.json();
+      setNotification({
+        type: 'success',
+        message: `Announcement is being sent to ${data.recipient_count} attendees`,
+      });
+      setSubject('');
+      setBody('');
+      setIsComposing(false);
+      fetchAnnouncements();
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while sending the announcement',
+      });
+    } finally {
+      setIsSending(false);
+    }
+  }
+
+  if (!canAnnounce && announcements.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className="mb-8">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+        Announcements
+      </h2>
+
+      {announcements.length === 0 ? (
+        <p className="text-gray-600 dark:text-gray-400">
+          No announcements have been sent yet.
+        </p>
+      ) : (
+        <ul className="divide-y divide-gray-200 dark:divide-gray-700">
+          {announcements.map((announcement) => (
+            <li key={announcement.id} className="py-3">
+              <p className="font-medium text-gray-900 dark:text-white">
+                {announcement.subject}
+              </p>
+              <p className="text-sm text-gray-500 dark:text-gray-400">
+                {new Date(announcement.created_at).toLocaleString()}
+                {announcement.sender_first_name &&
+                  ` · ${announcement.sender_first_name} ${announcement.sender_last_name}`}
+                {canAnnounce &&
+                  ` · To ${announcement.statuses
+                    .map((status) => statusLabels[status])
+                    .join(', ')} · ${
+                    announcement.delivered_at
+                      ? `${announcement.delivered_count} of ${announcement.recipient_count} delivered`
+                      : 'Sending...'
+                  }`}
+              </p>
+              <p className="mt-1 text-gray-800 dark:text-gray-200 whitespace-pre-line">
+                {announcement.body}
+              </p>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {nextCursor && (
+        <button
+          onClick={() => fetchAnnouncements(nextCursor)}
+          className="mt-3 text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+        >
+          Show older announcements
+        </button>
+      )}
+
+      {canAnnounce &&
+        (isComposing ? (
+          <form onSubmit={handleSend} className="mt-4 space-y-3">
+            <input
+              type="text"
+              required
+              maxLength={255}
+              placeholder="Subject"
+              value={subject}
+              onChange={(e) => setSubject(e.target.value)}
+              className={inputClass}
+            />
+            <textarea
+              required
+              rows={4}
+              maxLength={5000}
+              placeholder="Message"
+              value={body}
+              onChange={(e) => setBody(e.target.value)}
+              className={inputClass}
+            />
+            <div className="flex flex-wrap gap-4 text-sm text-gray-700 dark:text-gray-300">
+              <span>Send to:</span>
+              {Object.entries(statusLabels).map(([status, label]) => (
+                <label key={status} className="flex items-center gap-1">
+                  <input
+                    type="checkbox"
+                    checked={statuses.includes(status)}
+                    onChange={(e) =>
+                      setStatuses(
+                        e.target.checked
+                          ? [...statuses, status]
+                          : statuses.filter((s) => s !== status)
+                      )
+                    }
+                  />
+                  {label}
+                </label>
+              ))}
+            </div>
+            <div className="flex gap-3">
+              <button
+                type="submit"
+                disabled={isSending || statuses.length === 0}
+                className="px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700 disabled:opacity-50"
+              >
+                {isSending ? 'Sending...' : 'Send announcement'}
+              </button>
+              <button
+                type="button"
+                onClick={() => setIsComposing(false)}
+                className="px-4 py-2 border border-gray-300 text-sm font-medium rounded-md text-gray-700 bg-white hover:bg-gray-50 dark:bg-gray-700 dark:text-gray-300 dark:border-gray-600"
+              >
+                Cancel
+              </button>
+            </div>
+          </form>
+        ) : (
+          <button
+            onClick={() => setIsComposing(true)}
+            className="mt-3 text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+          >
+            New announcement
+          </button>
+        ))}
+    </div>
+  );
+}
@@ -0,0 +1,263 @@
+import { useState, useEffect } from 'react';
+import Notification from './Notification';
+import config from '../config';
+
+const inputClass =
+  'block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white';
+
+// CommentForm posts a new comment, a reply or an edit
+function CommentForm({ initialBody = '', submitLabel, onSubmit, onCancel }) {
+  const [body, setBody] = useState(initialBody);
+
+  async function handleSubmit(e) {
+    e.preventDefault();
+    if (await onSubmit(body)) {
+      setBody('');
+    }
+  }
+
+  return (
+    <form onSubmit={handleSubmit} className="mt-2">
+      <textarea
+        required
+        rows={2}
+        maxLength={2000}
+        value={body}
+        onChange={(e) => setBody(e.target.value)}
+        className={inputClass}
+      />
+      <div className="mt-2 flex gap-3">
+        <button
+          type="submit"
+          className="px-3 py-1 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700"
+        >
+          {submitLabel}
+        </button>
+        {onCancel && (
+          <button
+            type="button"
+            onClick={onCancel}
+            className="text-sm text-gray-600 hover:text-gray-700 dark:text-gray-400"
+          >
+            Cancel
+          </button>
+        )}
+      </div>
+    </form>
+  );
+}
+
+// EventComments shows an event's discussion, where attendees ask questions and
+// organizers answer, pin and moderate them
+export default function EventComments({ eventId, canModerate, accessHeaders }) {
+  const [comments, setComments] = useState([]);
+  const [nextCursor, setNextCursor] = useState('');
+  const [replyingTo, setReplyingTo] = useState(null);
+  const [editing, setEditing] = useState(null);
+  const [notification, setNotification] = useState(null);
+
+  const currentUserId = parseInt(localStorage.getItem('userId'), 10);
+  const isLoggedIn = !!localStorage.getItem('token');
+
+  useEffect(() => {
+    fetchComments();
+  }, [eventId]);
+
+  async function fetchComments(cursor = '') {
+    try {
+      const params = new URLSearchParams();
+      if (cursor) params.set('cursor', cursor);
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/comments?${params}`,
+        { headers: accessHeaders() }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch comments');
+      }
+
+      const data = await response.json();
+      setComments(cursor ? [...comments, ...data.items] : data.items);
+      setNextCursor(data.next_cursor || '');
+    } catch (error) {
+      console.error('Error fetching comments:', error);
+    }
+  }
+
+  async function request(path, method, body) {
+    try {
+      const headers = accessHeaders();
+      if (body) headers['Content-Type'] = 'application/json';
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/comments${path}`,
+        {
+          method,
+          headers,
+          body: body ? JSON.stringify(body) : undefined,
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to update comments');
+      }
+
+      setReplyingTo(null);
+      setEditing(null);
+      fetchComments();
+      return true;
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while updating comments',
+      });
+      return false;
+    }
+  }
+
+  function renderComment(comment, isReply) {
+    return (
+      <div key={comment.id} className={isReply ? 'ml-6 mt-3' : 'py-3'}>
+        {comment.deleted ? (
+          <p className="text-sm italic text-gray-500 dark:text-gray-400">
+            This comment was deleted.
+          </p>
+        ) : (
+          <>
+            <p className="text-sm text-gray-500 dark:text-gray-400">
+              <span className="font-medium text-gray-900 dark:text-white">
+                {comment.first_name} {comment.last_name}
+              </span>{' '}
+              · {new Date(comment.created_at).toLocaleString()}
+              {comment.edited_at && ' · edited'}
+              {comment.pinned && (
+                <span className="ml-2 text-primary-600 dark:text-primary-400">
+                  Pinned
+                </span>
+              )}
+            </p>
+            {editing === comment.id ? (
+              <CommentForm
+                initialBody={comment.body}
+                submitLabel="Save"
+                onSubmit={(body) =>
+                  request(`/${comment.id}`, 'PUT', { body })
+                }
+                onCancel={() => setEditing(null)}
+              />
+            ) : (
+              <p className="mt-1 text-gray-800 dark:text-gray-200 whitespace-pre-line">
+                {comment.body}
+              </p>
+            )}
+            <div className="mt-1 flex gap-3 text-xs">
+              {isLoggedIn && !isReply && (
+                <button
+                  onClick={() => setReplyingTo(comment.id)}
+                  className="text-primary-600 hover:text-primary-700 dark:text-primary-400"
+                >
+                  Reply
+                </button>
+              )}
+              {comment.user_id === currentUserId && (
+                <button
+                  onClick={() => setEditing(comment.id)}
+                  className="text-primary-600 hover:text-primary-700 dark:text-primary-400"
+                >
+                  Edit
+                </button>
+              )}
+              {canModerate && !isReply && (
+                <button
+                  onClick={() =>
+                    request(
+                      `/${comment.id}/pin`,
+                      comment.pinned ? 'DELETE' : 'POST'
+                    )
+                  }
+                  className="text-primary-600 hover:text-primary-700 dark:text-primary-400"
+                >
+                  {comment.pinned ? 'Unpin' : 'Pin'}
+                </button>
+              )}
+              {(comment.user_id === currentUserId || canModerate) && (
+                <button
+                  onClick={() => {
+                    if (window.confirm('Delete this comment?')) {
+                      request(`/${comment.id}`, 'DELETE');
+                    }
+                  }}
+                  className="text-red-600 hover:text-red-700 dark:text-red-400"
+                >
+                  Delete
+                </button>
+              )}
+            </div>
+          </>
+        )}
+        {!isReply &&
+          (comment.replies || []).map((reply) => renderComment(reply, true))}
+        {replyingTo === comment.id && (
+          <div className="ml-6">
+            <CommentForm
+              submitLabel="Reply"
+              onSubmit={(body) =>
+                request('', 'POST', { body, parent_id: comment.id })
+              }
+              onCancel={() => setReplyingTo(null)}
+            />
+          </div>
+        )}
+      </div>
+    );
+  }
+
+  return (
+    <div className="mb-8">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+        Discussion
+      </h2>
+
+      {isLoggedIn ? (
+        <CommentForm
+          submitLabel="Post"
+          onSubmit={(body) => request('', 'POST', { body })}
+        />
+      ) : (
+        <p className="text-sm text-gray-500 dark:text-gray-400">
+          Sign in to join the discussion.
+        </p>
+      )}
+
+      {comments.length === 0 ? (
+        <p className="mt-3 text-gray-600 dark:text-gray-400">
+          No one has commented yet.
+        </p>
+      ) : (
+        <div className="mt-3 divide-y divide-gray-200 dark:divide-gray-700">
+          {comments.map((comment) => renderComment(comment, false))}
+        </div>
+      )}
+
+      {nextCursor && (
+        <button
+          onClick={() => fetchComments(nextCursor)}
+          className="mt-3 text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+        >
+          Show more comments
+        </button>
+      )}
+    </div>
+  );
+}
@@ -2,8 +2,24 @@ import { useState, useEffect } from 'react';
 import Notification from './Notification';
 import EditEventForm from './EditEventForm';
 import GoogleCalendarButton from './GoogleCalendarButton';
+import EventAgenda from './EventAgenda';
+import EventQuestions, { QuestionInputs } from './EventQuestions';
+import RsvpOverrides from './RsvpOverrides';
+import EventComments from './EventComments';
+import EventAnnouncements from './EventAnnouncements';
+import EventSurvey from './EventSurvey';
+import EventHistory from './EventHistory';
 import config from '../config';
 
+const rsvpStatusLabels = {
+  going: 'Going',
+  maybe: 'Maybe',
+  not_going: 'Not Going',
+  waitlisted: 'Waitlisted',
+  pending: 'Awaiting Approval',
+  declined: 'Declined',
+};
+
 export default function EventDetails() {
   // Get the event ID from the URL path
   const path = window.location.pathname;
@@ -12,6 +28,8 @@ export default function EventDetails() {
   const [isLoading, setIsLoading] = useState(true);
   const [isEditing, setIsEditing] = useState(false);
   const [isDeleting, setIsDeleting] = useState(false);
+  const [isUpdatingStatus, setIsUpdatingStatus] = useState(false);
+  const [isUploading, setIsUploading] = useState(false);
   const [notification, setNotification] = useState(null);
   const [rsvpStatus, setRsvpStatus] = useState(null);
   const [rsvpCounts, setRsvpCounts] = useState({
@@ -22,14 +40,39 @@ export default function EventDetails() {
   const [isRsvpLoading, setIsRsvpLoading] = useState(false);
   const [attendees, setAttendees] = useState([]);
   const [isAttendeesLoading, setIsAttendeesLoading] = useState(false);
+  const [ticketUrl, setTicketUrl] = useState(null);
+  const [checkInCode, setCheckInCode] = useState('');
+  const [lastCheckIn, setLastCheckIn] = useState(null);
+  const [isCheckingIn, setIsCheckingIn] = useState(false);
+  const [questions, setQuestions] = useState([]);
+  const [answers, setAnswers] = useState({});
+  const [guests, setGuests] = useState(0);
+  const [guestNames, setGuestNames] = useState('');
+  const [organizer, setOrganizer] = useState(null);
 
   // Get the current user ID from localStorage
   const currentUserId = parseInt(localStorage.getItem('userId'), 10);
   const isLoggedIn = !!localStorage.getItem('token');
 
+  // Invite token from a shared link, required to open private events
+  const inviteToken = new URLSearchParams(window.location.search).get('invite');
+
+  function accessHeaders() {
+    const headers = {};
+    const token = localStorage.getItem('token');
+    if (token) {
+      headers.Authorization = `Bearer ${token}`;
+    }
+    if (inviteToken) {
+      headers['X-Invite-Token'] = inviteToken;
+    }
+    return headers;
+  }
+
   useEffect(() => {
     if (id) {
       fetchEventDetails(id);
+      fetchQuestions(id);
       if (isLoggedIn) {
         fetchRsvpStatus(id);
         fetchRsvpCounts(id);
@@ -43,18 +86,115 @@ export default function EventDetails() {
     }
   }, [id, isLoggedIn]);
 
-  // Add a new useEffect to fetch attendees when the event is loaded and user is the creator
+  // Add a new useEffect to fetch attendees when the event is loaded and user helps run it
   useEffect(() => {
-    if (event && currentUserId === event.user_id) {
+    if (event && event.role) {
       fetchAttendees(id);
     }
   }, [event, currentUserId, id]);
 
+  // Show the organizer's rating from the post-event surveys of their events
+  useEffect(() => {
+    if (event) {
+      fetchOrganizer(event.user_id);
+    }
+  }, [event?.user_id]);
+
+  // Show the attendee's ticket while they are going
+  useEffect(() => {
+    if (rsvpStatus !== 'going') {
+      setTicketUrl(null);
+      return;
+    }
+
+    let url;
+    fetchTicket(id).then((ticket) => {
+      url = ticket;
+      setTicketUrl(ticket);
+    });
+    return () => {
+      if (url) URL.revokeObjectURL(url);
+    };
+  }, [rsvpStatus, id]);
+
+  async function fetchTicket(eventId) {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/ticket`,
+        { headers: accessHeaders() }
+      );
+      if (!response.ok) return null;
+
+      const image = await response.blob();
+      return URL.createObjectURL(image);
+    } catch (error) {
+      console.error('Error fetching ticket:', error);
+      return null;
+    }
+  }
+
+  // Check in an attendee by the code on their ticket
+  async function handleCheckIn(e) {
+    e.preventDefault();
+    if (!checkInCode.trim()) return;
+
+    setIsCheckingIn(true);
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${event.id}/check-in`,
+        {
+          method: 'POST',
+          headers: {
+            ...accessHeaders(),
+            'Content-Type': 'application/json',
+          },
+          body: JSON.stringify({ code: checkInCode.trim() }),
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to check in');
+      }
+
+      const data = await response.json();
+      setLastCheckIn(data.attendee);
+      setRsvpCounts(data.count);
+      setCheckInCode('');
+      fetchAttendees(event.id);
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while checking in',
+      });
+    } finally {
+      setIsCheckingIn(false);
+    }
+  }
+
+  async function fetchOrganizer(organizerId) {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/organizers/${organizerId}`
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch organizer');
+      }
+
+      setOrganizer(await response.json());
+    } catch (error) {
+      console.error('Error fetching organizer:', error);
+    }
+  }
+
   async function fetchEventDetails(eventId) {
     setIsLoading(true);
     try {
       const response = await fetch(
-        `${config.apiBaseUrl}/api/events/${eventId}`
+        `${config.apiBaseUrl}/api/events/${eventId}`,
+        { headers: accessHeaders() }
       );
 
       if (!response.ok) {
@@ -99,15 +239,71 @@ export default function EventDetails() {
 
       const data = await response.json();
       setRsvpStatus(data ? data.status : null);
+      if (data) {
+        setGuests(data.guests || 0);
+        setGuestNames((data.guest_names || []).join(', '));
+      }
+      if (data && data.answers) {
+        const given = {};
+        data.answers.forEach((answer) => {
+          given[answer.question_id] = {
+            text: answer.text || '',
+            choices: answer.choices || [],
+          };
+        });
+        setAnswers(given);
+      }
     } catch (error) {
       console.error('Error fetching RSVP status:', error);
     }
   }
 
+  async function fetchQuestions(eventId) {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/questions`,
+        { headers: accessHeaders() }
+      );
+      if (!response.ok) return;
+
+      setQuestions(await response.json());
+    } catch (error) {
+      console.error('Error fetching questions:', error);
+    }
+  }
+
+  // Download the RSVPs with their answers as a CSV file
+  async function handleExportRsvps() {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${id}/rsvps/export`,
+        { headers: accessHeaders() }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to export RSVPs');
+      }
+
+      const url = URL.createObjectURL(await response.blob());
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = `event-${id}-rsvps.csv`;
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while exporting RSVPs',
+      });
+    }
+  }
+
   async function fetchRsvpCounts(eventId) {
     try {
       const response = await fetch(
-        `${config.apiBaseUrl}/api/events/${eventId}/rsvp/count`
+        `${config.apiBaseUrl}/api/events/${eventId}/rsvp/count`,
+        { headers: accessHeaders() }
       );
 
       if (!response.ok) {
@@ -163,10 +359,18 @@ export default function EventDetails() {
             method: 'POST',
             headers: {
               'Content-Type': 'application/json',
-              Authorization: `Bearer ${token}`,
+              ...accessHeaders(),
             },
             body: JSON.stringify({
               status: status,
+              ...(event.max_guests > 0 && {
+                guests: guests,
+                guest_names: guestNames.split(',').filter((n) => n.trim()),
+              }),
+              answers: questions.map((question) => ({
+                question_id: question.id,
+                ...(answers[question.id] || { text: '', choices: [] }),
+              })),
             }),
           }
         );
@@ -176,10 +380,14 @@ export default function EventDetails() {
           throw new Error(data.message || 'Failed to update RSVP');
         }
 
-        setRsvpStatus(status);
+        const data = await response.json();
+        setRsvpStatus(data.status || status);
         setNotification({
           type: 'success',
-          message: 'RSVP updated successfully',
+          message:
+            data.status === 'pending'
+              ? 'Your request was sent to the organizer for approval'
+              : 'RSVP updated successfully',
         });
       }
 
@@ -195,6 +403,39 @@ export default function EventDetails() {
     }
   }
 
+  // handleReviewRsvps approves or declines pending RSVPs. path is either
+  // approve/decline for every pending RSVP given, or :rsvpId/approve for one.
+  async function handleReviewRsvps(path, rsvpIds = []) {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${id}/rsvps/${path}`,
+        {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            Authorization: `Bearer ${localStorage.getItem('token')}`,
+          },
+          body: JSON.stringify({ rsvp_ids: rsvpIds }),
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to review RSVPs');
+      }
+
+      const data = await response.json();
+      setNotification({ type: 'success', message: data.message });
+      fetchAttendees(id);
+      fetchRsvpCounts(id);
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while reviewing RSVPs',
+      });
+    }
+  }
+
   async function handleDeleteEvent() {
     setIsDeleting(true);
     try {
@@ -221,7 +462,7 @@ export default function EventDetails() {
       // Show success notification
       setNotification({
         type: 'success',
-        message: 'Event deleted successfully!',
+        message: 'Event moved to the trash. You can restore it from the Trash page.',
       });
 
       // Set event to null to show the "Event not found" view
@@ -236,6 +477,153 @@ export default function EventDetails() {
     }
   }
 
+  // Publish, unpublish or cancel the event
+  async function handleStatusChange(action, body = {}) {
+    setIsUpdatingStatus(true);
+    try {
+      const token = localStorage.getItem('token');
+      if (!token) {
+        throw new Error('You must be logged in to update an event');
+      }
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${event.id}/${action}`,
+        {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            Authorization: `Bearer ${token}`,
+          },
+          body: JSON.stringify(body),
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to update event status');
+      }
+
+      setNotification({
+        type: 'success',
+        message:
+          action === 'cancel'
+            ? 'Event cancelled. Attendees have been notified.'
+            : action === 'publish'
+            ? 'Event published successfully!'
+            : 'Event moved back to drafts.',
+      });
+
+      fetchEventDetails(event.id);
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message:
+          error.message || 'An error occurred while updating the event status',
+      });
+    } finally {
+      setIsUpdatingStatus(false);
+    }
+  }
+
+  // Upload a cover image or attachment, or remove one with file set to null
+  async function handleMedia(path, method, file = null) {
+    setIsUploading(true);
+    try {
+      const token = localStorage.getItem('token');
+      if (!token) {
+        throw new Error('You must be logged in to update an event');
+      }
+
+      let body;
+      if (file) {
+        body = new FormData();
+        body.append('file', file);
+      }
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${event.id}/${path}`,
+        {
+          method,
+          headers: {
+            Authorization: `Bearer ${token}`,
+          },
+          body,
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to update event media');
+      }
+
+      fetchEventDetails(event.id);
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while updating event media',
+      });
+    } finally {
+      setIsUploading(false);
+    }
+  }
+
+  function formatFileSize(bytes) {
+    if (bytes < 1024 * 1024) {
+      return `${Math.max(1, Math.round(bytes / 1024))} KB`;
+    }
+    return `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
+  }
+
+  // Copy the event to a new date as a draft and open the copy
+  async function handleDuplicateEvent() {
+    const input = window.prompt(
+      'Start date and time for the copy (YYYY-MM-DD HH:MM):'
+    );
+    if (!input) return;
+
+    const date = new Date(input.trim().replace(' ', 'T'));
+    if (isNaN(date.getTime())) {
+      setNotification({
+        type: 'error',
+        message: 'Please enter the date as YYYY-MM-DD HH:MM',
+      });
+      return;
+    }
+
+    try {
+      const token = localStorage.getItem('token');
+      if (!token) {
+        throw new Error('You must be logged in to duplicate an event');
+      }
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${event.id}/duplicate`,
+        {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            Authorization: `Bearer ${token}`,
+          },
+          body: JSON.stringify({ date: date.toISOString(), status: 'draft' }),
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to duplicate event');
+      }
+
+      const data = await response.json();
+      window.location.href = `/event/${data.id}`;
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message:
+          error.message || 'An error occurred while duplicating the event',
+      });
+    }
+  }
+
   // Format date for display
   function formatDate(dateString) {
     const options = {
@@ -300,8 +688,14 @@ export default function EventDetails() {
     );
   }
 
-  // Check if the current user is the event creator
+  // Check if the current user is the event creator and what their role allows
   const isEventCreator = currentUserId === event.user_id;
+  const isEventMember = Boolean(event.role);
+  const canEditEvent = event.role === 'owner' || event.role === 'co_organizer';
+  const canDeleteEvent = event.role === 'owner';
+  const canCheckIn = canEditEvent || event.role === 'check_in_staff';
+  const isUnpublished = event.status === 'draft' || event.status === 'scheduled';
+  const canCancelEvent = isUnpublished || event.status === 'published';
 
   if (isEditing) {
     return (
@@ -339,7 +733,7 @@ export default function EventDetails() {
       }
 
       const data = await response.json();
-      setAttendees(data || []);
+      setAttendees(data.items || []);
     } catch (error) {
       console.error('Error fetching attendees:', error);
       setNotification({
@@ -362,16 +756,75 @@ export default function EventDetails() {
       )}
 
       <div className="bg-white dark:bg-gray-800 shadow rounded-lg overflow-hidden">
+        {event.cover_image && (
+          <img
+            src={event.cover_image.thumbnails.medium}
+            alt=""
+            className="w-full h-64 object-cover"
+          />
+        )}
         <div className="p-6">
+          {canEditEvent && (
+            <div className="flex flex-wrap items-center gap-3 mb-4 text-sm">
+              <label className="cursor-pointer text-primary-600 hover:text-primary-700 dark:text-primary-400">
+                {event.cover_image ? 'Change cover image' : 'Add cover image'}
+                <input
+                  type="file"
+                  accept="image/jpeg,image/png,image/gif"
+                  className="hidden"
+                  disabled={isUploading}
+                  onChange={(e) => {
+                    if (e.target.files[0]) {
+                      handleMedia('cover', 'PUT', e.target.files[0]);
+                    }
+                    e.target.value = '';
+                  }}
+                />
+              </label>
+              {event.cover_image && (
+                <button
+                  onClick={() => handleMedia('cover', 'DELETE')}
+                  disabled={isUploading}
+                  className="text-red-600 hover:text-red-700 dark:text-red-400"
+                >
+                  Remove cover image
+                </button>
+              )}
+            </div>
+          )}
+
           <div className="flex justify-between items-start mb-4">
             <h1 className="text-3xl font-bold text-gray-900 dark:text-white">
               {event.title}
             </h1>
             <span className="inline-flex items-center px-3 py-1 rounded-full text-sm font-medium bg-primary-100 text-primary-800 dark:bg-primary-900 dark:text-primary-200">
-              {getDaysRemaining(event.date)}
+              {event.status === 'published'
+                ? getDaysRemaining(event.date)
+                : event.status.charAt(0).toUpperCase() + event.status.slice(1)}
             </span>
           </div>
 
+          {event.status === 'cancelled' && (
+            <div className="mb-4 p-4 rounded-md bg-red-50 text-red-800 dark:bg-red-900 dark:text-red-200">
+              This event has been cancelled.
+              {event.cancellation_reason && ` ${event.cancellation_reason}`}
+            </div>
+          )}
+
+          {event.status === 'scheduled' && event.publish_at && (
+            <div className="mb-4 p-4 rounded-md bg-yellow-50 text-yellow-800 dark:bg-yellow-900 dark:text-yellow-200">
+              Only organizers can see this event until it is published on{' '}
+              {formatDate(event.publish_at)}.
+            </div>
+          )}
+
+          {event.status === 'draft' && (
+            <div className="mb-4 p-4 rounded-md bg-yellow-50 text-yellow-800 dark:bg-yellow-900 dark:text-yellow-200">
+              This event is a draft. Only organizers can see it until it is
+              published.
+            </div>
+          )}
+
           <div className="flex items-center text-sm text-gray-500 dark:text-gray-400 mb-4">
             <svg
               className="h-5 w-5 mr-2"
@@ -410,6 +863,14 @@ export default function EventDetails() {
               />
             </svg>
             <span>{event.location}</span>
+            {event.venue && (
+              <span className="ml-2">
+                · {event.venue.name}
+                {event.venue.rooms
+                  .filter((room) => room.id === event.room_id)
+                  .map((room) => ` · ${room.name}`)}
+              </span>
+            )}
           </div>
 
           {isLoggedIn && event && (
@@ -422,11 +883,75 @@ export default function EventDetails() {
           )}
 
           {/* RSVP Section */}
-          {!isEventCreator && (
+          {!isEventCreator && event.status === 'published' && (
             <div className="mb-8 border-t border-b border-gray-200 dark:border-gray-700 py-4">
               <h3 className="text-lg font-semibold text-gray-900 dark:text-white mb-3">
                 Will you attend?
               </h3>
+              {event.rsvp_opens_at &&
+                new Date(event.rsvp_opens_at) > new Date() && (
+                  <p className="mb-3 text-sm text-gray-500 dark:text-gray-400">
+                    RSVPs open{' '}
+                    {new Date(event.rsvp_opens_at).toLocaleString()}
+                  </p>
+                )}
+              {event.rsvp_closes_at && (
+                <p className="mb-3 text-sm text-gray-500 dark:text-gray-400">
+                  {new Date(event.rsvp_closes_at) > new Date()
+                    ? 'RSVPs close'
+                    : 'RSVPs closed'}{' '}
+                  {new Date(event.rsvp_closes_at).toLocaleString()}
+                </p>
+              )}
+              {isLoggedIn && event.max_guests > 0 && (
+                <div className="mb-4 grid grid-cols-1 sm:grid-cols-3 gap-3">
+                  <div>
+                    <label
+                      htmlFor="guests"
+                      className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+                    >
+                      Guests (up to {event.max_guests})
+                    </label>
+                    <input
+                      type="number"
+                      id="guests"
+                      min="0"
+                      max={event.max_guests}
+                      value={guests}
+                      onChange={(e) =>
+                        setGuests(parseInt(e.target.value, 10) || 0)
+                      }
+                      className="mt-1 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+                    />
+                  </div>
+                  {guests > 0 && (
+                    <div className="sm:col-span-2">
+                      <label
+                        htmlFor="guest_names"
+                        className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+                      >
+                        Guest names (optional, comma separated)
+                      </label>
+                      <input
+                        type="text"
+                        id="guest_names"
+                        value={guestNames}
+                        onChange={(e) => setGuestNames(e.target.value)}
+                        className="mt-1 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+                      />
+                    </div>
+                  )}
+                </div>
+              )}
+              {isLoggedIn && questions.length > 0 && (
+                <div className="mb-4">
+                  <QuestionInputs
+                    questions={questions}
+                    answers={answers}
+                    onChange={setAnswers}
+                  />
+                </div>
+              )}
               <div className="flex flex-wrap gap-3">
                 <button
                   onClick={() => handleRsvp('going')}
@@ -462,6 +987,46 @@ export default function EventDetails() {
                   Not Going ({rsvpCounts.not_going})
                 </button>
               </div>
+              {rsvpCounts.guests > 0 && (
+                <p className="mt-2 text-sm text-gray-500 dark:text-gray-400">
+                  {rsvpCounts.headcount} people going, including guests
+                </p>
+              )}
+              {event.requires_approval && !rsvpStatus && (
+                <p className="mt-2 text-sm text-gray-500 dark:text-gray-400">
+                  The organizer approves each attendee.
+                </p>
+              )}
+              {rsvpStatus === 'pending' && (
+                <p className="mt-2 text-sm text-yellow-700 dark:text-yellow-300">
+                  Your request to attend is awaiting the organizer's approval.
+                </p>
+              )}
+              {rsvpStatus === 'waitlisted' && (
+                <p className="mt-2 text-sm text-yellow-700 dark:text-yellow-300">
+                  You are on the waitlist. We will email you if a spot opens up.
+                </p>
+              )}
+              {rsvpStatus === 'declined' && (
+                <p className="mt-2 text-sm text-red-600 dark:text-red-400">
+                  Your request to attend this event was declined.
+                </p>
+              )}
+              {rsvpStatus === 'going' && ticketUrl && (
+                <div className="mt-4">
+                  <h4 className="text-sm font-medium text-gray-900 dark:text-white mb-2">
+                    Your ticket
+                  </h4>
+                  <img
+                    src={ticketUrl}
+                    alt="Ticket QR code"
+                    className="w-40 h-40 border border-gray-200 dark:border-gray-700"
+                  />
+                  <p className="mt-1 text-sm text-gray-500 dark:text-gray-400">
+                    Show this code when you arrive to check in.
+                  </p>
+                </div>
+              )}
               {!isLoggedIn && (
                 <p className="mt-2 text-sm text-gray-500 dark:text-gray-400">
                   <a
@@ -485,6 +1050,115 @@ export default function EventDetails() {
             </p>
           </div>
 
+          {(canEditEvent || (event.attachments && event.attachments.length > 0)) && (
+            <div className="mb-8">
+              <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+                Attachments
+              </h2>
+              {event.attachments && event.attachments.length > 0 ? (
+                <ul className="divide-y divide-gray-200 dark:divide-gray-700">
+                  {event.attachments.map((attachment) => (
+                    <li
+                      key={attachment.id}
+                      className="py-2 flex items-center justify-between"
+                    >
+                      <a
+                        href={attachment.url}
+                        target="_blank"
+                        rel="noopener noreferrer"
+                        className="text-primary-600 hover:text-primary-700 dark:text-primary-400"
+                      >
+                        {attachment.file_name}
+                      </a>
+                      <div className="flex items-center gap-3 text-sm text-gray-500 dark:text-gray-400">
+                        <span>{formatFileSize(attachment.size_bytes)}</span>
+                        {canEditEvent && (
+                          <button
+                            onClick={() =>
+                              handleMedia(`attachments/${attachment.id}`, 'DELETE')
+                            }
+                            disabled={isUploading}
+                            className="text-red-600 hover:text-red-700 dark:text-red-400"
+                          >
+                            Remove
+                          </button>
+                        )}
+                      </div>
+                    </li>
+                  ))}
+                </ul>
+              ) : (
+                <p className="text-gray-600 dark:text-gray-400">
+                  No attachments yet.
+                </p>
+              )}
+              {canEditEvent && (
+                <label className="mt-3 inline-block cursor-pointer text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400">
+                  {isUploading ? 'Uploading...' : 'Add attachment'}
+                  <input
+                    type="file"
+                    accept=".pdf,.pptx,.docx,.xlsx,.txt,.jpg,.jpeg,.png"
+                    className="hidden"
+                    disabled={isUploading}
+                    onChange={(e) => {
+                      if (e.target.files[0]) {
+                        handleMedia('attachments', 'POST', e.target.files[0]);
+                      }
+                      e.target.value = '';
+                    }}
+                  />
+                </label>
+              )}
+            </div>
+          )}
+
+          {!event.recurrence_rule && (
+            <EventAgenda
+              eventId={event.id}
+              canEdit={canEditEvent}
+              isGoing={rsvpStatus === 'going'}
+              accessHeaders={accessHeaders}
+            />
+          )}
+
+          {canEditEvent && <RsvpOverrides eventId={event.id} />}
+
+          <EventAnnouncements
+            eventId={event.id}
+            canAnnounce={canEditEvent}
+            accessHeaders={accessHeaders}
+          />
+
+          {isLoggedIn && (
+            <EventSurvey
+              eventId={event.id}
+              canManage={canEditEvent}
+              accessHeaders={accessHeaders}
+            />
+          )}
+
+          <EventComments
+            eventId={event.id}
+            canModerate={canEditEvent}
+            accessHeaders={accessHeaders}
+          />
+
+          {canEditEvent && (
+            <EventQuestions
+              eventId={event.id}
+              questions={questions}
+              onChange={() => fetchQuestions(event.id)}
+            />
+          )}
+
+          {canEditEvent && (
+            <EventHistory
+              eventId={event.id}
+              canRestore={canDeleteEvent}
+              onRestore={() => fetchEventDetails(event.id)}
+            />
+          )}
+
           <div className="border-t border-gray-200 dark:border-gray-700 pt-6">
             <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
               Organizer
@@ -492,14 +1166,82 @@ export default function EventDetails() {
             <p className="text-gray-700 dark:text-gray-300">
               {event.organizer_first_name} {event.organizer_last_name}
             </p>
+            {organizer && organizer.average_rating !== null && (
+              <p className="text-sm text-gray-500 dark:text-gray-400">
+                ★ {organizer.average_rating.toFixed(1)} from{' '}
+                {organizer.rating_count} attendee ratings
+              </p>
+            )}
           </div>
 
-          {isEventCreator && (
+          {isEventMember && (
             <>
+              {canCheckIn && (
+                <div className="border-t border-gray-200 dark:border-gray-700 mt-6 pt-6">
+                  <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+                    Check-in
+                  </h2>
+                  <p className="text-sm text-gray-500 dark:text-gray-400 mb-4">
+                    {rsvpCounts.checked_in || 0} of {rsvpCounts.going} attendees
+                    checked in
+                    {rsvpCounts.guests > 0 &&
+                      ` (${rsvpCounts.guests} guests expected)`}
+                  </p>
+                  <form onSubmit={handleCheckIn} className="flex gap-3">
+                    <input
+                      type="text"
+                      value={checkInCode}
+                      onChange={(e) => setCheckInCode(e.target.value)}
+                      placeholder="Scan or enter a ticket code"
+                      className="flex-1 px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+                    />
+                    <button
+                      type="submit"
+                      disabled={isCheckingIn}
+                      className="px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700 disabled:opacity-50"
+                    >
+                      {isCheckingIn ? 'Checking in...' : 'Check in'}
+                    </button>
+                  </form>
+                  {lastCheckIn && (
+                    <p className="mt-2 text-sm text-green-700 dark:text-green-300">
+                      {lastCheckIn.first_name} {lastCheckIn.last_name} checked in.
+                    </p>
+                  )}
+                </div>
+              )}
+
               <div className="border-t border-gray-200 dark:border-gray-700 mt-6 pt-6">
-                <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-4">
-                  Attendees
-                </h2>
+                <div className="flex justify-between items-center mb-4">
+                  <h2 className="text-xl font-semibold text-gray-900 dark:text-white">
+                    Attendees
+                  </h2>
+                  <div className="flex gap-4">
+                    {canEditEvent && rsvpCounts.pending > 0 && (
+                      <button
+                        onClick={() =>
+                          handleReviewRsvps(
+                            'approve',
+                            attendees
+                              .filter((a) => a.status === 'pending')
+                              .map((a) => a.id)
+                          )
+                        }
+                        className="text-sm text-green-600 hover:text-green-700 dark:text-green-400"
+                      >
+                        Approve all pending ({rsvpCounts.pending})
+                      </button>
+                    )}
+                    {attendees.length > 0 && (
+                      <button
+                        onClick={handleExportRsvps}
+                        className="text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+                      >
+                        Export CSV
+                      </button>
+                    )}
+                  </div>
+                </div>
 
                 {isAttendeesLoading ? (
                   <div className="flex justify-center items-center h-20">
@@ -524,6 +1266,14 @@ export default function EventDetails() {
                       >
                         <div className="text-gray-900 dark:text-white">
                           {attendee.first_name} {attendee.last_name}
+                          {attendee.guests > 0 && (
+                            <span
+                              className="ml-1 text-gray-500 dark:text-gray-400"
+                              title={(attendee.guest_names || []).join(', ')}
+                            >
+                              +{attendee.guests}
+                            </span>
+                          )}
                         </div>
                         <div>
                           <span
@@ -531,17 +1281,38 @@ export default function EventDetails() {
                             ${
                               attendee.status === 'going'
                                 ? 'bg-green-100 text-green-800 dark:bg-green-900 dark:text-green-200'
-                                : attendee.status === 'maybe'
+                                : ['maybe', 'pending', 'waitlisted'].includes(attendee.status)
                                 ? 'bg-yellow-100 text-yellow-800 dark:bg-yellow-900 dark:text-yellow-200'
                                 : 'bg-red-100 text-red-800 dark:bg-red-900 dark:text-red-200'
                             }`}
                           >
-                            {attendee.status === 'going'
-                              ? 'Going'
-                              : attendee.status === 'maybe'
-                              ? 'Maybe'
-                              : 'Not Going'}
+                            {rsvpStatusLabels[attendee.status] || attendee.status}
                           </span>
+                          {attendee.checked_in_at && (
+                            <span className="ml-2 text-xs text-gray-500 dark:text-gray-400">
+                              Checked in
+                            </span>
+                          )}
+                          {canEditEvent && attendee.status === 'pending' && (
+                            <span className="ml-2 text-xs">
+                              <button
+                                onClick={() =>
+                                  handleReviewRsvps(`${attendee.id}/approve`)
+                                }
+                                className="text-green-600 hover:text-green-700 dark:text-green-400"
+                              >
+                                Approve
+                              </button>
+                              <button
+                                onClick={() =>
+                                  handleReviewRsvps(`${attendee.id}/decline`)
+                                }
+                                className="ml-2 text-red-600 hover:text-red-700 dark:text-red-400"
+                              >
+                                Decline
+                              </button>
+                            </span>
+                          )}
                         </div>
                         <div className="text-gray-500 dark:text-gray-400">
                           {new Date(attendee.updated_at).toLocaleDateString()}
@@ -552,31 +1323,70 @@ export default function EventDetails() {
                 )}
               </div>
 
-              <div className="border-t border-gray-200 dark:border-gray-700 mt-6 pt-6">
-                <div className="flex flex-col sm:flex-row sm:justify-end gap-3">
-                  <button
-                    onClick={() => setIsEditing(true)}
-                    className="px-4 py-2 border border-gray-300 rounded-md shadow-sm text-sm font-medium text-gray-700 bg-white hover:bg-gray-50 dark:bg-gray-700 dark:text-gray-300 dark:border-gray-600 dark:hover:bg-gray-600"
-                  >
-                    Edit Event
-                  </button>
-                  <button
-                    onClick={() => {
-                      if (
-                        window.confirm(
-                          'Are you sure you want to delete this event? This action cannot be undone.'
-                        )
-                      ) {
-                        handleDeleteEvent();
-                      }
-                    }}
-                    disabled={isDeleting}
-                    className="px-4 py-2 border border-transparent rounded-md shadow-sm text-sm font-medium text-white bg-red-600 hover:bg-red-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-red-500 disabled:opacity-50"
-                  >
-                    {isDeleting ? 'Deleting...' : 'Delete Event'}
-                  </button>
+              {canEditEvent && (
+                <div className="border-t border-gray-200 dark:border-gray-700 mt-6 pt-6">
+                  <div className="flex flex-col sm:flex-row sm:justify-end gap-3">
+                    {isUnpublished && (
+                      <button
+                        onClick={() => handleStatusChange('publish')}
+                        disabled={isUpdatingStatus}
+                        className="px-4 py-2 border border-transparent rounded-md shadow-sm text-sm font-medium text-white bg-primary-600 hover:bg-primary-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500 disabled:opacity-50"
+                      >
+                        Publish Now
+                      </button>
+                    )}
+                    {event.status !== 'cancelled' && (
+                      <button
+                        onClick={() => setIsEditing(true)}
+                        className="px-4 py-2 border border-gray-300 rounded-md shadow-sm text-sm font-medium text-gray-700 bg-white hover:bg-gray-50 dark:bg-gray-700 dark:text-gray-300 dark:border-gray-600 dark:hover:bg-gray-600"
+                      >
+                        Edit Event
+                      </button>
+                    )}
+                    {canDeleteEvent && (
+                      <button
+                        onClick={handleDuplicateEvent}
+                        className="px-4 py-2 border border-gray-300 rounded-md shadow-sm text-sm font-medium text-gray-700 bg-white hover:bg-gray-50 dark:bg-gray-700 dark:text-gray-300 dark:border-gray-600 dark:hover:bg-gray-600"
+                      >
+                        Duplicate
+                      </button>
+                    )}
+                    {canCancelEvent && (
+                      <button
+                        onClick={() => {
+                          const reason = window.prompt(
+                            'Cancel this event? Attendees will be notified. You can add a message for them:'
+                          );
+                          if (reason !== null) {
+                            handleStatusChange('cancel', { reason });
+                          }
+                        }}
+                        disabled={isUpdatingStatus}
+                        className="px-4 py-2 border border-red-300 rounded-md shadow-sm text-sm font-medium text-red-700 bg-white hover:bg-red-50 dark:bg-gray-700 dark:text-red-300 dark:border-red-600 dark:hover:bg-gray-600 disabled:opacity-50"
+                      >
+                        Cancel Event
+                      </button>
+                    )}
+                    {canDeleteEvent && (
+                      <button
+                        onClick={() => {
+                          if (
+                            window.confirm(
+                              'Move this event to the trash? You can restore it from the trash until it is purged.'
+                            )
+                          ) {
+                            handleDeleteEvent();
+                          }
+                        }}
+                        disabled={isDeleting}
+                        className="px-4 py-2 border border-transparent rounded-md shadow-sm text-sm font-medium text-white bg-red-600 hover:bg-red-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-red-500 disabled:opacity-50"
+                      >
+                        {isDeleting ? 'Deleting...' : 'Delete Event'}
+                      </button>
+                    )}
+                  </div>
                 </div>
-              </div>
+              )}
             </>
           )}
         </div>
@@ -1,5 +1,6 @@
 import { useState } from 'react';
 import Notification from './Notification';
+import VenuePicker, { describeConflicts } from './VenuePicker';
 import config from '../config';
 
 export default function EventForm() {
@@ -20,6 +21,15 @@ export default function EventForm() {
     const date = formData.get('date');
     const time = formData.get('time');
     const location = formData.get('location');
+    const duration = formData.get('duration');
+    const maxGuests = formData.get('max_guests');
+    const rsvpOpensAt = formData.get('rsvp_opens_at');
+    const rsvpClosesAt = formData.get('rsvp_closes_at');
+    const venueId = formData.get('venue_id');
+    const roomId = formData.get('room_id');
+    // Drafts stay hidden from everyone but the organizers until published
+    const status =
+      e.nativeEvent.submitter?.value === 'draft' ? 'draft' : 'published';
 
     // Combine date and time
     const dateTime = new Date(`${date}T${time}`);
@@ -40,20 +50,38 @@ export default function EventForm() {
           title,
           description,
           date: dateTime.toISOString(),
+          timezone: Intl.DateTimeFormat().resolvedOptions().timeZone,
           location,
+          duration_minutes: duration ? parseInt(duration, 10) : undefined,
+          max_guests: maxGuests ? parseInt(maxGuests, 10) : undefined,
+          rsvp_opens_at: rsvpOpensAt ? new Date(rsvpOpensAt).toISOString() : undefined,
+          rsvp_closes_at: rsvpClosesAt ? new Date(rsvpClosesAt).toISOString() : undefined,
+          requires_approval: formData.get('requires_approval') === 'on',
+          venue_id: venueId ? parseInt(venueId, 10) : undefined,
+          room_id: roomId ? parseInt(roomId, 10) : undefined,
+          allow_conflicts: formData.get('allow_conflicts') === 'on',
+          status,
         }),
       });
 
-      const data = await response.json();
+      const text = await response.text();
+      const data = text.startsWith('{') ? JSON.parse(text) : { message: text };
 
       if (!response.ok) {
+        // The venue is already booked at this time
+        if (data.conflicts) {
+          throw new Error(describeConflicts(data));
+        }
         throw new Error(data.message || 'Failed to create event');
       }
 
       // Show success notification
       setNotification({
         type: 'success',
-        message: 'Event created successfully!',
+        message:
+          status === 'draft'
+            ? 'Draft saved. Publish it from the event page when you are ready.'
+            : 'Event created successfully!',
       });
 
       // Reset form
@@ -123,18 +151,19 @@ export default function EventForm() {
                 htmlFor="location"
                 className="block text-sm font-medium text-gray-700 dark:text-gray-300"
               >
-                Location *
+                Location
               </label>
               <input
                 type="text"
                 id="location"
                 name="location"
-                required
                 className="mt-1 block w-full px-3 py-2 border border-gray-300 dark:border-gray-600 rounded-md shadow-sm placeholder-gray-400 dark:placeholder-gray-500 dark:bg-gray-700 dark:text-white focus:outline-none focus:ring-primary-500 focus:border-primary-500 sm:text-sm"
-                placeholder="Enter event location"
+                placeholder="Enter event location, or leave empty to use the venue's address"
               />
             </div>
 
+            <VenuePicker />
+
             <div className="grid grid-cols-2 gap-4">
               <div>
                 <label
@@ -169,6 +198,80 @@ export default function EventForm() {
               </div>
             </div>
 
+            <div>
+              <label
+                htmlFor="duration"
+                className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+              >
+                Duration (minutes)
+              </label>
+              <input
+                type="number"
+                id="duration"
+                name="duration"
+                min="1"
+                className="mt-1 block w-full px-3 py-2 border border-gray-300 dark:border-gray-600 rounded-md shadow-sm placeholder-gray-400 dark:placeholder-gray-500 dark:bg-gray-700 dark:text-white focus:outline-none focus:ring-primary-500 focus:border-primary-500 sm:text-sm"
+                placeholder="Required when booking a venue"
+              />
+            </div>
+
+            <div>
+              <label
+                htmlFor="max_guests"
+                className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+              >
+                Guests per RSVP
+              </label>
+              <input
+                type="number"
+                id="max_guests"
+                name="max_guests"
+                min="0"
+                max="20"
+                className="mt-1 block w-full px-3 py-2 border border-gray-300 dark:border-gray-600 rounded-md shadow-sm placeholder-gray-400 dark:placeholder-gray-500 dark:bg-gray-700 dark:text-white focus:outline-none focus:ring-primary-500 focus:border-primary-500 sm:text-sm"
+                placeholder="How many people each attendee may bring"
+              />
+            </div>
+
+            <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
+              <div>
+                <label
+                  htmlFor="rsvp_opens_at"
+                  className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+                >
+                  RSVPs open
+                </label>
+                <input
+                  type="datetime-local"
+                  id="rsvp_opens_at"
+                  name="rsvp_opens_at"
+                  className="mt-1 block w-full px-3 py-2 border border-gray-300 dark:border-gray-600 rounded-md shadow-sm placeholder-gray-400 dark:placeholder-gray-500 dark:bg-gray-700 dark:text-white focus:outline-none focus:ring-primary-500 focus:border-primary-500 sm:text-sm"
+                />
+              </div>
+              <div>
+                <label
+                  htmlFor="rsvp_closes_at"
+                  className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+                >
+                  RSVPs close
+                </label>
+                <input
+                  type="datetime-local"
+                  id="rsvp_closes_at"
+                  name="rsvp_closes_at"
+                  className="mt-1 block w-full px-3 py-2 border border-gray-300 dark:border-gray-600 rounded-md shadow-sm placeholder-gray-400 dark:placeholder-gray-500 dark:bg-gray-700 dark:text-white focus:outline-none focus:ring-primary-500 focus:border-primary-500 sm:text-sm"
+                />
+                <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+                  Defaults to when the event starts. For repeating events, set the window of the first occurrence
+                </p>
+              </div>
+            </div>
+
+            <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300">
+              <input type="checkbox" name="requires_approval" />
+              Approve each attendee before they can go
+            </label>
+
             <div>
               <button
                 type="submit"
@@ -177,6 +280,14 @@ export default function EventForm() {
               >
                 {isLoading ? 'Creating...' : 'Create Event'}
               </button>
+              <button
+                type="submit"
+                value="draft"
+                disabled={isLoading}
+                className="mt-3 w-full flex justify-center py-2 px-4 border border-gray-300 rounded-md shadow-sm text-sm font-medium text-gray-700 bg-white hover:bg-gray-50 dark:bg-gray-700 dark:text-gray-300 dark:border-gray-600 dark:hover:bg-gray-600 disabled:opacity-50 disabled:cursor-not-allowed"
+              >
+                Save as Draft
+              </button>
             </div>
           </form>
         </div>
@@ -0,0 +1,186 @@
+import { useState } from 'react';
+import Notification from './Notification';
+import config from '../config';
+
+const fieldLabels = {
+  title: 'Title',
+  description: 'Description',
+  date: 'Start',
+  end_date: 'End',
+  timezone: 'Time zone',
+  location: 'Location',
+  latitude: 'Latitude',
+  longitude: 'Longitude',
+  recurrence_rule: 'Repeats',
+  capacity: 'Capacity',
+  max_guests: 'Guests per attendee',
+  rsvp_opens_at: 'RSVPs open',
+  rsvp_closes_at: 'RSVPs close',
+  requires_approval: 'Requires approval',
+  visibility: 'Visibility',
+  venue_id: 'Venue',
+  room_id: 'Room',
+  tags: 'Tags',
+};
+
+const dateFields = ['date', 'end_date', 'rsvp_opens_at', 'rsvp_closes_at'];
+
+// formatValue shows a changed field's value in a readable form
+function formatValue(field, value) {
+  if (value === null || value === '' || (Array.isArray(value) && value.length === 0)) {
+    return 'none';
+  }
+  if (dateFields.includes(field)) return new Date(value).toLocaleString();
+  if (Array.isArray(value)) return value.join(', ');
+  if (typeof value === 'boolean') return value ? 'yes' : 'no';
+  return String(value);
+}
+
+// EventHistory lists the changes made to an event for its organizers and lets
+// the owner restore an earlier revision
+export default function EventHistory({ eventId, canRestore, onRestore }) {
+  const [revisions, setRevisions] = useState([]);
+  const [nextCursor, setNextCursor] = useState('');
+  const [isOpen, setIsOpen] = useState(false);
+  const [notification, setNotification] = useState(null);
+
+  async function fetchRevisions(cursor = '') {
+    try {
+      const params = new URLSearchParams();
+      if (cursor) params.set('cursor', cursor);
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/revisions?${params}`,
+        {
+          headers: { Authorization: `Bearer ${localStorage.getItem('token')}` },
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch the event history');
+      }
+
+      const data = await response.json();
+      setRevisions(cursor ? [...revisions, ...data.items] : data.items);
+      setNextCursor(data.next_cursor || '');
+    } catch (error) {
+      console.error('Error fetching event history:', error);
+    }
+  }
+
+  function handleToggle() {
+    if (!isOpen) fetchRevisions();
+    setIsOpen(!isOpen);
+  }
+
+  async function handleRestore(number) {
+    if (!window.confirm(`Restore the event to revision ${number}?`)) return;
+
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/revisions/${number}/restore`,
+        {
+          method: 'POST',
+          headers: { Authorization: `Bearer ${localStorage.getItem('token')}` },
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to restore the revision');
+      }
+
+      setNotification({
+        type: 'success',
+        message: `Event restored to revision ${number}`,
+      });
+      fetchRevisions();
+      onRestore();
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while restoring the revision',
+      });
+    }
+  }
+
+  return (
+    <div className="mb-8">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      <div className="flex justify-between items-center mb-2">
+        <h2 className="text-xl font-semibold text-gray-900 dark:text-white">
+          History
+        </h2>
+        <button
+          onClick={handleToggle}
+          className="text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+        >
+          {isOpen ? 'Hide changes' : 'Show changes'}
+        </button>
+      </div>
+
+      {isOpen && (
+        <ul className="divide-y divide-gray-200 dark:divide-gray-700">
+          {revisions.map((revision, i) => (
+            <li key={revision.id} className="py-3 text-sm">
+              <div className="flex justify-between">
+                <p className="text-gray-500 dark:text-gray-400">
+                  Revision {revision.number} ·{' '}
+                  {new Date(revision.created_at).toLocaleString()}
+                  {revision.first_name &&
+                    ` · ${revision.first_name} ${revision.last_name}`}
+                  {revision.restored_from &&
+                    ` · Restored revision ${revision.restored_from}`}
+                </p>
+                {canRestore && i > 0 && (
+                  <button
+                    onClick={() => handleRestore(revision.number)}
+                    className="text-primary-600 hover:text-primary-700 dark:text-primary-400"
+                  >
+                    Restore
+                  </button>
+                )}
+              </div>
+              {revision.changes.length === 0 ? (
+                <p className="text-gray-700 dark:text-gray-300">
+                  First recorded version of the event
+                </p>
+              ) : (
+                <ul className="mt-1 text-gray-700 dark:text-gray-300">
+                  {revision.changes.map((change) => (
+                    <li key={change.field}>
+                      <span className="font-medium">
+                        {fieldLabels[change.field] || change.field}:
+                      </span>{' '}
+                      <span className="line-through">
+                        {formatValue(change.field, change.from)}
+                      </span>{' '}
+                      → {formatValue(change.field, change.to)}
+                    </li>
+                  ))}
+                </ul>
+              )}
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {isOpen && nextCursor && (
+        <button
+          onClick={() => fetchRevisions(nextCursor)}
+          className="mt-3 text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+        >
+          Show older changes
+        </button>
+      )}
+    </div>
+  );
+}
@@ -32,7 +32,7 @@ export default function EventList() {
       }
 
       const data = await response.json();
-      setEvents(data || []);
+      setEvents(data.items || []);
     } catch (error) {
       setNotification({
         type: 'error',
@@ -94,9 +94,21 @@ export default function EventList() {
               key={event.id}
               className="bg-white dark:bg-gray-800 shadow rounded-lg overflow-hidden"
             >
+              {event.cover_image && (
+                <img
+                  src={event.cover_image.thumbnails.small}
+                  alt=""
+                  className="w-full h-40 object-cover"
+                />
+              )}
               <div className="p-5">
                 <h3 className="text-lg font-semibold text-gray-900 dark:text-white mb-2">
                   {event.title}
+                  {event.status && event.status !== 'published' && (
+                    <span className="ml-2 inline-flex items-center px-2 py-0.5 rounded-full text-xs font-medium bg-gray-100 text-gray-800 dark:bg-gray-700 dark:text-gray-200">
+                      {event.status}
+                    </span>
+                  )}
                 </h3>
                 <p className="text-sm text-gray-600 dark:text-gray-400 mb-4 line-clamp-2">
                   {event.description}
@@ -0,0 +1,303 @@
+import { useState } from 'react';
+import Notification from './Notification';
+import config from '../config';
+
+const emptyQuestion = {
+  label: '',
+  type: 'text',
+  options: '',
+  required: false,
+};
+
+const inputClass =
+  'px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white';
+
+// QuestionInputs renders the registration questions for an attendee to answer.
+// answers maps each question ID to { text, choices }.
+export function QuestionInputs({ questions, answers, onChange }) {
+  function setAnswer(questionId, answer) {
+    onChange({ ...answers, [questionId]: answer });
+  }
+
+  return (
+    <div className="mt-4 space-y-3">
+      {questions.map((question) => {
+        const answer = answers[question.id] || { text: '', choices: [] };
+        return (
+          <div key={question.id}>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+              {question.label}
+              {question.required && <span className="text-red-600"> *</span>}
+            </label>
+            {question.type === 'text' && (
+              <input
+                type="text"
+                maxLength={1000}
+                value={answer.text}
+                onChange={(e) =>
+                  setAnswer(question.id, { text: e.target.value, choices: [] })
+                }
+                className={`mt-1 block w-full ${inputClass}`}
+              />
+            )}
+            {question.type === 'single_choice' && (
+              <select
+                value={answer.choices[0] || ''}
+                onChange={(e) =>
+                  setAnswer(question.id, {
+                    text: '',
+                    choices: e.target.value ? [e.target.value] : [],
+                  })
+                }
+                className={`mt-1 block w-full ${inputClass}`}
+              >
+                <option value="">Choose an option</option>
+                {question.options.map((option) => (
+                  <option key={option} value={option}>
+                    {option}
+                  </option>
+                ))}
+              </select>
+            )}
+            {question.type === 'multiple_choice' && (
+              <div className="mt-1 flex flex-wrap gap-3">
+                {question.options.map((option) => (
+                  <label
+                    key={option}
+                    className="flex items-center gap-1 text-sm text-gray-700 dark:text-gray-300"
+                  >
+                    <input
+                      type="checkbox"
+                      checked={answer.choices.includes(option)}
+                      onChange={(e) =>
+                        setAnswer(question.id, {
+                          text: '',
+                          choices: e.target.checked
+                            ? [...answer.choices, option]
+                            : answer.choices.filter((c) => c !== option),
+                        })
+                      }
+                    />
+                    {option}
+                  </label>
+                ))}
+              </div>
+            )}
+          </div>
+        );
+      })}
+    </div>
+  );
+}
+
+// EventQuestions lets organizers manage the questions attendees answer when they
+// RSVP, or in the post-event survey when resource is 'survey/questions'
+export default function EventQuestions({
+  eventId,
+  questions,
+  onChange,
+  resource = 'questions',
+  title = 'Registration Questions',
+  emptyText = 'Attendees are not asked anything when they RSVP.',
+}) {
+  const [newQuestion, setNewQuestion] = useState(emptyQuestion);
+  const [isAdding, setIsAdding] = useState(false);
+  const [notification, setNotification] = useState(null);
+
+  async function request(path, method, body) {
+    try {
+      const headers = { Authorization: `Bearer ${localStorage.getItem('token')}` };
+      if (body) headers['Content-Type'] = 'application/json';
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/${resource}${path}`,
+        {
+          method,
+          headers,
+          body: body ? JSON.stringify(body) : undefined,
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to update questions');
+      }
+
+      onChange();
+      return true;
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message:
+          error.message || 'An error occurred while updating the questions',
+      });
+      return false;
+    }
+  }
+
+  async function handleAddQuestion(e) {
+    e.preventDefault();
+    const added = await request('', 'POST', {
+      label: newQuestion.label,
+      type: newQuestion.type,
+      required: newQuestion.required,
+      options:
+        newQuestion.type === 'text'
+          ? []
+          : newQuestion.options.split('\n').filter((o) => o.trim()),
+    });
+    if (added) {
+      setNewQuestion(emptyQuestion);
+      setIsAdding(false);
+    }
+  }
+
+  const typeLabels = {
+    text: 'Text',
+    single_choice: 'Single choice',
+    multiple_choice: 'Multiple choice',
+  };
+
+  return (
+    <div className="mb-8">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+        {title}
+      </h2>
+
+      {questions.length === 0 ? (
+        <p className="text-gray-600 dark:text-gray-400">{emptyText}</p>
+      ) : (
+        <ul className="divide-y divide-gray-200 dark:divide-gray-700">
+          {questions.map((question) => (
+            <li key={question.id} className="py-2 flex justify-between gap-4">
+              <div>
+                <p className="font-medium text-gray-900 dark:text-white">
+                  {question.label}
+                  {question.required && (
+                    <span className="text-red-600"> *</span>
+                  )}
+                </p>
+                <p className="text-sm text-gray-500 dark:text-gray-400">
+                  {typeLabels[question.type]}
+                  {question.options.length > 0 &&
+                    `: ${question.options.join(', ')}`}
+                </p>
+              </div>
+              <div className="flex items-center gap-3 text-sm">
+                <button
+                  onClick={() =>
+                    request(`/${question.id}`, 'PUT', {
+                      ...question,
+                      required: !question.required,
+                    })
+                  }
+                  className="text-primary-600 hover:text-primary-700 dark:text-primary-400"
+                >
+                  {question.required ? 'Make optional' : 'Make required'}
+                </button>
+                <button
+                  onClick={() => {
+                    if (
+                      window.confirm(
+                        'Remove this question? Answers already given will be deleted.'
+                      )
+                    ) {
+                      request(`/${question.id}`, 'DELETE');
+                    }
+                  }}
+                  className="text-red-600 hover:text-red-700 dark:text-red-400"
+                >
+                  Remove
+                </button>
+              </div>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {isAdding ? (
+        <form
+          onSubmit={handleAddQuestion}
+          className="mt-4 grid grid-cols-1 sm:grid-cols-2 gap-3"
+        >
+          <input
+            type="text"
+            required
+            maxLength={255}
+            placeholder="Question"
+            value={newQuestion.label}
+            onChange={(e) =>
+              setNewQuestion({ ...newQuestion, label: e.target.value })
+            }
+            className={inputClass}
+          />
+          <select
+            value={newQuestion.type}
+            onChange={(e) =>
+              setNewQuestion({ ...newQuestion, type: e.target.value })
+            }
+            className={inputClass}
+          >
+            {Object.entries(typeLabels).map(([type, label]) => (
+              <option key={type} value={type}>
+                {label}
+              </option>
+            ))}
+          </select>
+          {newQuestion.type !== 'text' && (
+            <textarea
+              required
+              rows={3}
+              placeholder="Options, one per line"
+              value={newQuestion.options}
+              onChange={(e) =>
+                setNewQuestion({ ...newQuestion, options: e.target.value })
+              }
+              className={`sm:col-span-2 ${inputClass}`}
+            />
+          )}
+          <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300">
+            <input
+              type="checkbox"
+              checked={newQuestion.required}
+              onChange={(e) =>
+                setNewQuestion({ ...newQuestion, required: e.target.checked })
+              }
+            />
+            Required
+          </label>
+          <div className="flex gap-3">
+            <button
+              type="submit"
+              className="px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700"
+            >
+              Add question
+            </button>
+            <button
+              type="button"
+              onClick={() => setIsAdding(false)}
+              className="px-4 py-2 border border-gray-300 text-sm font-medium rounded-md text-gray-700 bg-white hover:bg-gray-50 dark:bg-gray-700 dark:text-gray-300 dark:border-gray-600"
+            >
+              Cancel
+            </button>
+          </div>
+        </form>
+      ) : (
+        <button
+          onClick={() => setIsAdding(true)}
+          className="mt-3 text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+        >
+          Add question
+        </button>
+      )}
+    </div>
+  );
+}
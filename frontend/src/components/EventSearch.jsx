@@ -41,7 +41,7 @@ export default function EventSearch() {
         }
 
         const data = await response.json();
-        setEvents(data || []);
+        setEvents(data.items || []);
       } catch (error) {
         setNotification({
           type: 'error',
@@ -205,13 +205,28 @@ export default function EventSearch() {
               key={event.id}
               className="bg-white dark:bg-gray-800 shadow rounded-lg overflow-hidden"
             >
+              {event.cover_image && (
+                <img
+                  src={event.cover_image.thumbnails.small}
+                  alt=""
+                  className="w-full h-40 object-cover"
+                />
+              )}
               <div className="p-5">
                 <h3 className="text-lg font-semibold text-gray-900 dark:text-white mb-2">
                   {event.title}
                 </h3>
-                <p className="text-sm text-gray-600 dark:text-gray-400 mb-4 line-clamp-2">
-                  {event.description}
-                </p>
+                {event.snippet ? (
+                  // Snippets are HTML-escaped by the API apart from the <mark> highlights
+                  <p
+                    className="text-sm text-gray-600 dark:text-gray-400 mb-4 line-clamp-2"
+                    dangerouslySetInnerHTML={{ __html: event.snippet }}
+                  />
+                ) : (
+                  <p className="text-sm text-gray-600 dark:text-gray-400 mb-4 line-clamp-2">
+                    {event.description}
+                  </p>
+                )}
 
                 <div className="flex items-center text-sm text-gray-500 dark:text-gray-400 mb-2">
                   <svg
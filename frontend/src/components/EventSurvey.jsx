@@ -0,0 +1,240 @@
+import { useState, useEffect } from 'react';
+import Notification from './Notification';
+import EventQuestions, { QuestionInputs } from './EventQuestions';
+import config from '../config';
+
+// EventSurvey shows the post-event survey: attendees who went rate the event and
+// answer the organizer's questions, and organizers manage the questions and see
+// the results
+export default function EventSurvey({ eventId, canManage, accessHeaders }) {
+  const [survey, setSurvey] = useState(null);
+  const [results, setResults] = useState(null);
+  const [rating, setRating] = useState(0);
+  const [comment, setComment] = useState('');
+  const [answers, setAnswers] = useState({});
+  const [isSaving, setIsSaving] = useState(false);
+  const [notification, setNotification] = useState(null);
+
+  useEffect(() => {
+    fetchSurvey();
+    if (canManage) fetchResults();
+  }, [eventId, canManage]);
+
+  async function fetchSurvey() {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/survey`,
+        { headers: accessHeaders() }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch survey');
+      }
+
+      const data = await response.json();
+      setSurvey(data);
+      if (data.response) {
+        setRating(data.response.rating);
+        setComment(data.response.comment);
+        const given = {};
+        data.response.answers.forEach((answer) => {
+          given[answer.question_id] = {
+            text: answer.text || '',
+            choices: answer.choices || [],
+          };
+        });
+        setAnswers(given);
+      }
+    } catch (error) {
+      console.error('Error fetching survey:', error);
+    }
+  }
+
+  async function fetchResults() {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/survey/results`,
+        { headers: accessHeaders() }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch survey results');
+      }
+
+      setResults(await response.json());
+    } catch (error) {
+      console.error('Error fetching survey results:', error);
+    }
+  }
+
+  async function handleSubmit(e) {
+    e.preventDefault();
+    setIsSaving(true);
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${eventId}/survey`,
+        {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            ...accessHeaders(),
+          },
+          body: JSON.stringify({
+            rating,
+            comment,
+            answers: survey.questions.map((question) => ({
+              question_id: question.id,
+              ...(answers[question.id] || { text: '', choices: [] }),
+            })),
+          }),
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to send feedback');
+      }
+
+      setNotification({ type: 'success', message: 'Thank you for your feedback!' });
+      fetchSurvey();
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while sending feedback',
+      });
+    } finally {
+      setIsSaving(false);
+    }
+  }
+
+  if (!survey || (!survey.can_respond && !canManage)) {
+    return null;
+  }
+
+  return (
+    <div className="mb-8">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      {survey.can_respond && (
+        <form onSubmit={handleSubmit}>
+          <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+            How was the event?
+          </h2>
+          <div className="flex gap-2">
+            {[1, 2, 3, 4, 5].map((value) => (
+              <button
+                key={value}
+                type="button"
+                onClick={() => setRating(value)}
+                aria-label={`${value} out of 5`}
+                className={`text-2xl ${
+                  value <= rating ? 'text-yellow-500' : 'text-gray-300 dark:text-gray-600'
+                }`}
+              >
+                ★
+              </button>
+            ))}
+          </div>
+          <textarea
+            rows={3}
+            maxLength={2000}
+            placeholder="What did you think? (optional)"
+            value={comment}
+            onChange={(e) => setComment(e.target.value)}
+            className="mt-3 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+          />
+          {survey.questions.length > 0 && (
+            <QuestionInputs
+              questions={survey.questions}
+              answers={answers}
+              onChange={setAnswers}
+            />
+          )}
+          <button
+            type="submit"
+            disabled={isSaving || rating === 0}
+            className="mt-3 px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700 disabled:opacity-50"
+          >
+            {survey.response ? 'Update feedback' : 'Send feedback'}
+          </button>
+        </form>
+      )}
+
+      {canManage && (
+        <>
+          <EventQuestions
+            eventId={eventId}
+            questions={survey.questions}
+            onChange={fetchSurvey}
+            resource="survey/questions"
+            title="Survey Questions"
+            emptyText="Attendees are only asked for a rating and comments after the event."
+          />
+
+          {results && (
+            <div>
+              <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+                Feedback
+              </h2>
+              {results.response_count === 0 ? (
+                <p className="text-gray-600 dark:text-gray-400">
+                  No one has given feedback yet. The survey is emailed to
+                  attendees once the event has ended.
+                </p>
+              ) : (
+                <div className="space-y-4 text-sm text-gray-700 dark:text-gray-300">
+                  <p>
+                    Rated {results.average_rating.toFixed(1)} out of 5 by{' '}
+                    {results.response_count} attendees
+                  </p>
+                  <ul>
+                    {[5, 4, 3, 2, 1].map((value) => (
+                      <li key={value}>
+                        {value} ★ · {results.ratings[value]}
+                      </li>
+                    ))}
+                  </ul>
+                  {results.questions.map((question) => (
+                    <div key={question.question.id}>
+                      <p className="font-medium text-gray-900 dark:text-white">
+                        {question.question.label} ({question.answer_count} answers)
+                      </p>
+                      {question.choices &&
+                        Object.entries(question.choices).map(([option, count]) => (
+                          <p key={option}>
+                            {option} · {count}
+                          </p>
+                        ))}
+                      {question.texts &&
+                        question.texts.map((text, i) => <p key={i}>“{text}”</p>)}
+                    </div>
+                  ))}
+                  {results.comments.length > 0 && (
+                    <div>
+                      <p className="font-medium text-gray-900 dark:text-white">
+                        Comments
+                      </p>
+                      {results.comments.map((c, i) => (
+                        <p key={i} className="mt-1">
+                          {c.rating} ★ · {c.comment}
+                        </p>
+                      ))}
+                    </div>
+                  )}
+                </div>
+              )}
+            </div>
+          )}
+        </>
+      )}
+    </div>
+  );
+}
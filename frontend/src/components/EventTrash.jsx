@@ -0,0 +1,150 @@
+import { useState, useEffect } from 'react';
+import Notification from './Notification';
+import { describeConflicts } from './VenuePicker';
+import config from '../config';
+
+// EventTrash lists the events the user deleted and lets them restore one before
+// it is purged for good
+export default function EventTrash() {
+  const [events, setEvents] = useState([]);
+  const [nextCursor, setNextCursor] = useState('');
+  const [isLoading, setIsLoading] = useState(true);
+  const [notification, setNotification] = useState(null);
+
+  useEffect(() => {
+    fetchTrash();
+  }, []);
+
+  async function fetchTrash(cursor = '') {
+    if (!cursor) setIsLoading(true);
+    try {
+      const params = new URLSearchParams();
+      if (cursor) params.set('cursor', cursor);
+
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/trash?${params}`,
+        {
+          headers: { Authorization: `Bearer ${localStorage.getItem('token')}` },
+        }
+      );
+
+      if (!response.ok) {
+        const message = await response.text();
+        throw new Error(message || 'Failed to fetch the trash');
+      }
+
+      const data = await response.json();
+      setEvents(cursor ? [...events, ...data.items] : data.items);
+      setNextCursor(data.next_cursor || '');
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while fetching the trash',
+      });
+    } finally {
+      setIsLoading(false);
+    }
+  }
+
+  async function handleRestore(event, allowConflicts = false) {
+    try {
+      const response = await fetch(
+        `${config.apiBaseUrl}/api/events/${event.id}/restore`,
+        {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            Authorization: `Bearer ${localStorage.getItem('token')}`,
+          },
+          body: JSON.stringify({ allow_conflicts: allowConflicts }),
+        }
+      );
+
+      if (!response.ok) {
+        const text = await response.text();
+        const data = text.startsWith('{') ? JSON.parse(text) : { message: text };
+        // The venue was booked by another event while this one was in the trash
+        if (data.conflicts) {
+          if (window.confirm(`${describeConflicts(data)}. Restore it anyway?`)) {
+            handleRestore(event, true);
+          }
+          return;
+        }
+        throw new Error(data.message || 'Failed to restore the event');
+      }
+
+      setEvents(events.filter((e) => e.id !== event.id));
+      setNotification({
+        type: 'success',
+        message: `${event.title} was restored`,
+      });
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message: error.message || 'An error occurred while restoring the event',
+      });
+    }
+  }
+
+  return (
+    <div className="w-full mx-auto">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      <h2 className="text-2xl font-bold mb-6 text-gray-900 dark:text-white">
+        Trash
+      </h2>
+
+      {isLoading ? (
+        <div className="flex justify-center items-center h-40">
+          <div className="animate-spin rounded-full h-12 w-12 border-t-2 border-b-2 border-primary-500"></div>
+        </div>
+      ) : events.length === 0 ? (
+        <div className="bg-white dark:bg-gray-800 shadow rounded-lg p-6 text-center">
+          <p className="text-gray-600 dark:text-gray-400">
+            The trash is empty.
+          </p>
+        </div>
+      ) : (
+        <ul className="bg-white dark:bg-gray-800 shadow rounded-lg divide-y divide-gray-200 dark:divide-gray-700">
+          {events.map((event) => (
+            <li
+              key={event.id}
+              className="p-5 flex justify-between items-center"
+            >
+              <div>
+                <h3 className="text-lg font-semibold text-gray-900 dark:text-white">
+                  {event.title}
+                </h3>
+                <p className="text-sm text-gray-500 dark:text-gray-400">
+                  Deleted {new Date(event.deleted_at).toLocaleString()} · Purged{' '}
+                  {new Date(event.purge_at).toLocaleDateString()}
+                </p>
+              </div>
+              <button
+                onClick={() => handleRestore(event)}
+                className="px-4 py-2 border border-transparent rounded-md shadow-sm text-sm font-medium text-white bg-primary-600 hover:bg-primary-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500"
+              >
+                Restore
+              </button>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {!isLoading && nextCursor && (
+        <button
+          onClick={() => fetchTrash(nextCursor)}
+          className="mt-4 text-sm text-primary-600 hover:text-primary-700 dark:text-primary-400"
+        >
+          Show more
+        </button>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,122 @@
+import { useState, useEffect } from 'react';
+import Notification from './Notification';
+import config from '../config';
+
+// RsvpOverrides lets organizers allow specific users to RSVP outside the RSVP window
+export default function RsvpOverrides({ eventId }) {
+  const [overrides, setOverrides] = useState([]);
+  const [email, setEmail] = useState('');
+  const [notification, setNotification] = useState(null);
+
+  useEffect(() => {
+    fetchOverrides();
+  }, [eventId]);
+
+  async function request(path, method, body) {
+    const headers = { Authorization: `Bearer ${localStorage.getItem('token')}` };
+    if (body) headers['Content-Type'] = 'application/json';
+
+    const response = await fetch(
+      `${config.apiBaseUrl}/api/events/${eventId}/rsvp-overrides${path}`,
+      {
+        method,
+        headers,
+        body: body ? JSON.stringify(body) : undefined,
+      }
+    );
+
+    if (!response.ok) {
+      const message = await response.text();
+      throw new Error(message || 'Failed to update RSVP overrides');
+    }
+    return response.json();
+  }
+
+  async function fetchOverrides() {
+    try {
+      setOverrides(await request('', 'GET'));
+    } catch (error) {
+      console.error('Error fetching RSVP overrides:', error);
+    }
+  }
+
+  async function handleChange(path, method, body) {
+    try {
+      await request(path, method, body);
+      setEmail('');
+      fetchOverrides();
+    } catch (error) {
+      setNotification({
+        type: 'error',
+        message:
+          error.message || 'An error occurred while updating RSVP overrides',
+      });
+    }
+  }
+
+  return (
+    <div className="mb-8">
+      {notification && (
+        <Notification
+          type={notification.type}
+          message={notification.message}
+          onClose={() => setNotification(null)}
+        />
+      )}
+
+      <h2 className="text-xl font-semibold text-gray-900 dark:text-white mb-2">
+        RSVP Window Exceptions
+      </h2>
+      <p className="text-sm text-gray-500 dark:text-gray-400 mb-3">
+        These people can RSVP before RSVPs open and after they close.
+      </p>
+
+      {overrides.length > 0 && (
+        <ul className="divide-y divide-gray-200 dark:divide-gray-700 mb-3">
+          {overrides.map((override) => (
+            <li
+              key={override.user_id}
+              className="py-2 flex justify-between text-sm"
+            >
+              <span className="text-gray-900 dark:text-white">
+                {override.first_name} {override.last_name}{' '}
+                <span className="text-gray-500 dark:text-gray-400">
+                  {override.email}
+                </span>
+              </span>
+              <button
+                onClick={() => handleChange(`/${override.user_id}`, 'DELETE')}
+                className="text-red-600 hover:text-red-700 dark:text-red-400"
+              >
+                Remove
+              </button>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      <form
+        onSubmit={(e) => {
+          e.preventDefault();
+          handleChange('', 'POST', { email });
+        }}
+        className="flex gap-3"
+      >
+        <input
+          type="email"
+          required
+          value={email}
+          onChange={(e) => setEmail(e.target.value)}
+          placeholder="Email address"
+          className="flex-1 px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-primary-500 focus:border-primary-500 dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+        />
+        <button
+          type="submit"
+          className="px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700"
+        >
+          Add
+        </button>
+      </form>
+    </div>
+  );
+}
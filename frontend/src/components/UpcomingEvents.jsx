@@ -22,7 +22,7 @@ export default function UpcomingEvents() {
       }
 
       const data = await response.json();
-      setEvents(data || []);
+      setEvents(data.items || []);
     } catch (error) {
       setNotification({
         type: 'error',
@@ -102,6 +102,13 @@ export default function UpcomingEvents() {
               key={event.id}
               className="bg-white dark:bg-gray-800 shadow rounded-lg overflow-hidden"
             >
+              {event.cover_image && (
+                <img
+                  src={event.cover_image.thumbnails.small}
+                  alt=""
+                  className="w-full h-40 object-cover"
+                />
+              )}
               <div className="p-5">
                 <div className="flex justify-between items-start">
                   <div>
@@ -0,0 +1,99 @@
+import { useState, useEffect } from 'react';
+import config from '../config';
+
+// describeConflicts turns the bookings returned with a 409 response into a message
+export function describeConflicts(data) {
+  const bookings = (data.conflicts || []).slice(0, 3).map((booking) => {
+    const start = new Date(booking.start).toLocaleString();
+    const room = booking.room_name ? ` in ${booking.room_name}` : '';
+    return `${booking.title}${room} (${start})`;
+  });
+  const more =
+    data.conflicts && data.conflicts.length > 3
+      ? ` and ${data.conflicts.length - 3} more`
+      : '';
+  return `${data.message}: ${bookings.join(', ')}${more}`;
+}
+
+export default function VenuePicker({ venueId, roomId }) {
+  const [venues, setVenues] = useState([]);
+  const [selectedVenue, setSelectedVenue] = useState(
+    venueId ? String(venueId) : ''
+  );
+
+  useEffect(() => {
+    const fetchVenues = async () => {
+      try {
+        const response = await fetch(`${config.apiBaseUrl}/api/venues`);
+        if (!response.ok) {
+          throw new Error('Failed to fetch venues');
+        }
+        setVenues(await response.json());
+      } catch (error) {
+        console.error('Error fetching venues:', error);
+      }
+    };
+    fetchVenues();
+  }, []);
+
+  const venue = venues.find((v) => String(v.id) === selectedVenue);
+  const inputClass =
+    'mt-1 block w-full px-3 py-2 border border-gray-300 dark:border-gray-600 rounded-md shadow-sm dark:bg-gray-700 dark:text-white focus:outline-none focus:ring-primary-500 focus:border-primary-500 sm:text-sm';
+
+  return (
+    <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
+      <div>
+        <label
+          htmlFor="venue_id"
+          className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+        >
+          Venue
+        </label>
+        <select
+          id="venue_id"
+          name="venue_id"
+          value={selectedVenue}
+          onChange={(e) => setSelectedVenue(e.target.value)}
+          className={inputClass}
+        >
+          <option value="">No venue</option>
+          {venues.map((v) => (
+            <option key={v.id} value={v.id}>
+              {v.name}
+            </option>
+          ))}
+        </select>
+      </div>
+      {venue && (
+        <div>
+          <label
+            htmlFor="room_id"
+            className="block text-sm font-medium text-gray-700 dark:text-gray-300"
+          >
+            Room
+          </label>
+          <select
+            id="room_id"
+            name="room_id"
+            defaultValue={roomId || ''}
+            className={inputClass}
+          >
+            <option value="">Whole venue</option>
+            {venue.rooms.map((room) => (
+              <option key={room.id} value={room.id}>
+                {room.name}
+                {room.capacity ? ` (${room.capacity})` : ''}
+              </option>
+            ))}
+          </select>
+        </div>
+      )}
+      {venue && (
+        <label className="md:col-span-2 flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300">
+          <input type="checkbox" name="allow_conflicts" />
+          Book even if the venue is already booked at this time
+        </label>
+      )}
+    </div>
+  );
+}